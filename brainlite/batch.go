@@ -0,0 +1,192 @@
+package brainlite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/utils"
+)
+
+// RunBatch runs each input as an independent run, and returns the results in input order.
+// At most concurrency runs are in parallel, each on a worker brain with the current topology and the options of this brain,
+// and its own clones of the processors and selectors. A worker brain is reset and reused for its next input.
+// A failed run is captured in its result, unless core.WithFailFast is set, then RunBatch returns the first failure.
+// The error of ctx is returned when ctx is done before all runs finish.
+func (b *BrainLite) RunBatch(ctx context.Context, inputs []map[string]any, concurrency int, withOpts ...core.BatchOption) ([]core.Result, error) {
+	config := core.NewBatchConfig(withOpts...)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(inputs) {
+		concurrency = len(inputs)
+	}
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]core.Result, len(inputs))
+	jobs := make(chan int)
+	var failOnce sync.Once
+	var failErr error
+
+	// workers are built before any run, so all runs have the same topology
+	workers := make([]*BrainLite, concurrency)
+	for i := range workers {
+		workers[i] = b.buildWorker()
+	}
+
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(worker *BrainLite) {
+			defer wg.Done()
+			finished := true
+			for index := range jobs {
+				if err := batchCtx.Err(); err != nil {
+					results[index] = core.NewResult(index, config)
+					results[index].Err = err
+					continue
+				}
+				results[index], finished = worker.runBatchInput(batchCtx, index, inputs[index], config)
+				if results[index].Err != nil && config.FailFast {
+					failOnce.Do(func() {
+						failErr = fmt.Errorf("run input %d: %w", index, results[index].Err)
+						cancel()
+					})
+				}
+			}
+			worker.shutdownAfterRun(finished, core.CancelReasonOfErr(batchCtx.Err()))
+		}(worker)
+	}
+	for index := range inputs {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failErr != nil {
+		return results, failErr
+	}
+
+	return results, ctx.Err()
+}
+
+// buildWorker builds a worker brain of RunBatch with the options and the current topology of this brain,
+// processors, selectors, trigger evaluators and the completion processor are cloned, so parallel runs never share a stateful one.
+func (b *BrainLite) buildWorker() *BrainLite {
+	w := BuildBrain(b.blueprint, b.options...)
+	b.mu.Lock()
+	if b.completion != nil {
+		w.completion = b.completion.Clone()
+	}
+	b.mu.Unlock()
+
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	w.links = make(map[string]*link, len(b.links))
+	for id, l := range b.links {
+		w.links[id] = &link{
+			id:   l.id,
+			spec: l.spec,
+			status: linkStatus{
+				state: core.LinkStateInit,
+			},
+		}
+	}
+	w.subsystems = b.subsystems.deepCopy()
+	w.neurons = make(map[string]*neuron, len(b.neurons))
+	for id, n := range b.neurons {
+		w.neurons[id] = &neuron{
+			id:     n.id,
+			labels: utils.LabelsDeepCopy(n.labels),
+			spec:   n.spec.clone(w.linksOf),
+			status: neuronStatus{
+				state: core.NeuronStateInactive,
+			},
+		}
+	}
+
+	return w
+}
+
+// linksOf returns the links of this brain with the same IDs as the links
+func (b *BrainLite) linksOf(links []*link) []*link {
+	ret := make([]*link, 0, len(links))
+	for _, l := range links {
+		ret = append(ret, b.links[l.id])
+	}
+
+	return ret
+}
+
+// runBatchInput runs the input, returns false if ctx is done before the run finished
+func (b *BrainLite) runBatchInput(ctx context.Context, index int, input map[string]any, config *core.BatchConfig) (core.Result, bool) {
+	result := core.NewResult(index, config)
+	if err := b.Reset(); err != nil {
+		result.Err = err
+		return result, true
+	}
+	keysAndValues := make([]any, 0, 2*len(input))
+	for k, v := range input {
+		keysAndValues = append(keysAndValues, k, v)
+	}
+	if err := b.SetMemory(keysAndValues...); err != nil {
+		result.Err = err
+		return result, true
+	}
+	if err := b.Run(core.WithRunLabels(config.Labels)); err != nil {
+		result.Err = err
+		return result, true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		result.Err = ctx.Err()
+		return result, false
+	}
+
+	result.ReachedEnds = b.GetReachedEnds()
+	result.Err = b.getRunErr()
+	result.Trace = b.GetRunTrace()
+	result.SetFinalMemory(b.snapshotMemory())
+	if len(config.OutputKeys) != 0 {
+		result.Memory = make(map[string]any, len(config.OutputKeys))
+		for _, key := range config.OutputKeys {
+			if b.ExistMemory(key) {
+				result.Memory[key] = b.GetMemory(key)
+			}
+		}
+	}
+
+	return result, true
+}
+
+// shutdownAfterRun shuts down the worker brain, after the unfinished run, cancelled by the reason, sleeps
+func (b *BrainLite) shutdownAfterRun(finished bool, reason core.CancelReason) {
+	if b.getState() == core.BrainStateShutdown {
+		return
+	}
+	if finished {
+		b.Shutdown()
+		return
+	}
+	// cancel the calls still in flight, so the run sleeps soon
+	b.mu.Lock()
+	b.cancelRunContext(reason)
+	b.mu.Unlock()
+	go func() {
+		b.Wait()
+		b.Shutdown()
+	}()
+}
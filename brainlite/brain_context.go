@@ -1,36 +1,184 @@
 package brainlite
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// cancelledContext is the context of the processes of a run which is over
+var cancelledContext = func() context.Context {
+	ctx, cancel := core.WithCancelReason(context.Background())
+	cancel(core.CancelReasonRunOver)
+	return ctx
+}()
+
 type brainContext struct {
 	b               *BrainLite
 	currentNeuronID string
+	// key of the trigger group which activated the current neuron
+	triggeredBy string
+	// sequence of the run the context belongs to, the memory can not be changed once the run is cancelled
+	run uint64
+	// payloads of the signals which activated the current neuron, key: in-link ID
+	payloads map[string]interface{}
+	// in-links without signal when the current neuron fired, see GetAbsentInputs
+	absent []string
+	// values read for the memory keys which are not set, see core.WithInputDefaults
+	inputDefaults map[string]interface{}
+	// scratch store of the current process, discarded with the context
+	local processor.LocalStore
+	// context of the current process with its share of the deadline budget, nil for the context of the run
+	ctx context.Context
 }
 
 func (c *brainContext) SetMemory(keysAndValues ...interface{}) error {
-	return c.b.SetMemory(keysAndValues...)
+	if c.b.isRunCancelled(c.run) {
+		return errors.ErrRunCancelled(c.currentNeuronID)
+	}
+	return c.b.setMemory(c.currentNeuronID, keysAndValues...)
+}
+
+func (c *brainContext) GetInternalMemory(key string) (interface{}, bool) {
+	return c.b.peekMemory(key)
+}
+
+func (c *brainContext) SetInternalMemory(key string, value interface{}) error {
+	if c.b.isRunCancelled(c.run) {
+		return errors.ErrRunCancelled(c.currentNeuronID)
+	}
+	return c.b.setInternalMemory(key, value)
 }
 
 func (c *brainContext) GetMemory(key interface{}) interface{} {
-	return c.b.GetMemory(key)
+	if v, ok := c.inputDefault(key); ok {
+		return v
+	}
+	return c.b.getMemory(c.currentNeuronID, key)
 }
 
 func (c *brainContext) ExistMemory(key interface{}) bool {
-	return c.b.ExistMemory(key)
+	if _, ok := c.inputDefault(key); ok {
+		return true
+	}
+	return c.b.existMemory(c.currentNeuronID, key)
+}
+
+// inputDefault returns the default of the memory key, if it has one and the memory is not set
+func (c *brainContext) inputDefault(key interface{}) (interface{}, bool) {
+	k, ok := key.(string)
+	if !ok {
+		return nil, false
+	}
+	v, ok := c.inputDefaults[k]
+	if !ok || c.b.hasMemory(k) {
+		return nil, false
+	}
+
+	return v, true
 }
 
 func (c *brainContext) DeleteMemory(key interface{}) {
-	c.b.DeleteMemory(key)
+	if c.b.isRunCancelled(c.run) {
+		return
+	}
+	c.b.deleteMemory(c.currentNeuronID, key)
 }
 
 func (c *brainContext) ClearMemory() {
-	c.b.ClearMemory()
+	if c.b.isRunCancelled(c.run) {
+		return
+	}
+	c.b.clearMemory(c.currentNeuronID)
+}
+
+func (c *brainContext) SetStream(key interface{}, r io.ReadCloser) error {
+	return c.SetMemory(key, processor.NewStream(r))
+}
+
+func (c *brainContext) GetStream(key interface{}) (io.ReadCloser, error) {
+	stream, ok := c.GetMemory(key).(*processor.Stream)
+	if !ok {
+		return nil, errors.Wrapf(processor.ErrNotStream, "memory key %v", key)
+	}
+	r, err := stream.Take()
+	if err != nil {
+		return nil, errors.Wrapf(err, "memory key %v", key)
+	}
+
+	return r, nil
 }
 
 func (c *brainContext) GetCurrentNeuronID() string {
 	return c.currentNeuronID
 }
 
+func (c *brainContext) GetTriggeredBy() string {
+	return c.triggeredBy
+}
+
+func (c *brainContext) SetCastPayload(group string, payload interface{}) error {
+	if c.b.isRunCancelled(c.run) {
+		return errors.ErrRunCancelled(c.currentNeuronID)
+	}
+	return c.b.setCastPayload(c.currentNeuronID, group, payload)
+}
+
+func (c *brainContext) GetPayloads() map[string]interface{} {
+	payloads := make(map[string]interface{}, len(c.payloads))
+	for linkID, payload := range c.payloads {
+		payloads[linkID] = payload
+	}
+
+	return payloads
+}
+
+func (c *brainContext) GetAbsentInputs() []string {
+	return append([]string{}, c.absent...)
+}
+
+func (c *brainContext) Local() *processor.LocalStore {
+	return &c.local
+}
+
+func (c *brainContext) Yield() {
+	c.b.yield(c.currentNeuronID)
+}
+
+func (c *brainContext) Rand() *rand.Rand {
+	return c.b.getRunRand()
+}
+
+// IdempotencyKey is `<base>/<neuron ID>/<n>`, the n-th activation of the neuron in the run,
+// so the reruns with the same base get the same keys
+func (c *brainContext) IdempotencyKey() string {
+	base, ok := c.GetMemory(processor.IdempotencyMemoryKey).(string)
+	if !ok || base == "" {
+		base = c.b.runID(c.run)
+	}
+
+	return fmt.Sprintf("%s/%s/%d", base, c.currentNeuronID, c.b.visitCount(c.currentNeuronID, c.run))
+}
+
+func (c *brainContext) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return c.b.getRunContext(c.run)
+}
+
 func (c *brainContext) GetCurrentNeuronLabels() map[string]string {
-	return c.b.neurons[c.currentNeuronID].labels
+	neu, ok := c.b.getNeuron(c.currentNeuronID)
+	if !ok {
+		return nil
+	}
+
+	return neu.labels
 }
 
 func (c *brainContext) GetBrainID() string {
@@ -42,7 +190,10 @@ func (c *brainContext) GetBrainLabels() map[string]string {
 }
 
 func (c *brainContext) ContinueCast() {
-	_, ok := c.b.neurons[c.currentNeuronID]
+	if c.b.isRunCancelled(c.run) {
+		return
+	}
+	_, ok := c.b.getNeuron(c.currentNeuronID)
 	if !ok {
 		return
 	}
@@ -53,3 +204,17 @@ func (c *brainContext) ContinueCast() {
 		id:     c.currentNeuronID,
 	})
 }
+
+func (c *brainContext) RegisterCompensation(fn func(ctx context.Context) error) {
+	if fn == nil || c.b.isRunCancelled(c.run) {
+		return
+	}
+	c.b.registerCompensation(c.run, c.currentNeuronID, fn)
+}
+
+func (c *brainContext) Defer(fn func()) {
+	if fn == nil {
+		return
+	}
+	c.b.registerDeferred(c.run, c.currentNeuronID, fn)
+}
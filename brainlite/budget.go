@@ -0,0 +1,39 @@
+package brainlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/internal/topology"
+)
+
+// budgetContext returns the context of the process of the neuron with its share of the deadline budget of the run,
+// see WithDeadlineBudget, nil without budget. Returns error if the budget is spent.
+func (b *BrainLite) budgetContext(neuronID string, run uint64) (context.Context, context.CancelFunc, error) {
+	if b.budget <= 0 {
+		return nil, nil, nil
+	}
+	b.mu.Lock()
+	remaining := b.budget - time.Since(b.runStartedAt)
+	b.mu.Unlock()
+	if remaining <= 0 {
+		return nil, nil, errors.ErrDeadlineBudgetSpent(b.budget, neuronID)
+	}
+
+	timeout := remaining
+	if b.budgetStrategy != nil {
+		b.topoMu.RLock()
+		links := make(map[string]topology.Link, len(b.links))
+		for id, l := range b.links {
+			links[id] = topology.Link{From: l.spec.from, To: l.spec.to}
+		}
+		b.topoMu.RUnlock()
+		if share := b.budgetStrategy(remaining, topology.PathLen(links, neuronID)); share > 0 && share < remaining {
+			timeout = share
+		}
+	}
+	ctx, cancel := context.WithTimeout(b.getRunContext(run), timeout)
+
+	return ctx, cancel, nil
+}
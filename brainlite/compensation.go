@@ -0,0 +1,70 @@
+package brainlite
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// compensation undoes the side effect of a neuron process, see processor.BrainContext.RegisterCompensation
+type compensation struct {
+	neuronID string
+	fn       func(ctx context.Context) error
+}
+
+// registerCompensation appends the compensation to the compensations of the run, unless the run is over
+func (b *BrainLite) registerCompensation(run uint64, neuronID string, fn func(ctx context.Context) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.run != run || b.state != core.BrainStateRunning {
+		return
+	}
+	b.compensations = append(b.compensations, compensation{neuronID: neuronID, fn: fn})
+}
+
+// runCompensations calls the compensations of the current run once, in reverse order of registration,
+// if the run fails and the policy compensates on failure. The compensations are dropped either way.
+// Every compensation is called even if some fail, the failures wrap the run error into a *core.CompensationError.
+func (b *BrainLite) runCompensations() {
+	b.mu.Lock()
+	compensations, runErr := b.compensations, b.runErr
+	b.compensations = nil
+	should := len(compensations) != 0 && runErr != nil && b.compensationPolicy == core.CompensateOnFailure &&
+		b.state == core.BrainStateRunning
+	b.mu.Unlock()
+	if !should {
+		return
+	}
+
+	failures := make([]error, 0)
+	for i := len(compensations) - 1; i >= 0; i-- {
+		c := compensations[i]
+		b.log().Debug().Str("neuronID", c.neuronID).Msg("run compensation")
+		// the context of the run is done once it fails, so the compensations get their own
+		if err := callCompensation(c.fn); err != nil {
+			err = fmt.Errorf("compensate neuron %s error: %w", c.neuronID, err)
+			b.log().Error().Err(err).Msg("run compensation error")
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	b.runErr = &core.CompensationError{RunErr: runErr, Failures: failures}
+	b.mu.Unlock()
+}
+
+// callCompensation recovers a panic of the compensation as a *core.PanicError
+func callCompensation(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &core.PanicError{Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+
+	return fn(context.Background())
+}
@@ -0,0 +1,52 @@
+package brainlite
+
+import (
+	"runtime/debug"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// deferred releases a resource of a neuron process at the end of the run, see processor.BrainContext.Defer
+type deferred struct {
+	neuronID string
+	fn       func()
+}
+
+// registerDeferred appends the func to the deferred funcs of the run.
+// The func is called at once if the run is over, so the resource is released anyway.
+func (b *BrainLite) registerDeferred(run uint64, neuronID string, fn func()) {
+	b.mu.Lock()
+	if b.run != run || b.state != core.BrainStateRunning {
+		b.mu.Unlock()
+		b.callDeferred(deferred{neuronID: neuronID, fn: fn})
+		return
+	}
+	b.deferred = append(b.deferred, deferred{neuronID: neuronID, fn: fn})
+	b.mu.Unlock()
+}
+
+// runDeferred calls the deferred funcs of the current run once, in reverse order of registration,
+// whether the run succeeds, fails or is cancelled. Every func is called even if some panic.
+func (b *BrainLite) runDeferred() {
+	b.mu.Lock()
+	funcs := b.deferred
+	b.deferred = nil
+	b.mu.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		b.callDeferred(funcs[i])
+	}
+}
+
+// callDeferred recovers and logs a panic of the deferred func
+func (b *BrainLite) callDeferred(d deferred) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := &core.PanicError{Value: r, Stack: string(debug.Stack())}
+			b.log().Error().Err(err).Str("neuronID", d.neuronID).Msg("run deferred func error")
+		}
+	}()
+
+	b.log().Debug().Str("neuronID", d.neuronID).Msg("run deferred func")
+	d.fn()
+}
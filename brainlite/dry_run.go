@@ -0,0 +1,76 @@
+package brainlite
+
+import (
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+const (
+	// dryRunAllGroup the cast group of all out-links of a neuron in a dry run of core.Scenario.AllBranches
+	dryRunAllGroup = "__DRY_RUN_ALL__"
+	// dryRunMaxSteps bounds the loops of a dry run if the brain has no step limit, see WithMaxSteps
+	dryRunMaxSteps = 1000
+)
+
+// DryRun runs the current topology on a worker brain with the decisions of the scenario, without invoking
+// the processors, selectors, completion processor, hooks and metrics of this brain. The required memories are ignored,
+// and an unsatisfiable trigger group ends the dry run with ErrDeadlock.
+func (b *BrainLite) DryRun(scenario core.Scenario) core.DryRunResult {
+	w := b.buildWorker()
+	defer w.Shutdown()
+	w.completion = nil
+	w.hooks = core.Hooks{}
+	w.metrics = nil
+	w.deadlockDetection = true
+	w.resultRetention = core.ResultRetentionFull
+	if w.maxSteps <= 0 {
+		w.maxSteps = dryRunMaxSteps
+	}
+	for _, n := range w.neurons {
+		n.spec.processor = &processor.EmptyProcessor{}
+		n.spec.requiredMemory = nil
+		group, ok := scenario.Selections[n.id]
+		if !ok {
+			group = processor.DefaultCastGroupName
+			if scenario.AllBranches {
+				group = dryRunAllGroup
+				all := make(map[string]*link)
+				for _, links := range n.spec.castGroups {
+					for _, l := range links {
+						all[l.id] = l
+					}
+				}
+				links := make([]*link, 0, len(all))
+				for _, l := range all {
+					links = append(links, l)
+				}
+				sort.Slice(links, func(i, j int) bool {
+					return links[i].id < links[j].id
+				})
+				n.spec.castGroups[dryRunAllGroup] = links
+			}
+		}
+		n.spec.selector = processor.NewFuncSelector(func(processor.BrainContextReader) string {
+			return group
+		})
+	}
+
+	result := core.DryRunResult{Executed: make([]string, 0)}
+	if err := w.Entry(); err != nil {
+		result.Err = err
+		return result
+	}
+	w.Wait()
+	for _, e := range w.GetRunTrace() {
+		if !utils.SlicesContains(result.Executed, []string{e.NeuronID}) {
+			result.Executed = append(result.Executed, e.NeuronID)
+		}
+	}
+	result.ReachedEnds = w.GetReachedEnds()
+	result.Err = w.getRunErr()
+
+	return result
+}
@@ -0,0 +1,160 @@
+package brainlite
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// memoryWrite is a write of a memory key by a neuron process, numbered in order of writes of the brain
+type memoryWrite struct {
+	seq   uint64
+	value interface{}
+}
+
+// branchWrites is the writes of the memory keys with merge resolvers along a branch, in order of write, key: memory key.
+// The writes are inherited by the branches forked from it.
+type branchWrites map[string][]memoryWrite
+
+func (w branchWrites) clone() branchWrites {
+	if w == nil {
+		return nil
+	}
+	newMap := make(branchWrites, len(w))
+	for key, writes := range w {
+		newMap[key] = append([]memoryWrite(nil), writes...)
+	}
+
+	return newMap
+}
+
+// mergeBranchWrites unions the writes of the branches joining at a neuron, the writes shared by them are kept once
+func mergeBranchWrites(branches []branchWrites) branchWrites {
+	var merged branchWrites
+	for _, w := range branches {
+		for key, writes := range w {
+			if merged == nil {
+				merged = make(branchWrites)
+			}
+			merged[key] = append(merged[key], writes...)
+		}
+	}
+	for key, writes := range merged {
+		sort.Slice(writes, func(i, j int) bool { return writes[i].seq < writes[j].seq })
+		unique := writes[:0]
+		for i, mw := range writes {
+			if i == 0 || mw.seq != writes[i-1].seq {
+				unique = append(unique, mw)
+			}
+		}
+		merged[key] = unique
+	}
+
+	return merged
+}
+
+// branchValues returns the values of the key written last by each branch joining at the neuron, in order of write.
+// The writes shared by several branches are made before they fork, so they are not written by any of them.
+func branchValues(key string, branches []branchWrites) []interface{} {
+	shared := make(map[uint64]int)
+	for _, w := range branches {
+		for _, mw := range w[key] {
+			shared[mw.seq]++
+		}
+	}
+	last := make([]memoryWrite, 0, len(branches))
+	for _, w := range branches {
+		writes := w[key]
+		for i := len(writes) - 1; i >= 0; i-- {
+			if shared[writes[i].seq] == 1 {
+				last = append(last, writes[i])
+				break
+			}
+		}
+	}
+	sort.Slice(last, func(i, j int) bool { return last[i].seq < last[j].seq })
+	values := make([]interface{}, 0, len(last))
+	for _, mw := range last {
+		values = append(values, mw.value)
+	}
+
+	return values
+}
+
+// memoryMerges returns the values to merge by the resolvers of the neuron, key: memory key.
+// The keys written by fewer than two branches are skipped, unless the neuron merges a single writer.
+func (n *neuron) memoryMerges(branches []branchWrites) map[string][]interface{} {
+	merges := make(map[string][]interface{})
+	for key := range n.spec.mergeResolvers {
+		values := branchValues(key, branches)
+		if len(values) == 0 || len(values) == 1 && !n.spec.mergeSingleWriter {
+			continue
+		}
+		merges[key] = values
+	}
+
+	return merges
+}
+
+// isMergeKey indicates whether any neuron has a merge resolver of the memory key, the writes of which are tracked
+func (b *BrainLite) isMergeKey(key any) bool {
+	k, ok := key.(string)
+	if !ok {
+		return false
+	}
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	for _, neu := range b.neurons {
+		if _, ok := neu.spec.mergeResolvers[k]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordBranchWrite records the write of the memory key by the neuron process into its branch,
+// if the key has a merge resolver. Writes outside of neuron processes are not tracked.
+func (b *BrainLite) recordBranchWrite(neuronID string, key any, value interface{}) {
+	if neuronID == "" || !b.isMergeKey(key) {
+		return
+	}
+	neu, ok := b.getNeuron(neuronID)
+	if !ok {
+		return
+	}
+
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	b.writeSeq++
+	if neu.status.writes == nil {
+		neu.status.writes = make(branchWrites)
+	}
+	k := key.(string)
+	neu.status.writes[k] = append(neu.status.writes[k], memoryWrite{seq: b.writeSeq, value: value})
+}
+
+// mergeMemory sets the values merged by the resolvers of the neuron into the memory,
+// a panic of a resolver is recovered as a *core.PanicError
+func (b *BrainLite) mergeMemory(neu *neuron, merges map[string][]interface{}) (err error) {
+	if len(merges) == 0 {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &core.PanicError{Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+
+	keysAndValues := make([]interface{}, 0, 2*len(merges))
+	for key, values := range merges {
+		keysAndValues = append(keysAndValues, key, neu.spec.mergeResolvers[key](values))
+	}
+	if err := b.setMemory(neu.id, keysAndValues...); err != nil {
+		return fmt.Errorf("merge memory error: %w", err)
+	}
+
+	return nil
+}
@@ -1,11 +1,18 @@
 package brainlite
 
 import (
+	"sort"
+	"time"
+
 	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/topology"
 	"github.com/Rovanta/rmodel/internal/utils"
 	"github.com/Rovanta/rmodel/processor"
 )
 
+// outcomeFailureGroup is selected when a failure is routed by the out-links gated by processor.OutcomeFailure
+const outcomeFailureGroup = "__OUTCOME_FAILURE__"
+
 type neuron struct {
 	id     string
 	labels map[string]string
@@ -18,11 +25,55 @@ type neuronSpec struct {
 	triggerGroups map[string][]*link
 	castGroups map[string][]*link
 	selector processor.Selector
+	// the selector is bound explicitly, see core.Neuron.IsSelectorBound
+	selectorBound bool
+	// key: alias, value: cast group name
+	groupAliases map[string]string
+	// decides whether the neuron is activated instead of the trigger groups, nil to use the trigger groups
+	triggerEvaluator processor.TriggerEvaluator
+	// memory keys required to activate the neuron besides the trigger, see core.WithRequiredMemory
+	requiredMemory []any
+	// values the processor reads for the memory keys which are not set, see core.WithInputDefaults
+	inputDefaults map[string]interface{}
+	// times the neuron can be activated again in a run, 0 for unbounded, see core.WithMaxRevisits
+	maxRevisits int
+	// the neuron runs at most once per run, see core.WithRunOnce
+	runOnce bool
+	// consulted before the neuron fires again in a run, see core.WithRefireGuard
+	refireGuard func(bcr processor.BrainContextReader) bool
+	// priorities of the trigger groups satisfied at once, key: trigger group key, see core.WithTriggerGroupPriority
+	triggerGroupPriorities map[string]int
+	// window from the first signal arrived to fire the neuron with partial inputs as timeoutGroup, see core.WithTriggerTimeout
+	triggerTimeout time.Duration
+	timeoutGroup   string
+	// resolvers of the memory keys written by the branches joining at the neuron, see core.WithMemoryMergeResolver
+	mergeResolvers    map[string]core.MemoryMergeResolver
+	mergeSingleWriter bool
+	// tags added to the labels of the metrics of the neuron, see core.Neuron.SetMetricTags
+	metricTags map[string]string
 }
 
 type neuronStatus struct {
 	state core.NeuronState
-	count struct {
+	// key of the trigger group which activated the neuron last time
+	triggeredBy string
+	// cast group selected by the ErrorAwareSelector on the failure of the last process, empty if not routed
+	errorGroup string
+	// payloads of the cast groups set by the last process, key: cast group name
+	payloads map[string]interface{}
+	// cast group selected by the last cast, empty if not cast since reset
+	castGroup string
+	// run of the visits, and the times the neuron is activated in the run
+	visitRun uint64
+	visits   int
+	// start of the pending trigger timeout, zero if none, and its timer
+	timeoutStart time.Time
+	timeoutTimer *time.Timer
+	// the neuron is fired by the trigger timeout, with the signals arrived
+	timedOut bool
+	// writes of the memory keys with merge resolvers along the branch of the last process
+	writes branchWrites
+	count      struct {
 		process int
 		succeed int
 		failed  int
@@ -30,20 +81,7 @@ type neuronStatus struct {
 }
 
 func newNeuron(n core.Neuron, linkMap map[string]*link) *neuron {
-	neu := &neuron{
-		id:     n.GetID(),
-		labels: utils.LabelsDeepCopy(n.GetLabels()),
-		spec: neuronSpec{
-			processor:     n.GetProcessor(),
-			selector:      n.GetSelector(),
-			triggerGroups: make(map[string][]*link),
-			castGroups:    make(map[string][]*link),
-		},
-		status: neuronStatus{
-			state: core.NeuronStateInactive,
-		},
-	}
-
+	neu := newNeuronWithoutLinks(n)
 	for gName, links := range n.ListTriggerGroups() {
 		neu.spec.triggerGroups[gName] = make([]*link, len(links))
 		for i, linkID := range links {
@@ -60,3 +98,267 @@ func newNeuron(n core.Neuron, linkMap map[string]*link) *neuron {
 
 	return neu
 }
+
+// newNeuronWithoutLinks builds the neuron with its spec but no link in its trigger groups and cast groups
+func newNeuronWithoutLinks(n core.Neuron) *neuron {
+	return &neuron{
+		id:     n.GetID(),
+		labels: utils.LabelsDeepCopy(n.GetLabels()),
+		spec:   newNeuronSpec(n),
+		status: neuronStatus{
+			state: core.NeuronStateInactive,
+		},
+	}
+}
+
+// newNeuronSpec builds the spec of the neuron, its trigger groups and cast groups are empty
+func newNeuronSpec(n core.Neuron) neuronSpec {
+	spec := neuronSpec{
+		processor:              n.GetProcessor(),
+		selector:               n.GetSelector(),
+		selectorBound:          n.IsSelectorBound(),
+		groupAliases:           n.ListCastGroupAliases(),
+		triggerGroups:          make(map[string][]*link),
+		castGroups:             make(map[string][]*link),
+		triggerEvaluator:       n.GetTriggerEvaluator(),
+		requiredMemory:         n.GetRequiredMemory(),
+		inputDefaults:          n.GetInputDefaults(),
+		maxRevisits:            n.GetMaxRevisits(),
+		runOnce:                n.GetRunOnce(),
+		refireGuard:            n.GetRefireGuard(),
+		triggerGroupPriorities: n.GetTriggerGroupPriorities(),
+		mergeResolvers:         n.GetMemoryMergeResolvers(),
+		mergeSingleWriter:      n.GetMergeSingleWriter(),
+		metricTags:             n.GetMetricTags(),
+	}
+	spec.triggerTimeout, spec.timeoutGroup = n.GetTriggerTimeout()
+
+	return spec
+}
+
+// clone returns a copy of the spec for a worker of RunBatch: the processor, the selector and the trigger evaluator
+// are cloned, and the links of the groups are mapped by linksOf. The other fields are copied as they are.
+func (s neuronSpec) clone(linksOf func(links []*link) []*link) neuronSpec {
+	c := s
+	if s.processor != nil {
+		c.processor = s.processor.Clone()
+	}
+	if s.selector != nil {
+		c.selector = s.selector.Clone()
+	}
+	if s.triggerEvaluator != nil {
+		c.triggerEvaluator = s.triggerEvaluator.Clone()
+	}
+	c.groupAliases = make(map[string]string, len(s.groupAliases))
+	for alias, group := range s.groupAliases {
+		c.groupAliases[alias] = group
+	}
+	c.triggerGroups = make(map[string][]*link, len(s.triggerGroups))
+	for key, links := range s.triggerGroups {
+		c.triggerGroups[key] = linksOf(links)
+	}
+	c.castGroups = make(map[string][]*link, len(s.castGroups))
+	for name, links := range s.castGroups {
+		c.castGroups[name] = linksOf(links)
+	}
+
+	return c
+}
+
+// triggerGroupKeys returns the keys of the trigger groups by priority, the highest first, then by key
+func (n *neuron) triggerGroupKeys() []string {
+	keys := make([]string, 0, len(n.spec.triggerGroups))
+	for key := range n.spec.triggerGroups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := n.spec.triggerGroupPriorities[keys[i]], n.spec.triggerGroupPriorities[keys[j]]
+		if pi != pj {
+			return pi > pj
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// inLinks returns the in-links of all trigger groups except the optional ones, sorted by ID
+func (n *neuron) inLinks() []*link {
+	found := make(map[string]*link)
+	for _, links := range n.spec.triggerGroups {
+		for _, l := range links {
+			if !l.spec.optional {
+				found[l.id] = l
+			}
+		}
+	}
+	ret := make([]*link, 0, len(found))
+	for _, l := range found {
+		ret = append(ret, l)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].id < ret[j].id
+	})
+
+	return ret
+}
+
+// arrivedSignals returns the signals of the ready in-links, should be called with statusMu locked
+func (n *neuron) arrivedSignals() map[string]processor.SignalInfo {
+	arrived := make(map[string]processor.SignalInfo)
+	for _, l := range n.inLinks() {
+		if l.status.state == core.LinkStateReady {
+			arrived[l.id] = processor.SignalInfo{
+				LinkID:    l.id,
+				Seq:       l.status.signal.delivered,
+				ArrivedAt: l.status.signal.arrivedAt,
+				Payload:   l.status.signal.payload,
+			}
+		}
+	}
+
+	return arrived
+}
+
+// triggeredLinks returns the in-links which activated the neuron by the trigger group key, should be called with statusMu locked.
+// The ready in-links are returned for the trigger evaluator and the trigger timeout.
+func (n *neuron) triggeredLinks(triggeredBy string) []*link {
+	if triggeredBy != processor.TriggerEvaluatorGroupKey && !n.status.timedOut {
+		return requiredLinks(n.spec.triggerGroups[triggeredBy])
+	}
+	links := make([]*link, 0)
+	for _, l := range n.inLinks() {
+		if l.status.state == core.LinkStateReady {
+			links = append(links, l)
+		}
+	}
+
+	return links
+}
+
+// absentLinks returns the IDs of the in-links without signal, sorted, when the neuron fires by the trigger evaluator
+// or the trigger timeout, should be called with statusMu locked before the in-links are reset. Optional links are left out.
+// Nil for a trigger group, which fires once all of its links have signals.
+func (n *neuron) absentLinks(triggeredBy string) []string {
+	if triggeredBy != processor.TriggerEvaluatorGroupKey && !n.status.timedOut {
+		return nil
+	}
+	absent := make([]string, 0)
+	for _, l := range n.inLinks() {
+		if l.status.state != core.LinkStateReady {
+			absent = append(absent, l.id)
+		}
+	}
+
+	return absent
+}
+
+// optionalLinks returns the optional in-links of all trigger groups, sorted by ID
+func (n *neuron) optionalLinks() []*link {
+	found := make(map[string]*link)
+	for _, links := range n.spec.triggerGroups {
+		for _, l := range links {
+			if l.spec.optional {
+				found[l.id] = l
+			}
+		}
+	}
+	ret := make([]*link, 0, len(found))
+	for _, l := range found {
+		ret = append(ret, l)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].id < ret[j].id
+	})
+
+	return ret
+}
+
+// requiredLinks returns the links of a trigger group which are not optional
+func requiredLinks(links []*link) []*link {
+	ret := make([]*link, 0, len(links))
+	for _, l := range links {
+		if !l.spec.optional {
+			ret = append(ret, l)
+		}
+	}
+
+	return ret
+}
+
+// noOpGroup returns the group cast without calling the selector, should be called with statusMu locked:
+// the group of a processor.NoOpSelector, or the only non-empty cast group of a neuron with the processor.DefaultSelector
+// not bound explicitly.
+func (n *neuron) noOpGroup() (string, bool) {
+	if s, ok := n.spec.selector.(*processor.NoOpSelector); ok {
+		return s.Group(), true
+	}
+
+	return topology.SoleCastGroup(n.spec.selector, n.spec.selectorBound, n.castGroupSizes())
+}
+
+// castGroupSizes returns the number of links of each cast group
+func (n *neuron) castGroupSizes() map[string]int {
+	sizes := make(map[string]int, len(n.spec.castGroups))
+	for name, links := range n.spec.castGroups {
+		sizes[name] = len(links)
+	}
+
+	return sizes
+}
+
+// hasCastGroup reports whether the group selected by a selector is known to the neuron, should be called with
+// statusMu locked: a cast group or an alias of the neuron, the default cast group, processor.SelectEnd, or the group
+// of the failure outcome links.
+func (n *neuron) hasCastGroup(group string) bool {
+	if _, ok := n.spec.castGroups[group]; ok {
+		return true
+	}
+	if _, ok := n.spec.groupAliases[group]; ok {
+		return true
+	}
+
+	return group == processor.DefaultCastGroupName || group == processor.SelectEnd || group == outcomeFailureGroup
+}
+
+// castLinks returns the links cast by the group, should be called with statusMu locked. The links gated by an outcome
+// other than the outcome of the last process are left out. outcomeFailureGroup casts the links gated by
+// processor.OutcomeFailure of all groups.
+func (n *neuron) castLinks(group string) []*link {
+	outcome := processor.OutcomeSuccess
+	if n.status.errorGroup != "" {
+		outcome = processor.OutcomeFailure
+	}
+	if group == outcomeFailureGroup {
+		return n.outcomeLinks(processor.OutcomeFailure)
+	}
+	ret := make([]*link, 0, len(n.spec.castGroups[group]))
+	for _, l := range n.spec.castGroups[group] {
+		if l.spec.outcome == "" || l.spec.outcome == outcome {
+			ret = append(ret, l)
+		}
+	}
+
+	return ret
+}
+
+// outcomeLinks returns the out-links gated by the outcome, sorted by ID
+func (n *neuron) outcomeLinks(outcome processor.Outcome) []*link {
+	found := make(map[string]*link)
+	for _, links := range n.spec.castGroups {
+		for _, l := range links {
+			if l.spec.outcome == outcome {
+				found[l.id] = l
+			}
+		}
+	}
+	ret := make([]*link, 0, len(found))
+	for _, l := range found {
+		ret = append(ret, l)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].id < ret[j].id
+	})
+
+	return ret
+}
@@ -1,43 +1,192 @@
 package brainlite
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"runtime/debug"
+	"time"
 
 	"github.com/Rovanta/rmodel/core"
 	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
 )
 
-func (b *BrainLite) publishEventActivateNeuron(neuronID string) {
-	if b.getState() == core.BrainStateShutdown || b.nQueue == nil {
+// activation of a neuron queued in a run
+type activation struct {
+	neuronID string
+	run      uint64
+}
+
+// publishEventActivateNeuron queues the activation from the maintainer goroutine, it is sent by the maintainer loop
+func (b *BrainLite) publishEventActivateNeuron(neuronID string, run uint64) {
+	if b.getState() == core.BrainStateShutdown || b.backlog == nil {
 		return
 	}
-	b.logger.Debug().Interface("neuronID", neuronID).Msg("publish activate neuron event")
+	b.log().Debug().Interface("neuronID", neuronID).Msg("publish activate neuron event")
 
-	b.nQueue <- neuronID
+	b.backlog.activations = append(b.backlog.activations, activation{neuronID: neuronID, run: run})
 }
 
 func (b *BrainLite) runNeuronWorker() {
-	for neuronID := range b.nQueue {
-		neu, ok := b.neurons[neuronID]
-		if !ok {
-			b.logger.Error().Str("neuronID", neuronID).Msg("neuron not found")
-			continue
+	queue, reclaim, stop := b.nQueue, b.reclaim, b.stop
+	for {
+		var act activation
+		select {
+		case act = <-queue:
+		case reclaim <- struct{}{}:
+			// the slot of the worker is handed back to a yielding process, which resumes in place of the worker
+			return
+		case <-stop:
+			return
 		}
+		b.runActivation(act)
+	}
+}
 
-		err := b.activateNeuron(neu)
-		if err != nil {
-			b.logger.Error().Err(err).Str("neuronID", neuronID).Msg("activate neuron error")
+// runYieldedWorker runs the activation the process yields to, then goes on as a worker in place of the yielding process
+func (b *BrainLite) runYieldedWorker(act activation) {
+	b.runActivation(act)
+	b.runNeuronWorker()
+}
+
+// runActivation activates the neuron of the queued activation on the current goroutine
+func (b *BrainLite) runActivation(act activation) {
+	neuronID := act.neuronID
+	neu, ok := b.getNeuron(neuronID)
+	if !ok {
+		b.log().Error().Str("neuronID", neuronID).Msg("neuron not found")
+		return
+	}
+
+	err := b.activateNeuron(neu, act.run)
+	if err != nil {
+		b.log().Error().Err(err).
+			Str("neuronID", neuronID).
+			Str("processorKind", processor.KindOf(neu.spec.processor)).
+			Msg("activate neuron error")
+	}
+}
+
+// yield hands the worker slot of the process to an activation waiting in the queue, which runs on a new worker,
+// then waits until a worker hands a slot back, once it is idle or done with its activation, so the number of
+// running processes stays within the workers. The scheduler of Go runs the other goroutines if none is waiting.
+func (b *BrainLite) yield(neuronID string) {
+	queue, reclaim, stop := b.nQueue, b.reclaim, b.stop
+	select {
+	case act := <-queue:
+		b.log().Debug().
+			Str("neuronID", neuronID).
+			Str("yieldTo", act.neuronID).
+			Msg("neuron process yields")
+		go b.runYieldedWorker(act)
+		select {
+		case <-reclaim:
+		case <-stop:
 		}
+	case <-stop:
+	default:
+		runtime.Gosched()
+	}
+}
+
+// process runs the processor, a panic of the process is recovered as a *core.PanicError
+func process(p processor.Processor, bc processor.BrainContext) (err error) {
+	if p == nil {
+		return errors.ErrNilProcessor(bc.GetCurrentNeuronID())
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &core.PanicError{Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+
+	return p.Process(bc)
 }
 
-func (b *BrainLite) activateNeuron(neu *neuron) error {
+// interceptMemory calls the memory interceptor with the context of the process, a panic is recovered like by process
+func (b *BrainLite) interceptMemory(bc processor.BrainContext) (err error) {
+	if b.memoryInterceptor == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &core.PanicError{Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+	b.memoryInterceptor(bc.GetCurrentNeuronID(), bc)
+
+	return nil
+}
+
+func (b *BrainLite) activateNeuron(neu *neuron, run uint64) error {
 	if neu == nil {
 		return errors.ErrNeuronNotFound("nil")
 	}
+	// the neuron is queued before the run is aborted, its status is reset by the brain sleep
+	if b.isRunCancelled(run) {
+		b.log().Debug().Str("neuronID", neu.id).Msg("run cancelled, skip activate neuron")
+		return nil
+	}
+	if b.skipRunOnce(neu, run) || b.skipRefire(neu, run) {
+		return nil
+	}
+	if err := b.takeStep(neu.id); err != nil {
+		b.log().Error().Err(err).Msg("abort run")
+		b.abortRun(err)
+		return err
+	}
+	if err := b.takeVisit(neu, run); err != nil {
+		b.log().Error().Err(err).Msg("abort run")
+		b.abortRun(err)
+		return err
+	}
+
+	b.log().Debug().
+		Interface("neuronID", neu.id).
+		Str("processorKind", processor.KindOf(neu.spec.processor)).
+		Msg("start activate neuron")
+	b.statusMu.Lock()
+	upstream := make([]string, 0)
+	payloads := make(map[string]interface{})
+	branches := make([]branchWrites, 0)
+	absent := neu.absentLinks(neu.status.triggeredBy)
+	// the signals of the satisfied trigger group are consumed once
+	for _, l := range neu.triggeredLinks(neu.status.triggeredBy) {
+		if !l.isEntryLink() && !utils.SlicesContains(upstream, []string{l.spec.from}) {
+			upstream = append(upstream, l.spec.from)
+		}
+		payloads[l.id] = l.status.signal.payload
+		branches = append(branches, l.status.signal.writes)
+		if !l.consumeSignal(run) {
+			neu.status.state = core.NeuronStateInactive
+			seq := l.status.signal.consumed
+			b.statusMu.Unlock()
 
-	b.logger.Debug().Interface("neuronID", neu.id).Msg("start activate neuron")
+			err := errors.ErrDoubleDelivery(l.id, neu.id, seq)
+			b.setRunErr(err)
+			b.publishEvent(maintainEvent{
+				kind:   eventKindNeuron,
+				action: eventActionNeuronTryInactive,
+				id:     neu.id,
+			})
+			return err
+		}
+	}
+	// the arrived signals of the optional links are delivered along, without blocking the trigger
+	for _, l := range neu.optionalLinks() {
+		if l.status.state == core.LinkStateReady && l.consumeSignal(run) {
+			payloads[l.id] = l.status.signal.payload
+			branches = append(branches, l.status.signal.writes)
+		}
+	}
+	// the branches join, the keys written by them are merged before the process
+	neu.status.writes = mergeBranchWrites(branches)
+	merges := neu.memoryMerges(branches)
 	neu.status.state = core.NeuronStateActivated
+	neu.status.errorGroup = ""
+	neu.status.payloads = nil
 	// in-link set init
 	for _, links := range neu.spec.triggerGroups {
 		for _, l := range links {
@@ -53,19 +202,105 @@ func (b *BrainLite) activateNeuron(neu *neuron) error {
 	}
 
 	neu.status.count.process++
+	triggeredBy := neu.status.triggeredBy
+	b.statusMu.Unlock()
 	// block process
-	err := neu.spec.processor.Process(&brainContext{
-		b:               b,
-		currentNeuronID: neu.id,
+	start := time.Now()
+	err := b.mergeMemory(neu, merges)
+	if err == nil {
+		bc := &brainContext{
+			b:               b,
+			run:             run,
+			currentNeuronID: neu.id,
+			triggeredBy:     triggeredBy,
+			payloads:        payloads,
+			absent:          absent,
+			inputDefaults:   neu.spec.inputDefaults,
+		}
+		var cancel context.CancelFunc
+		bc.ctx, cancel, err = b.budgetContext(neu.id, run)
+		if err == nil {
+			err = b.interceptMemory(bc)
+		}
+		if err == nil {
+			err = process(neu.spec.processor, bc)
+		}
+		if cancel != nil {
+			cancel()
+		}
+	}
+	b.addExecution(run, core.NeuronExecution{
+		NeuronID:      neu.id,
+		ProcessorKind: processor.KindOf(neu.spec.processor),
+		Start:         start,
+		Duration:      time.Since(start),
+		Err:           err,
+		Upstream:      upstream,
 	})
+	// the status of a cancelled run is reset, or owned by the next run
+	if b.isRunCancelled(run) {
+		b.log().Debug().Str("neuronID", neu.id).Msg("run cancelled, discard neuron result")
+		if err != nil {
+			return fmt.Errorf("process neuron error: %w", err)
+		}
+		return nil
+	}
+	if err != nil && errors.Is(err, processor.ErrAbortRun) {
+		err = fmt.Errorf("process neuron error: %w", err)
+		b.statusMu.Lock()
+		neu.status.count.failed++
+		b.statusMu.Unlock()
+		b.abortRun(err)
+		return err
+	}
+	// the failure is routed by the selector, instead of failing the run
+	if err != nil {
+		if group := b.selectOnError(neu, run, triggeredBy, err); group != "" {
+			b.log().Debug().Err(err).
+				Str("neuronID", neu.id).
+				Str("castGroup", group).
+				Msg("process neuron error routed by selector")
+			b.statusMu.Lock()
+			neu.status.state = core.NeuronStateInactive
+			neu.status.count.failed++
+			neu.status.errorGroup = group
+			b.statusMu.Unlock()
+			b.publishEvent(maintainEvent{
+				kind:   eventKindNeuron,
+				action: eventActionNeuronTryCast,
+				id:     neu.id,
+			})
+			return nil
+		}
+	}
+	b.statusMu.Lock()
 	neu.status.state = core.NeuronStateInactive
 	if err != nil {
 		neu.status.count.failed++
-		return fmt.Errorf("process neuron error: %w", err)
+		// out-links will not be cast
+		for _, links := range neu.spec.castGroups {
+			for _, l := range links {
+				if l.status.state == core.LinkStateWait {
+					l.status.state = core.LinkStateInit
+				}
+			}
+		}
+		b.statusMu.Unlock()
+
+		err = fmt.Errorf("process neuron error: %w", err)
+		b.setRunErr(err)
+		// refresh the brain state, the run sleeps if nothing else is running
+		b.publishEvent(maintainEvent{
+			kind:   eventKindNeuron,
+			action: eventActionNeuronTryInactive,
+			id:     neu.id,
+		})
+		return err
 	}
 
 	// SucceedCount++
 	neu.status.count.succeed++
+	b.statusMu.Unlock()
 
 	// cast
 	b.publishEvent(maintainEvent{
@@ -76,3 +311,122 @@ func (b *BrainLite) activateNeuron(neu *neuron) error {
 
 	return nil
 }
+
+// selectOnError returns the cast group selected on the process error by an ErrorAwareSelector, or else
+// outcomeFailureGroup if the neuron has out-links gated by processor.OutcomeFailure. Empty if the failure is not routed.
+func (b *BrainLite) selectOnError(neu *neuron, run uint64, triggeredBy string, err error) string {
+	selector, _ := b.selectorOf(neu)
+	if selector, ok := selector.(processor.ErrorAwareSelector); ok {
+		group := selector.SelectOnError(&brainContext{
+			b:               b,
+			run:             run,
+			currentNeuronID: neu.id,
+			triggeredBy:     triggeredBy,
+		}, err)
+		if group != "" {
+			return group
+		}
+	}
+	if len(neu.outcomeLinks(processor.OutcomeFailure)) != 0 {
+		return outcomeFailureGroup
+	}
+
+	return ""
+}
+
+// substituteNilProcessor substitutes an empty processor for the nil processor of the neuron, if allowed
+func (b *BrainLite) substituteNilProcessor(neu *neuron) {
+	if neu.spec.processor != nil || !b.allowNilProcessor {
+		return
+	}
+	b.logger.Warn().Str("neuronID", neu.id).Msg("nil processor substituted by an empty processor")
+	neu.spec.processor = &processor.EmptyProcessor{}
+}
+
+// applyDefaultSelector sets a clone of the default selector of the brain to the neuron without a selector bound explicitly
+func (b *BrainLite) applyDefaultSelector(neu *neuron) {
+	if b.defaultSelector == nil {
+		return
+	}
+	if _, ok := neu.spec.selector.(*processor.DefaultSelector); ok && !neu.spec.selectorBound {
+		neu.spec.selector = b.defaultSelector.Clone()
+	}
+}
+
+// takeVisit counts the activation of the neuron in the run, returns error beyond its max revisits
+func (b *BrainLite) takeVisit(neu *neuron, run uint64) error {
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	if neu.status.visitRun != run {
+		neu.status.visitRun = run
+		neu.status.visits = 0
+	}
+	if neu.spec.maxRevisits > 0 && neu.status.visits > neu.spec.maxRevisits {
+		return errors.ErrRevisitLimitExceeded(neu.id, neu.spec.maxRevisits)
+	}
+	neu.status.visits++
+
+	return nil
+}
+
+// skipRunOnce ignores the activation of a run-once neuron which already ran in the run, the signals of its satisfied
+// trigger are consumed, so they do not activate it again. Returns false if the neuron runs.
+func (b *BrainLite) skipRunOnce(neu *neuron, run uint64) bool {
+	b.statusMu.Lock()
+	if !neu.spec.runOnce || neu.status.visitRun != run || neu.status.visits == 0 {
+		b.statusMu.Unlock()
+		return false
+	}
+	b.statusMu.Unlock()
+	b.ignoreTrigger(neu, run, "run-once neuron already ran in the run, trigger ignored")
+
+	return true
+}
+
+// skipRefire ignores the activation of a neuron which already ran in the run, if its refire guard returns false.
+// The guard is consulted before the revisit is counted, see takeVisit. Returns false if the neuron runs.
+func (b *BrainLite) skipRefire(neu *neuron, run uint64) bool {
+	b.statusMu.Lock()
+	guard := neu.spec.refireGuard
+	refire := neu.status.visitRun == run && neu.status.visits != 0
+	triggeredBy := neu.status.triggeredBy
+	b.statusMu.Unlock()
+	if guard == nil || !refire {
+		return false
+	}
+	if guard(&brainContext{
+		b:               b,
+		run:             run,
+		currentNeuronID: neu.id,
+		triggeredBy:     triggeredBy,
+	}) {
+		return false
+	}
+	b.ignoreTrigger(neu, run, "refire guard ends the loop, trigger ignored")
+
+	return true
+}
+
+// ignoreTrigger consumes the signals of the satisfied trigger of the neuron without running it, so they do not
+// activate it again, and the neuron is inactive.
+func (b *BrainLite) ignoreTrigger(neu *neuron, run uint64, msg string) {
+	b.statusMu.Lock()
+	triggeredBy := neu.status.triggeredBy
+	for _, l := range neu.triggeredLinks(triggeredBy) {
+		l.consumeSignal(run)
+		l.status.state = core.LinkStateInit
+	}
+	neu.status.timedOut = false
+	neu.status.state = core.NeuronStateInactive
+	b.statusMu.Unlock()
+
+	b.log().Info().
+		Str("neuronID", neu.id).
+		Str("triggeredBy", triggeredBy).
+		Msg(msg)
+	b.publishEvent(maintainEvent{
+		kind:   eventKindNeuron,
+		action: eventActionNeuronTryInactive,
+		id:     neu.id,
+	})
+}
@@ -1,7 +1,12 @@
 package brainlite
 
 import (
+	"time"
+
 	"github.com/rs/zerolog"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
 )
 
 // Option configures a BrainLite in build.
@@ -16,13 +21,24 @@ func (f optionFunc) apply(brain *BrainLite) {
 	f(brain)
 }
 
-// WithNeuronWorkerNum sets the neuron process worker number
+// WithNeuronWorkerNum sets the neuron process worker number.
+// With one worker, neurons process one by one in the order they are activated, and the links cast at once
+// activate their neurons in the order of the link IDs, so the execution order of a blueprint is deterministic.
 func WithNeuronWorkerNum(workerNun int) Option {
 	return optionFunc(func(brain *BrainLite) {
 		brain.nWorkerNum = workerNun
 	})
 }
 
+// WithDeadlockDetection ends the run with core.ErrDeadlock, see Brain.GetRunError,
+// when no neuron is running but the ready links can never activate their neurons, e.g. a join waiting for a link
+// which is not selected. It is disabled by default, because links may still be triggered by TrigLinks later.
+func WithDeadlockDetection() Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.deadlockDetection = true
+	})
+}
+
 // WithNeuronQueueLen sets the neuron process queue length
 func WithNeuronQueueLen(nQueueLen int) Option {
 	return optionFunc(func(brain *BrainLite) {
@@ -30,23 +46,146 @@ func WithNeuronQueueLen(nQueueLen int) Option {
 	})
 }
 
-// WithLoggerLevel sets the default logger with specific level
+// WithLoggerLevel sets the level of the logger, a console logger writing to stdout is used if no logger is set.
 func WithLoggerLevel(level zerolog.Level) Option {
 	return optionFunc(func(brain *BrainLite) {
+		if brain.logger.GetLevel() == zerolog.Disabled {
+			brain.logger = utils.NewConsoleLogger()
+		}
 		brain.logger = brain.logger.Level(level)
 	})
 }
 
-// WithLogger sets the specific logger
+// WithLogger sets the specific logger, the brain is silent without a logger.
 func WithLogger(logger zerolog.Logger) Option {
 	return optionFunc(func(brain *BrainLite) {
 		brain.logger = logger
 	})
 }
 
+// WithLogSampling logs 1 in n runs in detail, the first run included.
+// Debug and info logs of the other runs are dropped, warnings and errors are always logged.
+func WithLogSampling(n int) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.logSampling = n
+	})
+}
+
+// WithRandSeed seeds the random source of every run with the seed, see processor.BrainContext.Rand,
+// so runs of the same inputs are reproducible. Without it, the source is seeded with the time the run starts.
+func WithRandSeed(seed int64) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.randSeed = &seed
+	})
+}
+
+// WithCancelOnSelectEnd cancels the neurons still processing when a selector ends the run by processor.SelectEnd,
+// like an aborted run but without error. By default, they finish without casting before the brain sleeps.
+func WithCancelOnSelectEnd() Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.cancelOnSelectEnd = true
+	})
+}
+
+// WithDeadlineBudget sets the total time of every run, split among its neuron processes by the strategy, e.g.
+// core.BudgetEqualSplit: the context of each process is done after its share of the budget left when it starts.
+// A process starting once the budget is spent fails with context.DeadlineExceeded. A nil strategy gives each process
+// the whole budget left.
+func WithDeadlineBudget(total time.Duration, strategy core.BudgetStrategy) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.budget = total
+		brain.budgetStrategy = strategy
+	})
+}
+
+// WithMaxSteps limits the neuron executions of a run, against runaway loops.
+// The run is aborted with core.ErrStepLimitExceeded instead of executing more than n neurons.
+func WithMaxSteps(n int) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.maxSteps = n
+	})
+}
+
+// WithNilProcessorAllowed substitutes a processor.EmptyProcessor for the nil processor of a neuron, with a warning logged.
+// By default the neuron fails its processes with core.ErrNilProcessor.
+func WithNilProcessorAllowed() Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.allowNilProcessor = true
+	})
+}
+
+// WithDefaultSelector sets the selector of the neurons which do not bind their own, i.e. still have the
+// processor.DefaultSelector. Each neuron gets a clone of the selector.
+func WithDefaultSelector(selector processor.Selector) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.defaultSelector = selector
+	})
+}
+
+// WithHooks sets the hooks called at the start and the end of every run, see core.Hooks.
+func WithHooks(hooks core.Hooks) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.hooks = hooks
+	})
+}
+
+// WithMetrics enables the metrics of the brain, e.g. core.MetricSelectorChoicesTotal. Metrics are disabled by default.
+func WithMetrics(metrics core.Metrics) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.metrics = metrics
+	})
+}
+
+// WithResultRetention sets the policy of keeping the neuron processes of a run, core.ResultRetentionFull by default.
+// With core.ResultRetentionMinimal the trace of the run is not kept, which bounds the memory of long or looping runs,
+// at the cost of an empty GetRunTrace, batch result trace and stats.
+func WithResultRetention(policy core.ResultRetention) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.resultRetention = policy
+	})
+}
+
+// WithUnknownGroupPolicy sets the policy of handling a cast group returned by a selector which the neuron does not
+// have, core.UnknownGroupError by default, which fails the run with core.ErrUnknownCastGroup.
+func WithUnknownGroupPolicy(policy core.UnknownGroupPolicy) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.unknownGroupPolicy = policy
+	})
+}
+
+// WithCompensationPolicy sets the policy of calling the compensations registered by the neuron processes,
+// core.CompensateOnFailure by default. See processor.BrainContext.RegisterCompensation.
+func WithCompensationPolicy(policy core.CompensationPolicy) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.compensationPolicy = policy
+	})
+}
+
+// WithMemoryInterceptor sets the function called with the context of each neuron process right before the processor,
+// e.g. to normalize the inputs or inject request-scoped IDs for all neurons. It may change the memory by the context.
+// It is called after the memory merges of the branches joining at the neuron. The input defaults of the neuron,
+// see core.WithInputDefaults, are read by the interceptor like by the processor, and a key it sets replaces the default.
+// A panic of the interceptor fails the process like a panic of the processor, which is not run then.
+// The completion processor is not intercepted.
+func WithMemoryInterceptor(fn func(neuronID string, bc processor.BrainContext)) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.memoryInterceptor = fn
+	})
+}
+
 // WithID sets the specific brain ID
 func WithID(brainID string) Option {
 	return optionFunc(func(brain *BrainLite) {
 		brain.id = brainID
 	})
 }
+
+// WithMemoryAudit sets the sink which receives every memory read, write and delete of the brain in order,
+// both from neurons and from outside the brain. The sink is called on the goroutines accessing memory, one event at a time
+// and without any lock held, see core.MemoryAuditor for the performance cost,
+// and use core.WithAuditKeyFilter or core.WithAuditSampling to reduce it.
+func WithMemoryAudit(sink func(event core.MemoryAuditEvent), withOpts ...core.MemoryAuditOption) Option {
+	return optionFunc(func(brain *BrainLite) {
+		brain.auditor = core.NewMemoryAuditor(sink, withOpts...)
+	})
+}
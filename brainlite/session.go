@@ -0,0 +1,201 @@
+package brainlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+type session struct {
+	b  *BrainLite
+	mu sync.Mutex
+	// the brain is entered by the first Feed
+	started bool
+	// run of the session
+	run uint64
+	// closed once the brain is entered by the first Feed, or the entry fails
+	entered chan struct{}
+	// error of the entry by the first Feed, the session is done then
+	enterErr error
+}
+
+// NewSession returns a session to run the brain incrementally. The first Feed enters the brain,
+// and every Feed returns once the run pauses for the required memories of the neurons, or completes.
+func (b *BrainLite) NewSession() core.Session {
+	return &session{b: b, entered: make(chan struct{})}
+}
+
+func (s *session) Feed(key, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done() {
+		return errors.ErrSessionDone(s.b.id)
+	}
+	if err := s.b.SetMemory(key, value); err != nil {
+		return err
+	}
+	if !s.started {
+		s.started = true
+		if err := s.b.Entry(); err != nil {
+			s.enterErr = err
+			close(s.entered)
+			return err
+		}
+		s.run = s.b.getRun()
+		close(s.entered)
+	}
+	// the feed is handled after the signals of the entry links, the run pauses after it at the earliest
+	seq := s.b.nextFeed()
+	s.b.publishEvent(maintainEvent{
+		kind:   eventKindMemory,
+		action: eventActionMemoryFed,
+		id:     strconv.FormatUint(seq, 10),
+	})
+	s.b.waitPaused(s.run, seq)
+
+	return nil
+}
+
+func (s *session) WaitForNeuron(ctx context.Context, neuronID string) error {
+	if _, ok := s.b.getNeuron(neuronID); !ok {
+		return errors.ErrNeuronNotFound(neuronID)
+	}
+	// Feed holds the lock of the session until the run pauses, the run is read once entered
+	select {
+	case <-s.entered:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if s.enterErr != nil {
+		return errors.ErrSessionNotEntered(s.b.id, s.enterErr)
+	}
+
+	return s.b.waitExecuted(ctx, s.run, neuronID)
+}
+
+func (s *session) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done()
+}
+
+func (s *session) done() bool {
+	if !s.started {
+		return false
+	}
+	if s.enterErr != nil {
+		return true
+	}
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	return s.b.run != s.run || s.b.state != core.BrainStateRunning
+}
+
+func (b *BrainLite) nextFeed() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.feeds++
+	return b.feeds
+}
+
+func (b *BrainLite) handleMemoryEvent(action eventAction, seq string) error {
+	switch action {
+	case eventActionMemoryFed:
+		fed, err := strconv.ParseUint(seq, 10, 64)
+		if err != nil {
+			return err
+		}
+		// the neurons waiting for the memory are resumed by the state refresh
+		b.mu.Lock()
+		b.handledFeeds = fed
+		b.mu.Unlock()
+		return nil
+	default:
+		return fmt.Errorf("unsupported memory action: %s", action)
+	}
+}
+
+// pause marks the run paused after the memories fed so far, should be called by the maintainer
+func (b *BrainLite) pause() {
+	b.mu.Lock()
+	b.pausedFeeds = b.handledFeeds
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+// waitPaused blocks until the run pauses after the feed of seq, or the run is over
+func (b *BrainLite) waitPaused(run, seq uint64) {
+	b.mu.Lock()
+	for b.state == core.BrainStateRunning && b.run == run && b.pausedFeeds < seq {
+		b.cond.Wait()
+	}
+	b.mu.Unlock()
+}
+
+// missingMemory returns the required memories of the neuron not set yet
+func (b *BrainLite) missingMemory(neu *neuron) []any {
+	missing := make([]any, 0)
+	for _, key := range neu.spec.requiredMemory {
+		if !b.hasMemory(key) {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
+}
+
+// findResumableNeurons should be called with statusMu locked, returns the sorted IDs of the neurons
+// triggered and waiting for their required memories, which are set now.
+func (b *BrainLite) findResumableNeurons() []string {
+	resumable := make([]string, 0)
+	for _, neu := range b.neurons {
+		if len(neu.spec.requiredMemory) == 0 || neu.status.state == core.NeuronStateActivated {
+			continue
+		}
+		if _, should := b.ifNeuronShouldActivate(neu); should {
+			resumable = append(resumable, neu.id)
+		}
+	}
+	sort.Strings(resumable)
+
+	return resumable
+}
+
+// waitExecuted blocks until the neuron is executed in the run, the run is over, or the context is done
+func (b *BrainLite) waitExecuted(ctx context.Context, run uint64, neuronID string) error {
+	// the cond does not select on the context, wake the waiters once it is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			b.cond.Broadcast()
+			b.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if b.run != run {
+			return errors.ErrNeuronNotExecuted(neuronID, b.id)
+		}
+		if _, ok := b.executed[neuronID]; ok {
+			return nil
+		}
+		if b.state != core.BrainStateRunning {
+			return errors.ErrNeuronNotExecuted(neuronID, b.id)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b.cond.Wait()
+	}
+}
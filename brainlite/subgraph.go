@@ -0,0 +1,88 @@
+package brainlite
+
+import (
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+// TakenSubgraph builds a brain of the part of the topology taken by the last run, e.g. to visualize the path taken
+// or to replay only the relevant part: the neurons executed, the End neurons reached, and the links which delivered
+// a signal between them. It is built like a worker of RunBatch, with the options of this brain and its own clones of
+// the processors and selectors, so it runs independently. The trigger groups of a neuron keep their links taken,
+// a neuron fired on a part of its links, e.g. by a trigger timeout, fires on them alone in the subgraph.
+// The executed neurons are read from the trace, it fails if the trace is not retained, see WithResultRetention.
+func (b *BrainLite) TakenSubgraph() (*BrainLite, error) {
+	if b.getState() == core.BrainStateRunning {
+		return nil, errors.ErrBrainRunning(b.id)
+	}
+	kept := make(map[string]bool)
+	for _, e := range b.GetRunTrace() {
+		kept[e.NeuronID] = true
+	}
+	if len(kept) == 0 {
+		return nil, errors.Wrapf(core.ErrNoNeurons, "no neuron executed in the trace of the last run of brain %s", b.id)
+	}
+	for _, id := range b.GetReachedEnds() {
+		kept[id] = true
+	}
+
+	run := b.getRun()
+	taken := make(map[string]bool)
+	b.topoMu.RLock()
+	b.statusMu.Lock()
+	for id, l := range b.links {
+		if l.signalCount(run) > 0 && (l.isEntryLink() || kept[l.spec.from]) && kept[l.spec.to] {
+			taken[id] = true
+		}
+	}
+	b.statusMu.Unlock()
+	b.topoMu.RUnlock()
+
+	w := b.buildWorker()
+	w.prune(kept, taken)
+
+	return w, nil
+}
+
+// prune removes the neurons and links not kept from the brain, and the links removed from the groups of the neurons.
+// A trigger group left without links is removed. The brain should not be running.
+func (b *BrainLite) prune(neurons, links map[string]bool) {
+	b.topoMu.Lock()
+	defer b.topoMu.Unlock()
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	for id := range b.links {
+		if !links[id] {
+			delete(b.links, id)
+		}
+	}
+	for id, n := range b.neurons {
+		if !neurons[id] {
+			delete(b.neurons, id)
+			b.subsystems.remove(id)
+			continue
+		}
+		for name, group := range n.spec.castGroups {
+			n.spec.castGroups[name] = b.keptLinks(group)
+		}
+		for key, group := range n.spec.triggerGroups {
+			if kept := b.keptLinks(group); len(kept) > 0 {
+				n.spec.triggerGroups[key] = kept
+			} else {
+				delete(n.spec.triggerGroups, key)
+			}
+		}
+	}
+}
+
+// keptLinks returns the links still in the brain, should be called with topoMu locked
+func (b *BrainLite) keptLinks(links []*link) []*link {
+	ret := make([]*link, 0, len(links))
+	for _, l := range links {
+		if _, ok := b.links[l.id]; ok {
+			ret = append(ret, l)
+		}
+	}
+
+	return ret
+}
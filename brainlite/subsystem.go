@@ -0,0 +1,152 @@
+package brainlite
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+// subsystems groups the neurons of the brain, protected by statusMu
+type subsystems struct {
+	// key: subsystem name, value: set of neuron IDs
+	neurons map[string]map[string]struct{}
+	// key: neuron ID, value: subsystem name
+	of map[string]string
+	// names of the disabled subsystems
+	disabled map[string]struct{}
+}
+
+func (s *subsystems) add(name, neuronID string) {
+	if s.neurons == nil {
+		s.neurons = make(map[string]map[string]struct{})
+		s.of = make(map[string]string)
+		s.disabled = make(map[string]struct{})
+	}
+	if s.neurons[name] == nil {
+		s.neurons[name] = make(map[string]struct{})
+	}
+	s.neurons[name][neuronID] = struct{}{}
+	s.of[neuronID] = name
+}
+
+func (s *subsystems) remove(neuronID string) {
+	name, ok := s.of[neuronID]
+	if !ok {
+		return
+	}
+	delete(s.of, neuronID)
+	delete(s.neurons[name], neuronID)
+}
+
+func (s *subsystems) isDisabled(neuronID string) bool {
+	name, ok := s.of[neuronID]
+	if !ok {
+		return false
+	}
+	_, disabled := s.disabled[name]
+
+	return disabled
+}
+
+func (s *subsystems) deepCopy() subsystems {
+	var cp subsystems
+	for name, neurons := range s.neurons {
+		for id := range neurons {
+			cp.add(name, id)
+		}
+	}
+	for name := range s.disabled {
+		cp.disabled[name] = struct{}{}
+	}
+
+	return cp
+}
+
+func (b *BrainLite) DefineSubsystem(name string, neuronIDs ...string) error {
+	if name == "" {
+		return fmt.Errorf("subsystem name is empty")
+	}
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	for _, id := range neuronIDs {
+		if _, ok := b.neurons[id]; !ok {
+			return errors.ErrNeuronNotFound(id)
+		}
+		if other, ok := b.subsystems.of[id]; ok && other != name {
+			return errors.ErrSubsystemConflict(id, other)
+		}
+	}
+	for _, id := range neuronIDs {
+		b.subsystems.add(name, id)
+	}
+
+	return nil
+}
+
+func (b *BrainLite) ListSubsystems() map[string][]string {
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	ret := make(map[string][]string, len(b.subsystems.neurons))
+	for name := range b.subsystems.neurons {
+		ret[name] = b.subsystemNeuronIDs(name)
+	}
+
+	return ret
+}
+
+func (b *BrainLite) SetSubsystemEnabled(name string, enabled bool) error {
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	if _, ok := b.subsystems.neurons[name]; !ok {
+		return errors.ErrSubsystemNotFound(name)
+	}
+	if enabled {
+		delete(b.subsystems.disabled, name)
+	} else {
+		b.subsystems.disabled[name] = struct{}{}
+	}
+	b.log().Info().Str("subsystem", name).Bool("enabled", enabled).Msg("set subsystem enabled")
+
+	return nil
+}
+
+func (b *BrainLite) GetSubsystemStats(name string) (core.RunStats, error) {
+	b.statusMu.Lock()
+	neurons, ok := b.subsystems.neurons[name]
+	if !ok {
+		b.statusMu.Unlock()
+		return core.RunStats{}, errors.ErrSubsystemNotFound(name)
+	}
+	ids := make(map[string]struct{}, len(neurons))
+	for id := range neurons {
+		ids[id] = struct{}{}
+	}
+	b.statusMu.Unlock()
+
+	trace := make([]core.NeuronExecution, 0)
+	for _, e := range b.GetRunTrace() {
+		if _, ok = ids[e.NeuronID]; ok {
+			trace = append(trace, e)
+		}
+	}
+
+	return core.NewRunStats(trace, core.DefaultStatsSlowestN), nil
+}
+
+// subsystemNeuronIDs returns the sorted neuron IDs of the subsystem, statusMu should be held
+func (b *BrainLite) subsystemNeuronIDs(name string) []string {
+	ids := make([]string, 0, len(b.subsystems.neurons[name]))
+	for id := range b.subsystems.neurons[name] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
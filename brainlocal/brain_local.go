@@ -1,21 +1,25 @@
 package brainlocal
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"strings"
+	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
 	"github.com/rs/zerolog"
 	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
 	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
 )
 
 const (
-	//  length of brain event queue
-	bQueueLen = 10
+	// default length of brain event queue, which buffers the link signals
+	defaultBQueueLen = 10
 	// default length of neuron process queue
 	defaultNQueueLen = 10
 	// default number of neuron process workers
@@ -33,6 +37,9 @@ func BuildBrain(blueprint core.Blueprint, withOpts ...Option) *BrainLocal {
 		state:   core.BrainStateShutdown,
 		neurons: make(map[string]*neuron),
 		links:   make(map[string]*link),
+		// kept for building worker brains of RunBatch
+		blueprint: blueprint.Clone(),
+		options:   withOpts,
 	}
 	b.cond = sync.NewCond(&b.mu)
 
@@ -46,26 +53,9 @@ func BuildBrain(blueprint core.Blueprint, withOpts ...Option) *BrainLocal {
 	}
 
 	// init config
-	b.logger = zerolog.New(zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: time.RFC3339,
-		FormatCaller: func(i interface{}) string {
-			var c string
-			if cc, ok := i.(string); ok {
-				c = cc
-			}
-			if len(c) > 0 && strings.Contains(c, "/") {
-				lastIndex := strings.LastIndex(c, "/")
-				left := c[:lastIndex]
-				c = c[lastIndex+1:]
-				if strings.Contains(left, "/") {
-					lastIndex = strings.LastIndex(left, "/")
-					c = left[lastIndex+1:] + "/" + c
-				}
-			}
-			return c
-		},
-	}).With().Caller().Timestamp().Logger().Level(zerolog.InfoLevel)
+	// silent unless a logger is set, see WithLogger
+	b.logger = zerolog.Nop()
+	b.BrainMaintainer.bQueueLen = defaultBQueueLen
 	b.BrainMaintainer.nQueueLen = defaultNQueueLen
 	b.BrainMaintainer.nWorkerNum = defaultNWorkerNum
 	b.BrainMemory.numCounters = defaultMemNumCounters
@@ -76,6 +66,23 @@ func BuildBrain(blueprint core.Blueprint, withOpts ...Option) *BrainLocal {
 	}
 
 	b.logger = b.logger.With().Str("brainID", b.id).Logger()
+	for _, neu := range b.neurons {
+		b.substituteNilProcessor(neu)
+		b.applyDefaultSelector(neu)
+	}
+	// the first failure in order of neuron ID fails the build
+	neuronIDs := make([]string, 0, len(b.neurons))
+	for id := range b.neurons {
+		neuronIDs = append(neuronIDs, id)
+	}
+	sort.Strings(neuronIDs)
+	for _, id := range neuronIDs {
+		if err := b.resolveAtBuild(b.neurons[id]); err != nil {
+			b.buildErr = err
+			b.logger.Error().Err(err).Msg("brain build failed")
+			return b
+		}
+	}
 
 	b.logger.Info().Interface("blueprint", blueprint).Msg("brain build success")
 	return b
@@ -88,12 +95,117 @@ type BrainLocal struct {
 	neurons map[string]*neuron
 	links   map[string]*link
 
+	// error of the build, e.g. of a processor.BuildResolver, returned by every run
+	buildErr error
+
 	// brain is in the Running state when there are 1 or more Activate neuron or 1 or more StandBy link.
 	state core.BrainState
+	// IDs of End neurons reached in the current (or last) run
+	reachedEnds []string
+	// the first neuron process error of the current (or last) run
+	runErr error
+	// neuron processes of the current (or last) run, in order of finish
+	runTrace []core.NeuronExecution
+	// IDs of the neurons executed in the current (or last) run, regardless of resultRetention
+	executed map[string]struct{}
+	// IDs of the neurons executed in the current (or last) run in order of finish, regardless of resultRetention
+	executedOrder []string
+	// policy of keeping the neuron processes in runTrace, see WithResultRetention
+	resultRetention core.ResultRetention
+	// sequence of the current (or last) run, increased when a run starts
+	run uint64
+	// the current (or last) run is aborted by a processor, see processor.ErrAbortRun,
+	// or ended by a selector with WithCancelOnSelectEnd
+	aborted bool
+	// the current (or last) run is ended by a selector returning processor.SelectEnd
+	ended bool
+	// cancel the neurons still processing when a selector ends the run, see WithCancelOnSelectEnd
+	cancelOnSelectEnd bool
+	// random source of the current (or last) run, seeded with randSeed if set
+	runRand *rand.Rand
+	// context of the current (or last) run, cancelled once the run is aborted or a new run starts
+	runCtx       context.Context
+	cancelRunCtx func(reason core.CancelReason)
+	// selectors overriding the selectors of the neurons in the current (or last) run, key: neuron ID, see Run
+	runSelectors map[string]processor.Selector
+	// labels of the current (or last) run, see Run
+	runLabels map[string]string
+	// logger of the current run with its labels, nil for the logger of the brain
+	runLogger atomic.Pointer[zerolog.Logger]
+	// called before each neuron process, see WithMemoryInterceptor
+	memoryInterceptor func(neuronID string, bc processor.BrainContext)
+	// policy of handling a cast group selected but unknown to the neuron, see WithUnknownGroupPolicy
+	unknownGroupPolicy core.UnknownGroupPolicy
+	// seed of the random source of every run, see WithRandSeed
+	randSeed *int64
+	// processor run once at the end of a run, see SetCompletionProcessor
+	completion processor.Processor
+	// the completion processor has run in the current (or last) run
+	completed bool
+	// compensations registered by the neuron processes of the current run, in order of registration
+	compensations []compensation
+	// policy of calling the compensations, see WithCompensationPolicy
+	compensationPolicy core.CompensationPolicy
+	// funcs deferred by the neuron processes of the current run, in order of registration
+	deferred []deferred
+	// substitute an empty processor for the nil processor of a neuron, see WithNilProcessorAllowed
+	allowNilProcessor bool
+	// selector of the neurons without their own, nil for processor.DefaultSelector, see WithDefaultSelector
+	defaultSelector processor.Selector
+	// maximum neuron executions of a run, 0 for unlimited, see WithMaxSteps
+	maxSteps int
+	// deadline budget of every run split among its neuron processes by budgetStrategy, 0 for none, see WithDeadlineBudget
+	budget         time.Duration
+	budgetStrategy core.BudgetStrategy
+	// neuron executions of the current (or last) run
+	steps int
+	// ID of the last neuron executed in the current (or last) run
+	lastExecuted string
+	// log 1 in logSampling runs in detail, see WithLogSampling
+	logSampling int
+	// detect deadlock when nothing is running, see WithDeadlockDetection
+	deadlockDetection bool
+	// subsystems of the neurons, see DefineSubsystem
+	subsystems subsystems
+	// run hooks, see WithHooks
+	hooks core.Hooks
+	// metrics, nil when metrics disabled, see WithMetrics
+	metrics core.Metrics
+	// memories set by the caller since the last run, the initial memory of core.Hooks.OnRunStart
+	entryMemory map[any]any
+	// start time of the current (or last) run
+	runStartedAt time.Time
+	// the OnRunEnd hook of the current run is not called yet
+	runEndPending bool
+	// sequence of the memories fed by the sessions, the last one handled by the maintainer,
+	// and the last one handled before the run paused, see NewSession
+	feeds        uint64
+	handledFeeds uint64
+	pausedFeeds  uint64
 	// brain memories
 	BrainMemory
 	BrainMaintainer
 
+	// statusMu protects the status of neurons and links, the groups of neurons, and the subsystems
+	statusMu sync.Mutex
+	// sequence of the memory writes tracked for the merge resolvers, protected by statusMu
+	writeSeq uint64
+	// topoMu protects the neurons and links index, edits of the topology hold it with statusMu
+	topoMu sync.RWMutex
+
+	// memory auditor, nil when memory audit disabled
+	auditor *core.MemoryAuditor
+	// auditMu orders audited memory accesses, see auditMemory
+	auditMu sync.Mutex
+	// keys set in the memory, see snapshotMemory
+	memoryKeys memoryKeys
+	// streams set in the memory by the current run, closed once the run is over
+	streams memoryStreams
+
+	// blueprint and options the brain is built from, the topology of the blueprint may be outdated by edits
+	blueprint core.Blueprint
+	options   []Option
+
 	logger zerolog.Logger
 	mu     sync.Mutex
 	cond   *sync.Cond
@@ -105,16 +217,21 @@ type BrainMemory struct {
 	maxCost     int64
 }
 type BrainMaintainer struct {
-	bQueue chan maintainEvent
-	stop   chan struct{}
+	bQueue    chan maintainEvent
+	bQueueLen int
+	// events and activations published by the maintainer itself, owned by the maintainer goroutine
+	backlog *maintainerBacklog
+	stop    chan struct{}
 
 	NeuronRunner
 }
 
 type NeuronRunner struct {
-	nQueue     chan string
+	nQueue     chan activation
 	nQueueLen  int
 	nWorkerNum int
+	// the worker slots handed back to the yielding processes, see yield
+	reclaim chan struct{}
 }
 
 func (b *BrainLocal) TrigLinks(links ...core.Link) error {
@@ -129,15 +246,60 @@ func (b *BrainLocal) TrigLinks(links ...core.Link) error {
 }
 
 func (b *BrainLocal) Entry() error {
-	// get all entry links
+	linkIDs, err := b.entryLinkIDs()
+	if err != nil {
+		return err
+	}
+
+	return b.trigLinks(linkIDs...)
+}
+
+// Run enters the brain like Entry, with the options applied to the new run only.
+// The selector overrides are cloned into the run, so they never change the brain nor the other runs.
+// The labels of the run are added to its metrics and logs, and set to its context.
+func (b *BrainLocal) Run(withOpts ...core.RunOption) error {
+	config := core.NewRunConfig(withOpts...)
+	for label := range config.Labels {
+		if label == core.MetricLabelNeuron || label == core.MetricLabelGroup {
+			return errors.ErrInvalidRunLabels(label)
+		}
+	}
+	b.topoMu.RLock()
+	for neuronID := range config.SelectorOverrides {
+		if _, ok := b.neurons[neuronID]; !ok {
+			b.topoMu.RUnlock()
+			return errors.ErrNeuronNotFound(neuronID)
+		}
+	}
+	b.topoMu.RUnlock()
+	linkIDs, err := b.entryLinkIDs()
+	if err != nil {
+		return err
+	}
+
+	return b.trigLinksInRun(config, linkIDs...)
+}
+
+// entryLinkIDs returns the sorted IDs of the entry links, so the entry neurons are activated in a stable order
+func (b *BrainLocal) entryLinkIDs() ([]string, error) {
 	linkIDs := make([]string, 0)
+	b.topoMu.RLock()
+	empty := true
+	for id := range b.neurons {
+		empty = empty && core.IsEndNeuronID(id)
+	}
 	for _, l := range b.links {
 		if l.isEntryLink() {
 			linkIDs = append(linkIDs, l.id)
 		}
 	}
+	b.topoMu.RUnlock()
+	if empty {
+		return nil, core.ErrNoNeurons
+	}
+	sort.Strings(linkIDs)
 
-	return b.trigLinks(linkIDs...)
+	return linkIDs, nil
 }
 
 func (b *BrainLocal) EntryWithMemory(keysAndValues ...interface{}) error {
@@ -149,9 +311,35 @@ func (b *BrainLocal) EntryWithMemory(keysAndValues ...interface{}) error {
 }
 
 func (b *BrainLocal) SetMemory(keysAndValues ...interface{}) error {
+	return b.setMemory("", keysAndValues...)
+}
+
+func (b *BrainLocal) GetMemory(key any) any {
+	return b.getMemory("", key)
+}
+
+func (b *BrainLocal) ExistMemory(key any) bool {
+	return b.existMemory("", key)
+}
+
+func (b *BrainLocal) DeleteMemory(key any) {
+	b.deleteMemory("", key)
+}
+
+func (b *BrainLocal) ClearMemory() {
+	b.clearMemory("")
+}
+
+func (b *BrainLocal) setMemory(neuronID string, keysAndValues ...interface{}) error {
 	if len(keysAndValues)%2 != 0 {
 		return fmt.Errorf("key and value are not paired")
 	}
+	// all keys are checked before any memory is set
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if core.IsReservedMemoryKey(keysAndValues[i]) {
+			return errors.ErrReservedMemoryKey(keysAndValues[i])
+		}
+	}
 	if err := b.ensureMemoryInit(); err != nil {
 		// TODO wrap error
 		return err
@@ -160,56 +348,250 @@ func (b *BrainLocal) SetMemory(keysAndValues ...interface{}) error {
 	for i := 0; i < len(keysAndValues); i += 2 {
 		k := keysAndValues[i]
 		v := keysAndValues[i+1]
-		b.BrainMemory.cache.Set(k, v, 1) // TODO maybe calculate cost
-		b.logger.Debug().
+		// the set of the cache is buffered, it is applied before the write is audited, so the audit order is the order of the values
+		_, _ = b.auditMemory(neuronID, core.MemoryOperationWrite, k, func() (any, error) {
+			b.BrainMemory.cache.Set(k, v, 1) // TODO maybe calculate cost
+			b.BrainMemory.cache.Wait()
+			return v, nil
+		})
+		b.memoryKeys.add(k)
+		if stream, ok := v.(*processor.Stream); ok {
+			b.streams.add(stream)
+		}
+		b.recordBranchWrite(neuronID, k, v)
+		b.log().Debug().
 			Any("key", k).
 			Any("value", v).
 			Msg("set memory")
 	}
-	b.BrainMemory.cache.Wait()
+	b.recordEntryMemory(neuronID, func(memory map[any]any) {
+		for i := 0; i < len(keysAndValues); i += 2 {
+			memory[keysAndValues[i]] = keysAndValues[i+1]
+		}
+	})
 
 	return nil
 }
 
-func (b *BrainLocal) GetMemory(key any) any {
+func (b *BrainLocal) getMemory(neuronID string, key any) any {
 	if b.BrainMemory.cache == nil {
 		return nil
 	}
-	v, _ := b.BrainMemory.cache.Get(key)
+	v, _ := b.auditMemory(neuronID, core.MemoryOperationRead, key, func() (any, error) {
+		v, _ := b.BrainMemory.cache.Get(key)
+		return v, nil
+	})
 
 	return v
 }
 
-func (b *BrainLocal) ExistMemory(key any) bool {
+func (b *BrainLocal) existMemory(neuronID string, key any) bool {
 	if b.BrainMemory.cache == nil {
 		return false
 	}
 
+	var ok bool
+	_, _ = b.auditMemory(neuronID, core.MemoryOperationRead, key, func() (any, error) {
+		var v any
+		v, ok = b.BrainMemory.cache.Get(key)
+		return v, nil
+	})
+
+	return ok
+}
+
+// peekMemory returns the memory of the key, without audit
+func (b *BrainLocal) peekMemory(key any) (any, bool) {
+	if b.BrainMemory.cache == nil {
+		return nil, false
+	}
+
+	return b.BrainMemory.cache.Get(key)
+}
+
+// setInternalMemory sets the memory of a reserved key, see core.ReservedMemoryKeyPrefix, without audit.
+// Unlike setMemory, the key is not in the snapshots of the memory, nor a write of the branch merged at the joins.
+func (b *BrainLocal) setInternalMemory(key string, value any) error {
+	if !core.IsReservedMemoryKey(key) {
+		return fmt.Errorf("internal memory key %s is not reserved", key)
+	}
+	if err := b.ensureMemoryInit(); err != nil {
+		return err
+	}
+	b.BrainMemory.cache.Set(key, value, 1)
+	b.BrainMemory.cache.Wait()
+
+	return nil
+}
+
+// hasMemory indicates whether there is a memory in the brain, without audit
+func (b *BrainLocal) hasMemory(key any) bool {
+	if b.BrainMemory.cache == nil {
+		return false
+	}
 	_, ok := b.BrainMemory.cache.Get(key)
 
 	return ok
 }
 
-func (b *BrainLocal) DeleteMemory(key any) {
+func (b *BrainLocal) deleteMemory(neuronID string, key any) {
 	if b.BrainMemory.cache == nil {
 		return
 	}
 
-	b.BrainMemory.cache.Del(key)
+	_, _ = b.auditMemory(neuronID, core.MemoryOperationDelete, key, func() (any, error) {
+		b.BrainMemory.cache.Del(key)
+		b.BrainMemory.cache.Wait()
+		return nil, nil
+	})
+	b.memoryKeys.remove(key)
+	b.recordEntryMemory(neuronID, func(memory map[any]any) {
+		delete(memory, key)
+	})
 }
 
-func (b *BrainLocal) ClearMemory() {
+func (b *BrainLocal) clearMemory(neuronID string) {
 	if b.BrainMemory.cache == nil {
 		return
 	}
 
-	b.BrainMemory.cache.Clear()
+	_, _ = b.auditMemory(neuronID, core.MemoryOperationClear, nil, func() (any, error) {
+		b.BrainMemory.cache.Clear()
+		return nil, nil
+	})
+	b.memoryKeys.clear()
+	b.recordEntryMemory(neuronID, func(memory map[any]any) {
+		for key := range memory {
+			delete(memory, key)
+		}
+	})
+}
+
+// auditMemory runs the memory access, with the audit event recorded in the same critical section,
+// so the sequence of events is the order of accesses. Events are delivered after the critical section.
+// A failed access is not audited.
+func (b *BrainLocal) auditMemory(neuronID string, op core.MemoryOperation, key any, access func() (any, error)) (any, error) {
+	if b.auditor == nil {
+		return access()
+	}
+
+	b.auditMu.Lock()
+	v, err := access()
+	if err == nil {
+		b.auditor.Record(b.id, neuronID, op, key, v)
+	}
+	b.auditMu.Unlock()
+	b.auditor.Flush()
+
+	return v, err
+}
+
+// log returns the logger of the current run with its labels, the debug and info logs of runs not sampled are dropped, see WithLogSampling
+func (b *BrainLocal) log() *zerolog.Logger {
+	logger := b.runLogger.Load()
+	if logger == nil {
+		logger = &b.logger
+	}
+	if b.logSampling <= 1 {
+		return logger
+	}
+	run := b.getRun()
+	if run == 0 || (run-1)%uint64(b.logSampling) == 0 || logger.GetLevel() >= zerolog.WarnLevel {
+		return logger
+	}
+	sampled := logger.Level(zerolog.WarnLevel)
+
+	return &sampled
 }
 
 func (b *BrainLocal) GetState() core.BrainState {
 	return b.getState()
 }
 
+func (b *BrainLocal) GetReachedEnds() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ret := make([]string, len(b.reachedEnds))
+	copy(ret, b.reachedEnds)
+
+	return ret
+}
+
+// Reset clears the memory and the status of all neurons and links left by the last run,
+// so the brain can be reused for the next run instead of building a new one.
+// The brain can not be reset while running.
+func (b *BrainLocal) Reset() error {
+	// a run starts with topoMu read locked, so no run starts during the reset
+	b.topoMu.Lock()
+	defer b.topoMu.Unlock()
+	if b.getState() == core.BrainStateRunning {
+		return errors.ErrBrainRunning(b.id)
+	}
+
+	b.resetStatus()
+	b.resetRunStatus()
+	b.ClearMemory()
+
+	return nil
+}
+
+// GetRunTrace returns the neuron processes of the current (or last) run, in order of finish
+func (b *BrainLocal) GetRunTrace() core.Trace {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ret := make([]core.NeuronExecution, len(b.runTrace))
+	copy(ret, b.runTrace)
+
+	return ret
+}
+
+// GetRunLabels returns the labels of the current (or last) run, see core.WithRunLabels
+func (b *BrainLocal) GetRunLabels() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return utils.LabelsDeepCopy(b.runLabels)
+}
+
+// GetExecutedNeurons returns a snapshot of the IDs of the neurons processed so far in the current (or last) run,
+// in order of finish
+func (b *BrainLocal) GetExecutedNeurons() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append(make([]string, 0, len(b.executedOrder)), b.executedOrder...)
+}
+
+func (b *BrainLocal) GetRunError() error {
+	return b.getRunErr()
+}
+
+// LinkSignalCount returns the number of signals delivered by the link in the current (or last) run,
+// a link delivers a signal when it is triggered or cast.
+func (b *BrainLocal) LinkSignalCount(linkID string) int {
+	run := b.getRun()
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	l, ok := b.links[linkID]
+	if !ok {
+		return 0
+	}
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	return l.signalCount(run)
+}
+
+// SetCompletionProcessor sets the processor run once at the end of every run which reaches an End neuron without error.
+// It runs after the neurons are put to sleep and before the brain state is Sleeping, so Wait returns after it.
+// Its current neuron ID is core.CompletionNeuronID, and its error is the run error. Nil removes it.
+// With more than one End neuron, it runs once, when the first End neuron is reached and the run ends.
+func (b *BrainLocal) SetCompletionProcessor(p processor.Processor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.completion = p
+}
+
 func (b *BrainLocal) Wait() {
 	// block when brain running
 	b.mu.Lock()
@@ -221,13 +603,37 @@ func (b *BrainLocal) Wait() {
 
 func (b *BrainLocal) Shutdown() {
 	b.logger.Info().Msg("brain local shutdown")
-	close(b.BrainMaintainer.nQueue)
-	close(b.BrainMaintainer.bQueue)
-	b.BrainMemory.cache.Close()
-	b.setState(core.BrainStateShutdown)
+	b.mu.Lock()
+	// the maintainer of a brain never triggered, or already shut down, is not running
+	running := b.state != core.BrainStateShutdown
+	b.state = core.BrainStateShutdown
+	b.cancelRunContext(core.CancelReasonShutdown)
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	// queues are not closed, a neuron worker may still publish events after the brain is sleeping,
+	// publishers and workers select on stop instead
+	if running {
+		close(b.BrainMaintainer.stop)
+	}
+	b.runDeferred()
+	b.notifyRunEnd()
+	b.closeStreams()
+	if b.BrainMemory.cache != nil {
+		b.BrainMemory.cache.Close()
+	}
 }
 
 func (b *BrainLocal) trigLinks(linkIDs ...string) error {
+	return b.trigLinksInRun(nil, linkIDs...)
+}
+
+// trigLinksInRun triggers the links, a new run is configured by the config if not nil.
+// Returns ErrBrainRunning if the config can not be applied, the brain is running already.
+func (b *BrainLocal) trigLinksInRun(config *core.RunConfig, linkIDs ...string) error {
+	if b.buildErr != nil {
+		return b.buildErr
+	}
 	if len(linkIDs) == 0 {
 		return nil
 	}
@@ -240,40 +646,52 @@ func (b *BrainLocal) trigLinks(linkIDs ...string) error {
 	// ensure brain maintainer start
 	b.ensureMaintainerStart()
 
-	// goroutine wait
-	var wg sync.WaitGroup
+	links := make([]*link, 0, len(linkIDs))
+	b.topoMu.RLock()
 	for _, linkID := range linkIDs {
 		l, ok := b.links[linkID]
 		if !ok {
 			continue
 		}
-
-		wg.Add(1)
-		go b.trigLink(&wg, l)
+		links = append(links, l)
+	}
+	if len(links) == 0 {
+		b.topoMu.RUnlock()
+		return nil
+	}
+	// the brain is running before any link is triggered, so the topology can not be edited during the run,
+	// and the maintainer refreshes the state after handling the triggered links
+	started := b.startRun(config)
+	b.topoMu.RUnlock()
+	if !started && config != nil {
+		return errors.ErrBrainRunning(b.id)
+	}
+	if started {
+		b.notifyRunStart()
+		b.seedRun()
 	}
-	wg.Wait()
-
-	b.refreshState()
-
-	return nil
-}
-
-func (b *BrainLocal) trigLink(wg *sync.WaitGroup, l *link) {
-	defer wg.Done()
 
-	if l.status.state != core.LinkStateReady {
-		// change link state as ready
-		l.status.state = core.LinkStateReady
+	// links are ready at once, so the maintainer never observes a part of them ready
+	readyLinks := make([]string, 0, len(links))
+	run := b.getRun()
+	b.statusMu.Lock()
+	for _, l := range links {
+		if l.status.state != core.LinkStateReady {
+			l.deliverSignal(run, nil, nil)
+			readyLinks = append(readyLinks, l.id)
+		}
+	}
+	b.statusMu.Unlock()
 
-		// send maintain event
+	for _, linkID := range readyLinks {
 		b.publishEvent(maintainEvent{
 			kind:   eventKindLink,
 			action: eventActionLinkReady,
-			id:     l.id,
+			id:     linkID,
 		})
 	}
 
-	return
+	return nil
 }
 
 func (b *BrainLocal) ensureMemoryInit() error {
@@ -18,6 +18,7 @@ const (
 	eventKindNeuron eventKind = "neuron"
 	eventKindLink   eventKind = "link"
 	eventKindBrain  eventKind = "brain"
+	eventKindMemory eventKind = "memory"
 
 	eventActionLinkInit          eventAction = "link_init"
 	eventActionLinkReady         eventAction = "link_ready"
@@ -26,8 +27,10 @@ const (
 	eventActionNeuronTryInactive eventAction = "try_inactive_neuron"
 	eventActionNeuronTryCast     eventAction = "try_cast"
 	eventActionNeuronCastAnyway  eventAction = "cast_anyway"
+	eventActionNeuronTimeout     eventAction = "trigger_timeout"
 	eventActionBrainSleep        eventAction = "brain_sleep"
 	eventActionBrainShutdown     eventAction = "brain_shutdown"
+	eventActionMemoryFed         eventAction = "memory_fed"
 )
 
 func (m maintainEvent) MarshalZerologObject(e *zerolog.Event) {
@@ -40,7 +43,25 @@ func (b *BrainLocal) publishEvent(event maintainEvent) {
 	if b.getState() == core.BrainStateShutdown || b.bQueue == nil {
 		return
 	}
-	b.logger.Debug().Interface("event", event).Msg("publish maintain event")
+	b.log().Debug().Interface("event", event).Msg("publish maintain event")
 
-	b.bQueue <- event
+	select {
+	case b.bQueue <- event:
+	case <-b.stop:
+	}
+}
+
+// publishLocalEvent publishes the event from the maintainer goroutine, it is handled before the events in the queue
+func (b *BrainLocal) publishLocalEvent(event maintainEvent) {
+	if b.getState() == core.BrainStateShutdown || b.backlog == nil {
+		return
+	}
+	b.log().Debug().Interface("event", event).Msg("publish local maintain event")
+
+	b.backlog.events = append(b.backlog.events, event)
+}
+
+// SignalQueueDepth get the number of events waiting in the brain event queue, see WithSignalBufferSize
+func (b *BrainLocal) SignalQueueDepth() int {
+	return len(b.bQueue)
 }
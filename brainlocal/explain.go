@@ -0,0 +1,157 @@
+package brainlocal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/topology"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// WhyNotFired explains why the neuron has not fired since the brain is reset, e.g.
+// "trigger group g1 needs links [A B], arrived [A]; upstream neuron X selected cast group C, which does not cast link B".
+// It correlates the trigger groups of the neuron and the signals arrived, with the cast groups selected by the upstream neurons.
+func (b *BrainLocal) WhyNotFired(neuronID string) string {
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	neu, ok := b.neurons[neuronID]
+	if !ok {
+		return fmt.Sprintf("neuron %s not found", neuronID)
+	}
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	b.mu.Lock()
+	run, aborted, ended := b.run, b.aborted, b.ended
+	reached := utils.SlicesContains(b.reachedEnds, []string{neuronID})
+	b.mu.Unlock()
+
+	switch {
+	case reached:
+		return fmt.Sprintf("neuron %s fired, the End neuron is reached", neuronID)
+	case neu.status.state == core.NeuronStateActivated:
+		return fmt.Sprintf("neuron %s is processing", neuronID)
+	case neu.status.count.process > 0:
+		return fmt.Sprintf("neuron %s fired %d times", neuronID, neu.status.count.process)
+	case b.subsystems.isDisabled(neuronID):
+		return fmt.Sprintf("neuron %s is disabled by subsystem %s", neuronID, b.subsystems.of[neuronID])
+	}
+
+	reasons := make([]string, 0)
+	if ended {
+		reasons = append(reasons, "the run is ended by a selector")
+	} else if aborted {
+		reasons = append(reasons, "the run is aborted")
+	}
+	arrived := make([]string, 0)
+	for _, l := range neu.inLinks() {
+		if l.hasPendingSignal(run) {
+			arrived = append(arrived, l.id)
+		}
+	}
+	if missing := b.missingMemory(neu); len(missing) != 0 {
+		reasons = append(reasons, fmt.Sprintf("required memory %v is not set", missing))
+	}
+	switch {
+	case len(neu.spec.triggerGroups) == 0:
+		reasons = append(reasons, "no in-link")
+	case neu.spec.triggerEvaluator != nil:
+		reasons = append(reasons, fmt.Sprintf("trigger evaluator not satisfied, arrived %v", arrived))
+	default:
+		keys := make([]string, 0, len(neu.spec.triggerGroups))
+		for key := range neu.spec.triggerGroups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			need, got := make([]string, 0), make([]string, 0)
+			for _, l := range requiredLinks(neu.spec.triggerGroups[key]) {
+				need = append(need, l.id)
+				if l.hasPendingSignal(run) {
+					got = append(got, l.id)
+				}
+			}
+			reasons = append(reasons, fmt.Sprintf("trigger group %s needs links %v, arrived %v", key, need, got))
+		}
+	}
+	for _, l := range neu.inLinks() {
+		if !l.hasPendingSignal(run) {
+			reasons = append(reasons, b.whyNotCast(l))
+		}
+	}
+
+	return fmt.Sprintf("neuron %s did not fire: %s", neuronID, strings.Join(reasons, "; "))
+}
+
+// whyNotCast explains why the link has no pending signal, should be called with statusMu locked
+func (b *BrainLocal) whyNotCast(l *link) string {
+	if l.isEntryLink() {
+		return fmt.Sprintf("entry link %s is not triggered", l.id)
+	}
+	from, ok := b.neurons[l.spec.from]
+	if !ok {
+		return fmt.Sprintf("upstream neuron %s of link %s not found", l.spec.from, l.id)
+	}
+	switch {
+	case from.status.state == core.NeuronStateActivated:
+		return fmt.Sprintf("upstream neuron %s of link %s is processing", from.id, l.id)
+	case from.status.count.process == 0:
+		return fmt.Sprintf("upstream neuron %s of link %s did not fire", from.id, l.id)
+	case from.status.castGroup == "" && from.status.count.failed > 0:
+		return fmt.Sprintf("upstream neuron %s of link %s failed", from.id, l.id)
+	case from.status.castGroup == "":
+		return fmt.Sprintf("upstream neuron %s of link %s did not cast", from.id, l.id)
+	case from.status.castGroup == processor.SelectEnd:
+		return fmt.Sprintf("upstream neuron %s ended the run, link %s is not cast", from.id, l.id)
+	}
+	for _, cast := range from.spec.castGroups[from.status.castGroup] {
+		if cast.id == l.id {
+			return fmt.Sprintf("upstream neuron %s cast link %s, but its signal is consumed", from.id, l.id)
+		}
+	}
+
+	return fmt.Sprintf("upstream neuron %s selected cast group %s, which does not cast link %s",
+		from.id, from.status.castGroup, l.id)
+}
+
+// RoutingReport returns the routing of every neuron but the End neurons, sorted by neuron ID:
+// the out-links of its cast groups, the kind of its selector and the groups the selector may select.
+func (b *BrainLocal) RoutingReport() []core.NeuronRouting {
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	report := make([]core.NeuronRouting, 0, len(b.neurons))
+	for id, neu := range b.neurons {
+		if core.IsEndNeuronID(id) {
+			continue
+		}
+		routing := core.NeuronRouting{
+			NeuronID:     id,
+			SelectorKind: processor.KindOf(neu.spec.selector),
+			CastGroups:   make(map[string][]string, len(neu.spec.castGroups)),
+			Aliases:      utils.LabelsDeepCopy(neu.spec.groupAliases),
+		}
+		for name, links := range neu.spec.castGroups {
+			linkIDs := make([]string, 0, len(links))
+			for _, l := range links {
+				linkIDs = append(linkIDs, l.id)
+			}
+			sort.Strings(linkIDs)
+			routing.CastGroups[name] = linkIDs
+		}
+		if possible, ok := topology.PossibleGroups(neu.spec.selector, neu.spec.selectorBound, neu.castGroupSizes()); ok {
+			routing.PossibleGroups = make([]string, 0)
+			for _, group := range possible {
+				if !utils.SlicesContains(routing.PossibleGroups, []string{group}) {
+					routing.PossibleGroups = append(routing.PossibleGroups, group)
+				}
+			}
+			sort.Strings(routing.PossibleGroups)
+		}
+		report = append(report, routing)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].NeuronID < report[j].NeuronID })
+
+	return report
+}
@@ -0,0 +1,69 @@
+package brainlocal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// runID identifies the run across the runs of the brain
+func (b *BrainLocal) runID(run uint64) string {
+	return fmt.Sprintf("%s/%d", b.id, run)
+}
+
+// recordEntryMemory applies the memory change by the caller to the initial memory of the next run, see core.Hooks.OnRunStart.
+// Changes by neurons, and by the caller during a run, are not recorded.
+func (b *BrainLocal) recordEntryMemory(neuronID string, change func(memory map[any]any)) {
+	if neuronID != "" || b.hooks.OnRunStart == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == core.BrainStateRunning {
+		return
+	}
+	if b.entryMemory == nil {
+		b.entryMemory = make(map[any]any)
+	}
+	change(b.entryMemory)
+}
+
+// notifyRunStart calls the OnRunStart hook of the run just started, before its links are triggered
+func (b *BrainLocal) notifyRunStart() {
+	b.mu.Lock()
+	run, initialMemory := b.run, b.entryMemory
+	b.entryMemory = nil
+	b.mu.Unlock()
+	if b.hooks.OnRunStart == nil {
+		return
+	}
+	if initialMemory == nil {
+		initialMemory = make(map[any]any)
+	}
+
+	b.hooks.OnRunStart(b.runID(run), initialMemory)
+}
+
+// notifyRunEnd calls the OnRunEnd hook once for the current run, if it is started and not notified yet
+func (b *BrainLocal) notifyRunEnd() {
+	b.mu.Lock()
+	if !b.runEndPending {
+		b.mu.Unlock()
+		return
+	}
+	b.runEndPending = false
+	run, err, duration := b.run, b.runErr, time.Since(b.runStartedAt)
+	result := core.RunResult{
+		ReachedEnds: make([]string, len(b.reachedEnds)),
+		Trace:       make(core.Trace, len(b.runTrace)),
+	}
+	copy(result.ReachedEnds, b.reachedEnds)
+	copy(result.Trace, b.runTrace)
+	b.mu.Unlock()
+	if b.hooks.OnRunEnd == nil {
+		return
+	}
+
+	b.hooks.OnRunEnd(b.runID(run), result, err, duration)
+}
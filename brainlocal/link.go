@@ -1,7 +1,10 @@
 package brainlocal
 
 import (
+	"time"
+
 	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
 )
 
 type link struct {
@@ -15,6 +18,10 @@ type linkSpec struct {
 	from string
 	// to neuron ID
 	to string
+	// optional link does not take part in the trigger groups of the to neuron
+	optional bool
+	// outcome of the from neuron process the link is cast on, empty for any, see core.Link.OnlyOnOutcome
+	outcome processor.Outcome
 }
 
 type linkStatus struct {
@@ -24,14 +31,33 @@ type linkStatus struct {
 		succeed int
 		failed int
 	}
+	signal linkSignal
+}
+
+// linkSignal numbers the signals of the link in a run, a signal is delivered when the link is set ready,
+// and consumed when the destination neuron is activated by it
+type linkSignal struct {
+	run uint64
+	// sequence of the last signal delivered in the run
+	delivered int
+	// sequence of the last signal consumed in the run
+	consumed int
+	// arrival time of the last signal delivered
+	arrivedAt time.Time
+	// payload of the last signal delivered, set for the cast group by the source neuron, see BrainContext.SetCastPayload
+	payload interface{}
+	// writes of the memory keys with merge resolvers along the branch of the signal
+	writes branchWrites
 }
 
 func newLink(l core.Link) *link {
 	return &link{
 		id: l.GetID(),
 		spec: linkSpec{
-			from: l.GetSrcNeuronID(),
-			to:   l.GetDestNeuronID(),
+			from:     l.GetSrcNeuronID(),
+			to:       l.GetDestNeuronID(),
+			optional: l.IsOptional(),
+			outcome:  l.GetOutcome(),
 		},
 		status: linkStatus{
 			state: core.LinkStateInit,
@@ -46,3 +72,47 @@ func (l *link) isEntryLink() bool {
 
 	return false
 }
+
+// deliverSignal sets the link ready with a new signal of the run carrying the payload and the branch writes,
+// should be called with statusMu locked
+func (l *link) deliverSignal(run uint64, payload interface{}, writes branchWrites) {
+	l.status.state = core.LinkStateReady
+	l.resetSignal(run)
+	l.status.signal.delivered++
+	l.status.signal.arrivedAt = time.Now()
+	l.status.signal.payload = payload
+	l.status.signal.writes = writes
+}
+
+// consumeSignal consumes the last signal of the run, should be called with statusMu locked.
+// It returns false if the signal is consumed already, which is a double delivery.
+func (l *link) consumeSignal(run uint64) bool {
+	l.resetSignal(run)
+	if l.status.signal.consumed >= l.status.signal.delivered {
+		return false
+	}
+	l.status.signal.consumed = l.status.signal.delivered
+
+	return true
+}
+
+// hasPendingSignal returns true if a signal delivered in the run is not consumed yet, should be called with statusMu locked.
+// Unlike the ready state, it is kept when the brain sleeps.
+func (l *link) hasPendingSignal(run uint64) bool {
+	return l.status.signal.run == run && l.status.signal.delivered > l.status.signal.consumed
+}
+
+// signalCount returns the number of signals delivered in the run, should be called with statusMu locked
+func (l *link) signalCount(run uint64) int {
+	if l.status.signal.run != run {
+		return 0
+	}
+
+	return l.status.signal.delivered
+}
+
+func (l *link) resetSignal(run uint64) {
+	if l.status.signal.run != run {
+		l.status.signal = linkSignal{run: run}
+	}
+}
@@ -1,35 +1,48 @@
 package brainlocal
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Rovanta/rmodel/core"
 	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/internal/utils"
 	"github.com/Rovanta/rmodel/processor"
 )
 
 func (b *BrainLocal) ensureMaintainerStart() {
 	if b.getState() == core.BrainStateShutdown {
 		b.maintainerStart()
-		b.setState(core.BrainStateSleeping)
+		// only the maintainer start leaves Shutdown, see setState
+		b.mu.Lock()
+		b.state = core.BrainStateSleeping
+		b.cond.Broadcast()
+		b.mu.Unlock()
 	}
 
 	return
 }
 
 func (b *BrainLocal) maintainerStart() {
-	b.logger.Info().
+	b.log().Info().
 		Int("neuronWorkerNum", b.nWorkerNum).
 		Int("neuronQueueLen", b.nQueueLen).
+		Int("signalBufferSize", b.bQueueLen).
 		Msg("brain maintainer start")
 	if b.getState() != core.BrainStateShutdown {
 		b.Shutdown()
 	}
 
 	// new
-	b.nQueue = make(chan string, b.nQueueLen)
-	b.bQueue = make(chan maintainEvent, bQueueLen)
+	b.nQueue = make(chan activation, b.nQueueLen)
+	b.reclaim = make(chan struct{})
+	b.bQueue = make(chan maintainEvent, b.bQueueLen)
+	b.backlog = &maintainerBacklog{}
+	b.stop = make(chan struct{})
 
 	for i := 0; i < b.nWorkerNum; i++ {
 		go b.runNeuronWorker()
@@ -38,33 +51,75 @@ func (b *BrainLocal) maintainerStart() {
 
 }
 
+// maintainerBacklog keeps the events and activations published by the maintainer.
+// The maintainer never blocks on the queues, otherwise a full queue would deadlock it with the publishers waiting for it.
+type maintainerBacklog struct {
+	events      []maintainEvent
+	activations []activation
+}
+
 func (b *BrainLocal) runBrainMaintainer() {
-	for msg := range b.bQueue {
-		b.maintain(msg)
+	queue, stop, backlog := b.bQueue, b.stop, b.backlog
+	for {
+		// events of the maintainer are handled first
+		if len(backlog.events) != 0 {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			msg := backlog.events[0]
+			backlog.events = backlog.events[1:]
+			b.maintain(msg)
+			continue
+		}
+		// the pending activation is sent once the neuron queue has room, events are handled meanwhile
+		var nQueue chan activation
+		var next activation
+		if len(backlog.activations) != 0 {
+			nQueue, next = b.nQueue, backlog.activations[0]
+		}
+		select {
+		case msg := <-queue:
+			b.maintain(msg)
+		case nQueue <- next:
+			backlog.activations = backlog.activations[1:]
+		case <-stop:
+			return
+		}
 	}
 }
 
 func (b *BrainLocal) maintain(event maintainEvent) {
-	b.logger.Debug().Interface("event", event).Msg("got a maintain event")
+	b.log().Debug().Interface("event", event).Msg("got a maintain event")
 
 	switch event.kind {
 	case eventKindLink:
 		if err := b.handleLinkEvent(event.action, event.id); err != nil {
-			b.logger.Error().Err(err).Msg("handle link event error")
+			b.log().Error().Err(err).Msg("handle link event error")
 			return
 		}
 	case eventKindNeuron:
 		if err := b.handleNeuronEvent(event.action, event.id); err != nil {
-			b.logger.Error().Err(err).Msg("handle neuron event error")
+			b.log().Error().Err(err).Msg("handle neuron event error")
 			return
 		}
 	case eventKindBrain:
 		if err := b.handleBrainEvent(event.action); err != nil {
-			b.logger.Error().Err(err).Msg("handle brain event error")
+			b.log().Error().Err(err).Msg("handle brain event error")
+			return
+		}
+	case eventKindMemory:
+		// the run of the session is over, the memory is left to the next run
+		if b.getState() != core.BrainStateRunning {
+			return
+		}
+		if err := b.handleMemoryEvent(event.action, event.id); err != nil {
+			b.log().Error().Err(err).Msg("handle memory event error")
 			return
 		}
 	default:
-		b.logger.Error().Msg("unknown maintain event kind")
+		b.log().Error().Msg("unknown maintain event kind")
 		return
 	}
 
@@ -76,7 +131,7 @@ func (b *BrainLocal) maintain(event maintainEvent) {
 }
 
 func (b *BrainLocal) handleLinkEvent(action eventAction, linkID string) error {
-	l, ok := b.links[linkID]
+	l, ok := b.getLink(linkID)
 	if !ok {
 		return errors.ErrLinkNotFound(linkID)
 	}
@@ -87,14 +142,13 @@ func (b *BrainLocal) handleLinkEvent(action eventAction, linkID string) error {
 	case eventActionLinkWait:
 		// do nothing
 	case eventActionLinkReady:
-		dest, ok := b.neurons[l.spec.to]
+		dest, ok := b.getNeuron(l.spec.to)
 		if !ok {
-			b.logger.Debug().Any("link", b.neurons)
 			return errors.ErrNeuronNotFound(l.spec.to)
 		}
 
 		// try dest neuron activate
-		b.publishEvent(maintainEvent{
+		b.publishLocalEvent(maintainEvent{
 			kind:   eventKindNeuron,
 			action: eventActionNeuronTryActivate,
 			id:     dest.id,
@@ -108,7 +162,7 @@ func (b *BrainLocal) handleLinkEvent(action eventAction, linkID string) error {
 }
 
 func (b *BrainLocal) handleNeuronEvent(action eventAction, neuronID string) error {
-	n, ok := b.neurons[neuronID]
+	n, ok := b.getNeuron(neuronID)
 	if !ok {
 		return errors.ErrNeuronNotFound(neuronID)
 	}
@@ -123,6 +177,8 @@ func (b *BrainLocal) handleNeuronEvent(action eventAction, neuronID string) erro
 		return b.neuronCast(n, false)
 	case eventActionNeuronCastAnyway:
 		return b.neuronCast(n, true)
+	case eventActionNeuronTimeout:
+		return b.fireOnTriggerTimeout(n)
 	default:
 		return fmt.Errorf("unsupported neuron action: %s", action)
 	}
@@ -144,98 +200,160 @@ func (b *BrainLocal) handleBrainEvent(action eventAction) error {
 }
 
 func (b *BrainLocal) tryActivateNeuron(n *neuron) error {
-	if n.status.state == core.NeuronStateActivated {
-		b.logger.Debug().Str("neuronID", n.id).Msg("neuron already activated")
-		return nil
+	b.statusMu.Lock()
+	activated := n.status.state == core.NeuronStateActivated
+	should := false
+	if !activated {
+		var triggeredBy string
+		if triggeredBy, should = b.ifNeuronShouldActivate(n); should {
+			n.status.triggeredBy = triggeredBy
+			n.status.timedOut = false
+			b.stopTriggerTimeout(n)
+			// the neuron is in-flight once queued, so it is never queued twice by the other in-links
+			if !core.IsEndNeuronID(n.id) {
+				n.status.state = core.NeuronStateActivated
+			}
+		} else {
+			b.startTriggerTimeout(n)
+		}
 	}
+	b.statusMu.Unlock()
 
-	should := b.ifNeuronShouldActivate(n)
+	if activated {
+		b.log().Debug().Str("neuronID", n.id).Msg("neuron already activated")
+		return nil
+	}
 	if !should {
-		b.logger.Debug().Str("neuronID", n.id).Msg("neuron should not be activated")
+		b.log().Debug().Str("neuronID", n.id).Msg("neuron should not be activated")
 		return nil
 	}
 
 	// should END, send brain sleep message
-	if n.id == core.EndNeuronID {
-		b.logger.Info().Msg("arrival at END neuron")
-		b.publishEvent(maintainEvent{
+	if core.IsEndNeuronID(n.id) {
+		b.log().Info().Str("neuronID", n.id).Msg("arrival at END neuron")
+		b.addReachedEnd(n.id)
+		b.publishLocalEvent(maintainEvent{
 			kind:   eventKindBrain,
 			action: eventActionBrainSleep,
 		})
 		return nil
 	}
 
-	b.publishEventActivateNeuron(n.id)
+	b.publishEventActivateNeuron(n.id, b.getRun())
 
 	return nil
 }
 
 func (b *BrainLocal) neuronCast(n *neuron, isCastAnyway bool) error {
-	if !isCastAnyway && n.status.state != core.NeuronStateInactive {
-		b.logger.Debug().
+	if b.isAborted() {
+		b.log().Debug().
+			Str("neuronID", n.id).
+			Msg("run aborted, should not cast")
+		return nil
+	}
+
+	b.statusMu.Lock()
+	inactive := n.status.state == core.NeuronStateInactive
+	b.statusMu.Unlock()
+	if !isCastAnyway && !inactive {
+		b.log().Debug().
 			Str("neuronID", n.id).
 			Msg("neuron already active, should not cast")
 		return nil
 	}
 
-	b.logger.Debug().
+	selector, overridden := b.selectorOf(n)
+	b.log().Debug().
 		Str("neuronID", n.id).
+		Str("selectorKind", processor.KindOf(selector)).
 		Msg("neuron try to cast")
 
+	b.statusMu.Lock()
+	triggeredBy := n.status.triggeredBy
+	errorGroup := n.status.errorGroup
+	noOpGroup, noOp := n.noOpGroup()
+	noOp = noOp && !overridden
+	b.statusMu.Unlock()
+
 	var selectedGroup string
-	if n.spec.selector != nil {
-		selectedGroup = n.spec.selector.Select(&brainContext{
+	if errorGroup != "" {
+		// the failure of the process is routed by the selector
+		selectedGroup = errorGroup
+	} else if noOp {
+		// nothing to select
+		selectedGroup = noOpGroup
+		b.incNeuronCounter(n, core.MetricSelectorChoicesTotal, map[string]string{
+			core.MetricLabelNeuron: n.id,
+			core.MetricLabelGroup:  selectedGroup,
+		})
+	} else if selector != nil {
+		selectedGroup = selector.Select(&brainContext{
 			b:               b,
+			run:             b.getRun(),
 			currentNeuronID: n.id,
+			triggeredBy:     triggeredBy,
+		})
+		b.incNeuronCounter(n, core.MetricSelectorChoicesTotal, map[string]string{
+			core.MetricLabelNeuron: n.id,
+			core.MetricLabelGroup:  selectedGroup,
 		})
 	} else {
 		selectedGroup = processor.DefaultCastGroupName
 	}
+	selectedGroup = b.checkSelectedGroup(n, selectedGroup)
 
-	selectedLinks := make(map[string]struct{})
+	if selectedGroup == processor.SelectEnd {
+		b.log().Info().Str("neuronID", n.id).Msg("selector ends the run")
+		b.endRun()
+	}
+	if b.isEnded() {
+		if isCastAnyway {
+			return nil
+		}
+		// the run is ended, the waiting out-links are reset without cast
+		selectedGroup = processor.SelectEnd
+	}
 
-	for _, l := range n.spec.castGroups[selectedGroup] {
+	run := b.getRun()
+	selectedLinks := make(map[string]struct{})
+	// events are published after status unlocked
+	readyLinks := make([]string, 0)
+	castAgain := false
+
+	b.statusMu.Lock()
+	if _, ok := n.spec.castGroups[selectedGroup]; !ok {
+		if group, isAlias := n.spec.groupAliases[selectedGroup]; isAlias {
+			selectedGroup = group
+		}
+	}
+	n.status.castGroup = selectedGroup
+	for _, l := range n.castLinks(selectedGroup) {
 		selectedLinks[l.id] = struct{}{}
 
 		switch l.status.state {
 		case core.LinkStateWait:
-			l.status.state = core.LinkStateReady
-			b.publishEvent(maintainEvent{
-				kind:   eventKindLink,
-				action: eventActionLinkReady,
-				id:     l.id,
-			})
+			l.deliverSignal(run, n.status.payloads[selectedGroup], n.status.writes.clone())
+			readyLinks = append(readyLinks, l.id)
 
 		case core.LinkStateInit:
 			if !isCastAnyway {
-				b.logger.Debug().
+				b.log().Debug().
 					Str("neuronID", n.id).
 					Str("link", l.id).
 					Msg("link on init state, will not cast")
 			} else {
-				l.status.state = core.LinkStateReady
-				b.publishEvent(maintainEvent{
-					kind:   eventKindLink,
-					action: eventActionLinkReady,
-					id:     l.id,
-				})
+				l.deliverSignal(run, n.status.payloads[selectedGroup], n.status.writes.clone())
+				readyLinks = append(readyLinks, l.id)
 			}
 
 		case core.LinkStateReady:
 			if !isCastAnyway {
-				b.logger.Debug().
+				b.log().Debug().
 					Str("neuronID", n.id).
 					Str("link", l.id).
 					Msg("link already cast, will not cast again")
 			} else {
-				go func() {
-					time.Sleep(500 * time.Millisecond)
-					b.publishEvent(maintainEvent{
-						kind:   eventKindNeuron,
-						action: eventActionNeuronCastAnyway,
-						id:     n.id,
-					})
-				}()
+				castAgain = true
 			}
 		}
 
@@ -257,17 +375,103 @@ func (b *BrainLocal) neuronCast(n *neuron, isCastAnyway bool) error {
 
 		}
 	}
+	b.statusMu.Unlock()
+
+	for _, linkID := range readyLinks {
+		b.publishLocalEvent(maintainEvent{
+			kind:   eventKindLink,
+			action: eventActionLinkReady,
+			id:     linkID,
+		})
+	}
+	if castAgain {
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			b.publishEvent(maintainEvent{
+				kind:   eventKindNeuron,
+				action: eventActionNeuronCastAnyway,
+				id:     n.id,
+			})
+		}()
+	}
 
 	return nil
 }
 
-func (b *BrainLocal) ifNeuronShouldActivate(neu *neuron) bool {
+// checkSelectedGroup returns the group to cast for the group selected by the neuron. A group the neuron does not have
+// is handled by the unknown group policy of the brain, empty to cast no link.
+func (b *BrainLocal) checkSelectedGroup(n *neuron, group string) string {
+	b.statusMu.Lock()
+	known := n.hasCastGroup(group)
+	hasDefault := len(n.spec.castGroups[processor.DefaultCastGroupName]) != 0
+	b.statusMu.Unlock()
+	if known {
+		return group
+	}
+
+	b.log().Warn().
+		Str("neuronID", n.id).
+		Str("castGroup", group).
+		Msg("selector returns an unknown cast group")
+	switch b.unknownGroupPolicy {
+	case core.UnknownGroupFallbackToDefault:
+		if hasDefault {
+			return processor.DefaultCastGroupName
+		}
+	case core.UnknownGroupTerminate:
+		return processor.SelectEnd
+	}
+	b.setRunErr(errors.ErrUnknownCastGroup(group, n.id))
+
+	return ""
+}
+
+// incNeuronCounter increases the counter of the neuron if metrics enabled, labeled with the labels of the run and
+// the metric tags of the neuron
+func (b *BrainLocal) incNeuronCounter(n *neuron, name string, labels map[string]string) {
+	if b.metrics == nil {
+		return
+	}
+	b.mu.Lock()
+	for key, value := range b.runLabels {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
+		}
+	}
+	b.mu.Unlock()
+	for key, value := range n.spec.metricTags {
+		labels[key] = value
+	}
+	b.metrics.IncCounter(name, labels)
+}
+
+// ifNeuronShouldActivate should be called with statusMu locked, returns the key of the satisfied trigger group.
+// When more than one trigger group is satisfied, the smallest key is returned.
+// A neuron with a trigger evaluator is activated by the evaluator with processor.TriggerEvaluatorGroupKey instead.
+func (b *BrainLocal) ifNeuronShouldActivate(neu *neuron) (string, bool) {
 	state := b.getState()
-	if state == core.BrainStateSleeping || state == core.BrainStateShutdown {
-		return false
+	if state == core.BrainStateSleeping || state == core.BrainStateShutdown || b.isAborted() || b.isEnded() {
+		return "", false
+	}
+	if b.subsystems.isDisabled(neu.id) {
+		return "", false
+	}
+	// the neuron waits for its required memories, see core.WithRequiredMemory
+	if len(b.missingMemory(neu)) != 0 {
+		return "", false
 	}
 
-	for _, links := range neu.spec.triggerGroups {
+	if neu.spec.triggerEvaluator != nil {
+		arrived := neu.arrivedSignals()
+		if len(arrived) != 0 && neu.spec.triggerEvaluator.IsSatisfied(arrived) {
+			return processor.TriggerEvaluatorGroupKey, true
+		}
+		return "", false
+	}
+
+	for _, key := range neu.triggerGroupKeys() {
+		// optional links are excluded, a group of only optional links is never satisfied
+		links := requiredLinks(neu.spec.triggerGroups[key])
 		trigLinks := make([]*link, 0)
 		for _, l := range links {
 			if l.status.state == core.LinkStateReady {
@@ -277,36 +481,169 @@ func (b *BrainLocal) ifNeuronShouldActivate(neu *neuron) bool {
 			}
 		}
 		if len(links) != 0 && len(trigLinks) == len(links) {
-			return true
+			return key, true
 		}
 	}
 
-	return false
+	return "", false
 }
 
 func (b *BrainLocal) refreshState() {
+	b.statusMu.Lock()
 	inactiveCnt, activateCnt := b.getNeuronCountByState()
 	initCnt, waitCnt, readyCnt := b.getLinkCountByState()
+	var stuck, resumable []string
+	if activateCnt+waitCnt == 0 && readyCnt > 0 {
+		resumable = b.findResumableNeurons()
+		if b.deadlockDetection && len(resumable) == 0 {
+			stuck = b.findStuckNeurons()
+		}
+	}
+	b.statusMu.Unlock()
 
-	b.logger.Debug().
+	b.log().Debug().
 		Int("neuronInactive", inactiveCnt).
 		Int("neuronActivated", activateCnt).
 		Int("linkInit", initCnt).
 		Int("linkWait", waitCnt).
 		Int("linkReady", readyCnt).
 		Msg("refresh brain state by count")
-	// send brain sleep message
-	if activateCnt+waitCnt+readyCnt == 0 {
-		b.publishEvent(maintainEvent{
+	// the neurons waiting for their required memories are triggered already, they resume once the memories are set
+	if len(resumable) != 0 {
+		for _, neuronID := range resumable {
+			b.publishLocalEvent(maintainEvent{
+				kind:   eventKindNeuron,
+				action: eventActionNeuronTryActivate,
+				id:     neuronID,
+			})
+		}
+		return
+	}
+	if len(stuck) != 0 {
+		err := errors.ErrDeadlock(stuck)
+		b.setRunErr(err)
+		b.log().Error().Err(err).Msg("brain deadlock")
+		b.publishLocalEvent(maintainEvent{
+			kind:   eventKindBrain,
+			action: eventActionBrainSleep,
+			id:     b.id,
+		})
+		return
+	}
+	// send brain sleep message, the ready links of an ended run never activate their neurons
+	if activateCnt+waitCnt == 0 && (readyCnt == 0 || b.isEnded()) {
+		b.publishLocalEvent(maintainEvent{
 			kind:   eventKindBrain,
 			action: eventActionBrainSleep,
 			id:     b.id,
 		})
 	} else { // > 0, set to running
 		b.setState(core.BrainStateRunning)
+		// nothing but the ready links left, the run pauses until the neurons get their required memories
+		if activateCnt+waitCnt == 0 && len(b.backlog.events) == 0 {
+			b.pause()
+		}
 	}
 }
 
+// findStuckNeurons should be called with statusMu locked, when no neuron is activated and no link is waiting.
+// It returns the neurons which have ready in-links but no satisfied trigger group, with their unsatisfied trigger groups.
+// Nil is returned if any neuron can be activated.
+func (b *BrainLocal) findStuckNeurons() []string {
+	stuck := make([]string, 0)
+	for _, neu := range b.neurons {
+		if b.subsystems.isDisabled(neu.id) {
+			continue
+		}
+		// the neuron is fired once its trigger timeout is up
+		if !neu.status.timeoutStart.IsZero() {
+			return nil
+		}
+		if neu.spec.triggerEvaluator != nil {
+			arrived := neu.arrivedSignals()
+			if len(arrived) == 0 {
+				continue
+			}
+			if neu.spec.triggerEvaluator.IsSatisfied(arrived) {
+				// the neuron can be activated
+				return nil
+			}
+			linkIDs := make([]string, 0, len(arrived))
+			for id := range arrived {
+				linkIDs = append(linkIDs, id)
+			}
+			sort.Strings(linkIDs)
+			stuck = append(stuck, fmt.Sprintf("neuron %s: trigger evaluator is not satisfied by links %v", neu.id, linkIDs))
+			continue
+		}
+		hasReady := false
+		unsatisfied := make([]string, 0)
+		for key, group := range neu.spec.triggerGroups {
+			links := requiredLinks(group)
+			missing := make([]string, 0)
+			for _, l := range links {
+				if l.status.state == core.LinkStateReady {
+					hasReady = true
+				} else {
+					missing = append(missing, l.id)
+				}
+			}
+			if len(missing) == 0 && len(links) != 0 {
+				// the neuron can be activated
+				return nil
+			}
+			sort.Strings(missing)
+			unsatisfied = append(unsatisfied, fmt.Sprintf("trigger group %s waits for links %v", key, missing))
+		}
+		if hasReady {
+			sort.Strings(unsatisfied)
+			stuck = append(stuck, fmt.Sprintf("neuron %s: %s", neu.id, strings.Join(unsatisfied, ", ")))
+		}
+	}
+	sort.Strings(stuck)
+
+	return stuck
+}
+
+// setCastPayload sets the payload delivered along the links of the cast group, when the neuron casts the group.
+// The group may be an alias of the neuron.
+func (b *BrainLocal) setCastPayload(neuronID, group string, payload interface{}) error {
+	neu, ok := b.getNeuron(neuronID)
+	if !ok {
+		return errors.ErrNeuronNotFound(neuronID)
+	}
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	if _, ok := neu.spec.castGroups[group]; !ok && group != processor.DefaultCastGroupName {
+		alias, isAlias := neu.spec.groupAliases[group]
+		if !isAlias {
+			return errors.ErrCastGroupNotFound(group, neuronID)
+		}
+		group = alias
+	}
+	if neu.status.payloads == nil {
+		neu.status.payloads = make(map[string]interface{})
+	}
+	neu.status.payloads[group] = payload
+
+	return nil
+}
+
+// visitCount returns the number of activations of the neuron in the run, 0 if it is not activated in the run, see takeVisit
+func (b *BrainLocal) visitCount(neuronID string, run uint64) int {
+	neu, ok := b.getNeuron(neuronID)
+	if !ok {
+		return 0
+	}
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	if neu.status.visitRun != run {
+		return 0
+	}
+
+	return neu.status.visits
+}
+
 func (b *BrainLocal) getNeuronCountByState() (int, int) {
 	var inactiveCnt, activateCnt int
 	for _, neu := range b.neurons {
@@ -330,7 +667,10 @@ func (b *BrainLocal) getLinkCountByState() (int, int, int) {
 		case core.LinkStateWait:
 			waitCnt++
 		case core.LinkStateReady:
-			readyCnt++
+			// the signal to a disabled neuron or along an optional link does not keep the brain running
+			if !l.spec.optional && !b.subsystems.isDisabled(l.spec.to) {
+				readyCnt++
+			}
 		}
 	}
 
@@ -338,17 +678,324 @@ func (b *BrainLocal) getLinkCountByState() (int, int, int) {
 }
 
 func (b *BrainLocal) ForceSleep() {
+	b.statusMu.Lock()
 	for _, l := range b.links {
 		l.status.state = core.LinkStateInit
 	}
 	for _, neu := range b.neurons {
 		neu.status.state = core.NeuronStateInactive
+		b.stopTriggerTimeout(neu)
 	}
+	b.statusMu.Unlock()
+	b.runCompletion()
+	b.runCompensations()
+	b.runDeferred()
+	b.closeStreams()
+	b.notifyRunEnd()
 	b.setState(core.BrainStateSleeping)
 }
 
+// runCompletion runs the completion processor once in a run, if the run reached an End neuron without error.
+// The processor is skipped if the context of the run is done, e.g. cancelled as the run reaches End, with its error as the run error.
+func (b *BrainLocal) runCompletion() {
+	b.mu.Lock()
+	p, run, ctx := b.completion, b.run, b.runCtx
+	// an aborted run has the run error, unless it is ended by a selector
+	should := p != nil && !b.completed && len(b.reachedEnds) != 0 && b.runErr == nil &&
+		b.state == core.BrainStateRunning
+	if should {
+		b.completed = true
+	}
+	b.mu.Unlock()
+	if !should {
+		return
+	}
+	if ctx != nil && ctx.Err() != nil {
+		b.setRunErr(ctx.Err())
+		b.log().Debug().Err(ctx.Err()).Msg("run context done, skip completion processor")
+		return
+	}
+
+	b.log().Debug().Str("processorKind", processor.KindOf(p)).Msg("run completion processor")
+	if err := p.Process(&brainContext{
+		b:               b,
+		currentNeuronID: core.CompletionNeuronID,
+		run:             run,
+	}); err != nil {
+		err = fmt.Errorf("process completion error: %w", err)
+		b.setRunErr(err)
+		b.log().Error().Err(err).Msg("run completion processor error")
+	}
+}
+
+func (b *BrainLocal) addReachedEnd(neuronID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range b.reachedEnds {
+		if id == neuronID {
+			return
+		}
+	}
+	b.reachedEnds = append(b.reachedEnds, neuronID)
+}
+
+// setRunErr records the first neuron process error of the current run
+func (b *BrainLocal) setRunErr(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.runErr == nil {
+		b.runErr = err
+	}
+}
+
+// abortRun records the error as the error of the current run, and stops scheduling the run.
+// Neurons still processing are cancelled, see isRunCancelled.
+func (b *BrainLocal) abortRun(err error) {
+	b.mu.Lock()
+	if b.aborted {
+		b.mu.Unlock()
+		return
+	}
+	b.aborted = true
+	b.runErr = err
+	b.cancelRunContext(core.CancelReasonOfErr(err))
+	b.mu.Unlock()
+
+	b.publishEvent(maintainEvent{
+		kind:   eventKindBrain,
+		action: eventActionBrainSleep,
+		id:     b.id,
+	})
+}
+
+// endRun ends the run at the default End neuron, when a selector returns processor.SelectEnd.
+// The neurons still processing finish without casting, or are cancelled with WithCancelOnSelectEnd.
+func (b *BrainLocal) endRun() {
+	b.addReachedEnd(core.EndNeuronIDOf(""))
+	b.mu.Lock()
+	b.ended = true
+	cancel := b.cancelOnSelectEnd && !b.aborted
+	if cancel {
+		b.aborted = true
+		b.cancelRunContext(core.CancelReasonEarlyExit)
+	}
+	b.mu.Unlock()
+
+	if cancel {
+		b.publishLocalEvent(maintainEvent{
+			kind:   eventKindBrain,
+			action: eventActionBrainSleep,
+			id:     b.id,
+		})
+	}
+}
+
+// selectorOf returns the selector of the neuron in the current run, and whether it is overridden for the run, see Run
+func (b *BrainLocal) selectorOf(n *neuron) (processor.Selector, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if selector, ok := b.runSelectors[n.id]; ok {
+		return selector, true
+	}
+
+	return n.spec.selector, false
+}
+
+func (b *BrainLocal) isEnded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ended
+}
+
+func (b *BrainLocal) isAborted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.aborted
+}
+
+func (b *BrainLocal) getRun() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.run
+}
+
+// isRunCancelled if the run is aborted, or is over and a new run started.
+// The process of a cancelled run can not change the memory, and its result is discarded.
+func (b *BrainLocal) isRunCancelled(run uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.run != run || b.aborted
+}
+
+// takeStep counts the execution of the neuron in the run, returns error if the run reaches the step limit
+func (b *BrainLocal) takeStep(neuronID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxSteps > 0 && b.steps >= b.maxSteps {
+		return errors.ErrStepLimitExceeded(b.steps, b.lastExecuted, neuronID)
+	}
+	b.steps++
+	b.lastExecuted = neuronID
+
+	return nil
+}
+
+// addExecution records the neuron process into the trace of the run, if the run is not over and the trace is retained
+func (b *BrainLocal) addExecution(run uint64, execution core.NeuronExecution) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.run != run {
+		return
+	}
+	if b.resultRetention == core.ResultRetentionFull {
+		b.runTrace = append(b.runTrace, execution)
+	}
+	if b.executed == nil {
+		b.executed = make(map[string]struct{})
+	}
+	b.executed[execution.NeuronID] = struct{}{}
+	b.executedOrder = append(b.executedOrder, execution.NeuronID)
+	// wake the sessions waiting for the neuron
+	b.cond.Broadcast()
+}
+
+// newRunRand new random source of a run, seeded with randSeed if set, or the current time
+func (b *BrainLocal) newRunRand() *rand.Rand {
+	seed := time.Now().UnixNano()
+	if b.randSeed != nil {
+		seed = *b.randSeed
+	}
+
+	return utils.NewLockedRand(seed)
+}
+
+// getRunRand returns the random source of the current run
+func (b *BrainLocal) getRunRand() *rand.Rand {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.runRand == nil {
+		// no run started yet
+		b.runRand = b.newRunRand()
+	}
+
+	return b.runRand
+}
+
+// getRunContext returns the context of the run, which is done if the run is over and a new run started
+func (b *BrainLocal) getRunContext(run uint64) context.Context {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.run != run {
+		return cancelledContext
+	}
+	if b.runCtx == nil {
+		// no run started yet
+		return context.Background()
+	}
+
+	return b.runCtx
+}
+
+// cancelRunContext cancels the context of the current run by the reason, must be called with mu held
+func (b *BrainLocal) cancelRunContext(reason core.CancelReason) {
+	if b.cancelRunCtx != nil {
+		b.cancelRunCtx(reason)
+	}
+}
+
+func (b *BrainLocal) getRunErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.runErr
+}
+
+// resetStatus reset the status and counts of all neurons and links
+func (b *BrainLocal) resetStatus() {
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	for _, l := range b.links {
+		l.status = linkStatus{state: core.LinkStateInit}
+	}
+	for _, neu := range b.neurons {
+		neu.status = neuronStatus{state: core.NeuronStateInactive}
+	}
+}
+
+// startRun sets the brain running, a new run starts with the run status reset if the brain is not running yet.
+// The check and the set are atomic, so concurrent triggers never reset the status recorded by each other.
+// The new run is configured by config if not nil. Returns true if a new run starts.
+func (b *BrainLocal) startRun(config *core.RunConfig) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// the brain is shut down concurrently
+	if b.state == core.BrainStateShutdown {
+		return false
+	}
+	if b.state != core.BrainStateRunning {
+		b.run++
+		b.runStartedAt = time.Now()
+		b.runEndPending = true
+		b.aborted = false
+		b.ended = false
+		b.completed = false
+		b.steps = 0
+		b.lastExecuted = ""
+		b.runRand = b.newRunRand()
+		b.cancelRunContext(core.CancelReasonRunOver)
+		b.runCtx, b.cancelRunCtx = core.WithCancelReason(context.Background())
+		b.reachedEnds = nil
+		b.runErr = nil
+		b.runTrace = nil
+		b.executed = nil
+		b.executedOrder = nil
+		b.compensations = nil
+		b.runSelectors = nil
+		if config != nil {
+			for neuronID, selector := range config.SelectorOverrides {
+				if b.runSelectors == nil {
+					b.runSelectors = make(map[string]processor.Selector, len(config.SelectorOverrides))
+				}
+				b.runSelectors[neuronID] = selector.Clone()
+			}
+		}
+		b.runLabels = nil
+		b.runLogger.Store(nil)
+		if config != nil && len(config.Labels) != 0 {
+			b.runLabels = utils.LabelsDeepCopy(config.Labels)
+			b.runCtx = core.ContextWithRunLabels(b.runCtx, b.runLabels)
+			fields := make(map[string]interface{}, len(b.runLabels))
+			for key, value := range b.runLabels {
+				fields[key] = value
+			}
+			logger := b.logger.With().Fields(fields).Logger()
+			b.runLogger.Store(&logger)
+		}
+		b.state = core.BrainStateRunning
+		b.cond.Broadcast()
+		return true
+	}
+
+	return false
+}
+
+// resetRunStatus reset the status of the last run
+func (b *BrainLocal) resetRunStatus() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reachedEnds = nil
+	b.runErr = nil
+	b.runTrace = nil
+	b.executedOrder = nil
+	b.aborted = false
+}
+
+// setState never leaves Shutdown, events handled by a stopped maintainer may still refresh the state
 func (b *BrainLocal) setState(state core.BrainState) {
 	b.mu.Lock()
+	if b.state == core.BrainStateShutdown {
+		b.mu.Unlock()
+		return
+	}
 	b.state = state
 	b.cond.Broadcast() // Notify all waiting goroutines
 	b.mu.Unlock()
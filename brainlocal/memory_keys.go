@@ -0,0 +1,62 @@
+package brainlocal
+
+import (
+	"fmt"
+	"sync"
+)
+
+// memoryKeys tracks the keys set in the memory, which the memory can not list, see snapshotMemory
+type memoryKeys struct {
+	mu   sync.Mutex
+	keys map[any]struct{}
+}
+
+func (k *memoryKeys) add(key any) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		k.keys = make(map[any]struct{})
+	}
+	k.keys[key] = struct{}{}
+}
+
+func (k *memoryKeys) remove(key any) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.keys, key)
+}
+
+func (k *memoryKeys) clear() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = nil
+}
+
+func (k *memoryKeys) list() []any {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	keys := make([]any, 0, len(k.keys))
+	for key := range k.keys {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// snapshotMemory returns the memories set in the brain, without audit. The keys which are not strings are formatted by fmt.Sprint.
+func (b *BrainLocal) snapshotMemory() map[string]interface{} {
+	snapshot := make(map[string]interface{})
+	for _, key := range b.memoryKeys.list() {
+		v, ok := b.peekMemory(key)
+		if !ok {
+			continue
+		}
+		if k, isString := key.(string); isString {
+			snapshot[k] = v
+		} else {
+			snapshot[fmt.Sprint(key)] = v
+		}
+	}
+
+	return snapshot
+}
@@ -0,0 +1,85 @@
+package brainlocal
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// RunnerPool recycles the brains running a template brain, e.g. one per request of a service, so the memory
+// and the status of the neurons and links are reused instead of built for every run.
+// Each brain of the pool is built like a worker of RunBatch, with the topology of the template when it is built,
+// the options of the template, and its own clones of the processors and selectors.
+type RunnerPool struct {
+	template *BrainLocal
+	idle     chan *BrainLocal
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRunnerPool new pool of the brains running the template, keeping up to GOMAXPROCS idle brains.
+// The idle brains are kept in a bounded list instead of a sync.Pool, a brain dropped by the pool is shut down,
+// so its goroutines never leak.
+func NewRunnerPool(template *BrainLocal) *RunnerPool {
+	return &RunnerPool{
+		template: template,
+		idle:     make(chan *BrainLocal, runtime.GOMAXPROCS(0)),
+	}
+}
+
+// Get returns an idle brain of the pool, reset for a new run, or a new brain if there is none
+func (p *RunnerPool) Get() *BrainLocal {
+	select {
+	case b := <-p.idle:
+		return b
+	default:
+		return p.template.buildWorker()
+	}
+}
+
+// Put resets the brain and returns it to the pool, the brain must be got from the pool and not be used after.
+// A brain still running is shut down once its run is over instead, as is a brain put into a full or closed pool.
+func (p *RunnerPool) Put(b *BrainLocal) {
+	if b.getState() == core.BrainStateShutdown {
+		return
+	}
+	if err := b.Reset(); err != nil {
+		go func() {
+			b.Wait()
+			b.Shutdown()
+		}()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		b.Shutdown()
+		return
+	}
+	select {
+	case p.idle <- b:
+	default:
+		b.Shutdown()
+	}
+}
+
+// Close shuts down the idle brains of the pool, the brains put afterwards are shut down
+func (p *RunnerPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for {
+		select {
+		case b := <-p.idle:
+			b.Shutdown()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package brainlocal
+
+import (
+	"sort"
+
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// resolveAtBuild resolves the processor of the neuron once, see processor.BuildResolver
+func (b *BrainLocal) resolveAtBuild(neu *neuron) error {
+	r, ok := neu.spec.processor.(processor.BuildResolver)
+	if !ok {
+		return nil
+	}
+	if err := r.ResolveAtBuild(); err != nil {
+		return errors.ErrResolveAtBuild(neu.id, err)
+	}
+
+	return nil
+}
+
+// seedRun sets the memories of the processors seeding the runs, see processor.RunSeeder, in order of neuron ID.
+// A failure is the run error.
+func (b *BrainLocal) seedRun() {
+	b.topoMu.RLock()
+	ids := make([]string, 0)
+	seeds := make(map[string][]interface{})
+	for id, neu := range b.neurons {
+		if s, ok := neu.spec.processor.(processor.RunSeeder); ok {
+			if seed := s.RunSeed(); len(seed) != 0 {
+				ids = append(ids, id)
+				seeds[id] = seed
+			}
+		}
+	}
+	b.topoMu.RUnlock()
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err := b.setMemory("", seeds[id]...); err != nil {
+			b.log().Error().Err(err).Str("neuronID", id).Msg("seed run memory failed")
+			b.setRunErr(err)
+			return
+		}
+	}
+}
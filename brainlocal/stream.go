@@ -0,0 +1,40 @@
+package brainlocal
+
+import (
+	"sync"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// memoryStreams tracks the streams set in the memory, including the ones overwritten or deleted since,
+// so they are closed once the run is over, see closeStreams
+type memoryStreams struct {
+	mu      sync.Mutex
+	streams []*processor.Stream
+}
+
+func (s *memoryStreams) add(stream *processor.Stream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams = append(s.streams, stream)
+}
+
+// take returns the streams tracked, and stops tracking them
+func (s *memoryStreams) take() []*processor.Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	streams := s.streams
+	s.streams = nil
+
+	return streams
+}
+
+// closeStreams closes the streams set in the run which is over, whether they are read or not.
+// A stream left in the memory can not be read any more, GetStream fails with processor.ErrStreamClosed.
+func (b *BrainLocal) closeStreams() {
+	for _, stream := range b.streams.take() {
+		if err := stream.Close(); err != nil {
+			b.log().Error().Err(err).Msg("close memory stream error")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package brainlocal
+
+import (
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/internal/topology"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// AddNeuron adds the neuron to the brain, with its processor, selector, labels and cast group aliases.
+// Links of the neuron are not added, connect it by AddLink.
+func (b *BrainLocal) AddNeuron(n core.Neuron) error {
+	return b.editTopology(func(topo *topology.Topology) error {
+		if err := topo.CheckAddNeuron(n.GetID()); err != nil {
+			return err
+		}
+		neu := newNeuronWithoutLinks(n)
+		b.substituteNilProcessor(neu)
+		b.applyDefaultSelector(neu)
+		if err := b.resolveAtBuild(neu); err != nil {
+			return err
+		}
+		b.neurons[neu.id] = neu
+
+		return nil
+	})
+}
+
+// RemoveNeuron removes the neuron, End neurons and neurons with links can not be removed.
+func (b *BrainLocal) RemoveNeuron(neuronID string) error {
+	return b.editTopology(func(topo *topology.Topology) error {
+		if err := topo.CheckRemoveNeuron(neuronID); err != nil {
+			return err
+		}
+		delete(b.neurons, neuronID)
+		b.subsystems.remove(neuronID)
+
+		return nil
+	})
+}
+
+// AddLink adds the link into the default cast group of the source neuron, and a new trigger group of the destination neuron.
+func (b *BrainLocal) AddLink(l core.Link) error {
+	return b.editTopology(func(topo *topology.Topology) error {
+		if err := topo.CheckAddLink(l.GetID(), topology.Link{From: l.GetSrcNeuronID(), To: l.GetDestNeuronID()}); err != nil {
+			return err
+		}
+		if src, ok := b.neurons[l.GetSrcNeuronID()]; ok && src.id == l.GetDestNeuronID() && src.spec.maxRevisits <= 0 {
+			return errors.ErrSelfLink(src.id)
+		}
+		dest, ok := b.neurons[l.GetDestNeuronID()]
+		if !ok {
+			// ensure End neuron
+			dest = &neuron{
+				id:     l.GetDestNeuronID(),
+				labels: make(map[string]string),
+				spec: neuronSpec{
+					processor:     &processor.EmptyProcessor{},
+					triggerGroups: make(map[string][]*link),
+					castGroups:    make(map[string][]*link),
+				},
+				status: neuronStatus{
+					state: core.NeuronStateInactive,
+				},
+			}
+			b.neurons[dest.id] = dest
+		}
+
+		lk := newLink(l)
+		b.links[lk.id] = lk
+		if src, ok := b.neurons[l.GetSrcNeuronID()]; ok {
+			src.spec.castGroups[processor.DefaultCastGroupName] = append(src.spec.castGroups[processor.DefaultCastGroupName], lk)
+		}
+		dest.spec.triggerGroups[utils.GenIDShort()] = []*link{lk}
+
+		return nil
+	})
+}
+
+// RemoveLink removes the link, see RemoveLinks.
+func (b *BrainLocal) RemoveLink(linkID string) error {
+	return b.RemoveLinks(linkID)
+}
+
+// RemoveLinks removes the links from the cast groups of the source neurons and the trigger groups of the destination neurons.
+// Links of a trigger group are removed together, and the trigger group is removed with them,
+// so a join never fires on a part of its links. End neurons can not be made unreachable by the removal.
+func (b *BrainLocal) RemoveLinks(linkIDs ...string) error {
+	return b.editTopology(func(topo *topology.Topology) error {
+		if err := topo.CheckRemoveLinks(linkIDs...); err != nil {
+			return err
+		}
+		for _, linkID := range linkIDs {
+			lk, ok := b.links[linkID]
+			if !ok {
+				// listed twice
+				continue
+			}
+			if src, ok := b.neurons[lk.spec.from]; ok {
+				for name, links := range src.spec.castGroups {
+					src.spec.castGroups[name] = removeLinkFrom(links, lk)
+				}
+			}
+			if dest, ok := b.neurons[lk.spec.to]; ok {
+				for key, links := range dest.spec.triggerGroups {
+					for _, l := range links {
+						if l == lk {
+							delete(dest.spec.triggerGroups, key)
+							break
+						}
+					}
+				}
+			}
+			delete(b.links, linkID)
+		}
+
+		return nil
+	})
+}
+
+// editTopology runs the edit while no run can observe the topology, the edit is rejected if the brain is running.
+// The edit checks itself on the copy of the topology before changing anything.
+func (b *BrainLocal) editTopology(edit func(topo *topology.Topology) error) error {
+	b.topoMu.Lock()
+	defer b.topoMu.Unlock()
+	if b.getState() == core.BrainStateRunning {
+		return errors.ErrBrainRunning(b.id)
+	}
+	// the groups of neurons are read with statusMu locked
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+
+	return edit(b.topology())
+}
+
+// topology should be called with topoMu and statusMu locked
+func (b *BrainLocal) topology() *topology.Topology {
+	topo := topology.New()
+	for id, l := range b.links {
+		topo.Links[id] = topology.Link{From: l.spec.from, To: l.spec.to}
+	}
+	for id, n := range b.neurons {
+		groups := make(map[string][]string, len(n.spec.triggerGroups))
+		for key, links := range n.spec.triggerGroups {
+			ids := make([]string, 0, len(links))
+			for _, l := range links {
+				ids = append(ids, l.id)
+			}
+			groups[key] = ids
+		}
+		topo.TriggerGroups[id] = groups
+	}
+
+	return topo
+}
+
+func (b *BrainLocal) getNeuron(neuronID string) (*neuron, bool) {
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	n, ok := b.neurons[neuronID]
+
+	return n, ok
+}
+
+func (b *BrainLocal) getLink(linkID string) (*link, bool) {
+	b.topoMu.RLock()
+	defer b.topoMu.RUnlock()
+	l, ok := b.links[linkID]
+
+	return l, ok
+}
+
+func removeLinkFrom(links []*link, target *link) []*link {
+	ret := make([]*link, 0, len(links))
+	for _, l := range links {
+		if l != target {
+			ret = append(ret, l)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,69 @@
+package brainlocal
+
+import (
+	"time"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// startTriggerTimeout starts the trigger timeout of the neuron on the first signal arrived, should be called with statusMu locked
+func (b *BrainLocal) startTriggerTimeout(n *neuron) {
+	if n.spec.triggerTimeout <= 0 || !n.status.timeoutStart.IsZero() || len(n.arrivedSignals()) == 0 {
+		return
+	}
+	if b.isAborted() || b.isEnded() {
+		return
+	}
+	neuronID := n.id
+	n.status.timeoutStart = time.Now()
+	n.status.timeoutTimer = time.AfterFunc(n.spec.triggerTimeout, func() {
+		b.publishEvent(maintainEvent{
+			kind:   eventKindNeuron,
+			action: eventActionNeuronTimeout,
+			id:     neuronID,
+		})
+	})
+}
+
+// stopTriggerTimeout stops the pending trigger timeout of the neuron, should be called with statusMu locked
+func (b *BrainLocal) stopTriggerTimeout(n *neuron) {
+	if n.status.timeoutTimer != nil {
+		n.status.timeoutTimer.Stop()
+	}
+	n.status.timeoutTimer = nil
+	n.status.timeoutStart = time.Time{}
+}
+
+// fireOnTriggerTimeout fires the neuron with the signals arrived, if its trigger is still not satisfied
+// when the trigger timeout is up.
+func (b *BrainLocal) fireOnTriggerTimeout(n *neuron) error {
+	b.statusMu.Lock()
+	start := n.status.timeoutStart
+	// the timeout is stopped, or restarted by a signal of the next round
+	if start.IsZero() || time.Since(start) < n.spec.triggerTimeout {
+		b.statusMu.Unlock()
+		return nil
+	}
+	b.stopTriggerTimeout(n)
+	state := b.getState()
+	fire := n.status.state != core.NeuronStateActivated && len(n.arrivedSignals()) != 0 &&
+		state == core.BrainStateRunning && !b.isAborted() && !b.isEnded() &&
+		!b.subsystems.isDisabled(n.id) && len(b.missingMemory(n)) == 0
+	if fire {
+		n.status.triggeredBy = n.spec.timeoutGroup
+		n.status.timedOut = true
+		n.status.state = core.NeuronStateActivated
+	}
+	b.statusMu.Unlock()
+	if !fire {
+		return nil
+	}
+
+	b.log().Debug().
+		Str("neuronID", n.id).
+		Dur("triggerTimeout", n.spec.triggerTimeout).
+		Msg("trigger timeout, fire neuron with partial inputs")
+	b.publishEventActivateNeuron(n.id, b.getRun())
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package rModel
 
 import (
+	"sort"
+
 	"github.com/rs/zerolog"
 	"github.com/Rovanta/rmodel/core"
 	"github.com/Rovanta/rmodel/internal/errors"
@@ -39,6 +41,8 @@ type brainprint struct {
 	neurons map[string]*neuron
 	// map of all link
 	links map[string]*link
+	// sequence of the neurons and links added, the build order of Canonicalize
+	seq int
 }
 
 func (b *brainprint) GetID() string {
@@ -187,7 +191,32 @@ func (b *brainprint) ListOutLinks(neuronID string) []core.Link {
 	return ret
 }
 
+func (b *brainprint) ListCastGroupsResolved(neuronID string) (map[string][]core.Link, error) {
+	n, ok := b.neurons[neuronID]
+	if !ok {
+		return nil, errors.ErrNeuronNotFound(neuronID)
+	}
+	groups := make(map[string][]core.Link, len(n.castGroups))
+	for name, linkIDs := range n.ListCastGroups() {
+		links := make([]core.Link, 0, len(linkIDs))
+		for _, linkID := range linkIDs {
+			l, ok := b.links[linkID]
+			if !ok {
+				return nil, errors.Wrapf(errors.ErrLinkNotFound(linkID), "cast group %s of neuron %s", name, neuronID)
+			}
+			links = append(links, l)
+		}
+		groups[name] = links
+	}
+
+	return groups, nil
+}
+
 func (b *brainprint) AddNeuron(processFn func(bc processor.BrainContext) error, withOpts ...core.NeuronOption) core.Neuron {
+	// a nil process func is a nil processor, rejected by Validate
+	if processFn == nil {
+		return b.addNeuronWithProcessor(nil, withOpts...)
+	}
 	return b.addNeuronWithProcessor(processor.NewFuncProcessor(processFn), withOpts...)
 }
 
@@ -207,10 +236,17 @@ func (b *brainprint) AddLink(from, to core.Neuron, withOpts ...core.LinkOption)
 	if !ok {
 		return nil, errors.ErrNeuronNotFound(from.GetID())
 	}
+	if core.IsEndNeuronID(src.GetID()) {
+		return nil, errors.ErrOutLinkFromEndNeuron(src.GetID())
+	}
 	dest, ok := b.neurons[to.GetID()]
 	if !ok {
 		return nil, errors.ErrNeuronNotFound(to.GetID())
 	}
+	// a loop on the neuron itself should be bounded
+	if src.id == dest.id && src.maxRevisits <= 0 {
+		return nil, errors.ErrSelfLink(src.id)
+	}
 	// new link, and neurons set
 	l := newLink(from.GetID(), to.GetID())
 	src.addOutLink(l.GetID())
@@ -220,6 +256,7 @@ func (b *brainprint) AddLink(from, to core.Neuron, withOpts ...core.LinkOption)
 	for _, opt := range withOpts {
 		opt.Apply(l)
 	}
+	l.seq = b.nextSeq()
 	b.links[l.GetID()] = l
 
 	return l, nil
@@ -239,6 +276,7 @@ func (b *brainprint) AddEntryLinkTo(to core.Neuron, withOpts ...core.LinkOption)
 	for _, opt := range withOpts {
 		opt.Apply(l)
 	}
+	l.seq = b.nextSeq()
 	b.links[l.GetID()] = l
 
 	return l, nil
@@ -251,7 +289,7 @@ func (b *brainprint) AddEndLinkFrom(from core.Neuron, withOpts ...core.LinkOptio
 		return nil, errors.ErrNeuronNotFound(from.GetID())
 	}
 	// ensure END neuron
-	end := b.ensureEndNeuron()
+	end := b.ensureEndNeuron(core.EndNeuronID)
 	// new link, and neurons set
 	l := newEndLink(src.GetID())
 	src.addOutLink(l.GetID())
@@ -261,11 +299,41 @@ func (b *brainprint) AddEndLinkFrom(from core.Neuron, withOpts ...core.LinkOptio
 	for _, opt := range withOpts {
 		opt.Apply(l)
 	}
+	l.seq = b.nextSeq()
 	b.links[l.GetID()] = l
 
 	return l, nil
 }
 
+func (b *brainprint) AddEndNeuron(name string) core.Neuron {
+	return b.ensureEndNeuron(core.EndNeuronIDOf(name))
+}
+
+// DefineGroupAlias defines the cast group alias for neurons, so one selector can be shared among neurons with different group names.
+// key of perNeuron: neuron ID, value: group name of the neuron which the alias refers to.
+// All referenced neurons and groups are validated before any alias is defined.
+func (b *brainprint) DefineGroupAlias(alias string, perNeuron map[string]string) error {
+	neuronIDs := make([]string, 0, len(perNeuron))
+	for neuronID := range perNeuron {
+		neuronIDs = append(neuronIDs, neuronID)
+	}
+	sort.Strings(neuronIDs)
+	for _, neuronID := range neuronIDs {
+		n, ok := b.neurons[neuronID]
+		if !ok {
+			return errors.ErrNeuronNotFound(neuronID)
+		}
+		if err := n.checkCastGroupAlias(alias, perNeuron[neuronID]); err != nil {
+			return err
+		}
+	}
+	for _, neuronID := range neuronIDs {
+		b.neurons[neuronID].setCastGroupAlias(alias, perNeuron[neuronID])
+	}
+
+	return nil
+}
+
 func (b *brainprint) Clone() core.Blueprint {
 	if b == nil {
 		return nil
@@ -275,6 +343,7 @@ func (b *brainprint) Clone() core.Blueprint {
 		labels:  utils.LabelsDeepCopy(b.labels),
 		neurons: make(map[string]*neuron),
 		links:   make(map[string]*link),
+		seq:     b.seq,
 	}
 	for id, n := range b.neurons {
 		cp.neurons[id] = n.deepCopy()
@@ -312,18 +381,27 @@ func (b *brainprint) addNeuronWithProcessor(p processor.Processor, withOpts ...c
 	for _, opt := range withOpts {
 		opt.Apply(n)
 	}
+	n.seq = b.nextSeq()
 	b.neurons[n.GetID()] = n
 
 	return n
 }
 
-func (b *brainprint) ensureEndNeuron() *neuron {
-	n, ok := b.neurons[core.EndNeuronID]
+// nextSeq returns the sequence of the neuron or link added
+func (b *brainprint) nextSeq() int {
+	b.seq++
+
+	return b.seq
+}
+
+func (b *brainprint) ensureEndNeuron(neuronID string) *neuron {
+	n, ok := b.neurons[neuronID]
 	if ok {
 		return n
 	}
 
-	n = newEndNeuron()
+	n = newEndNeuron(neuronID)
+	n.seq = b.nextSeq()
 	b.neurons[n.GetID()] = n
 
 	return n
@@ -0,0 +1,14 @@
+package rModel
+
+import (
+	"context"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// CancelReason returns the reason the context of the run is cancelled, e.g. processor.BrainContext.Context(),
+// core.CancelReasonNone if it is not cancelled. The reason is set before the context is done, so a processor
+// observing ctx.Done() can clean up by the reason.
+func CancelReason(ctx context.Context) core.CancelReason {
+	return core.CancelReasonOf(ctx)
+}
@@ -0,0 +1,138 @@
+package rModel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+const (
+	canonicalNeuronPrefix       = "n"
+	canonicalLinkPrefix         = "l"
+	canonicalTriggerGroupPrefix = "t"
+)
+
+// Canonicalize renames the neurons, links and generated trigger groups to canonical IDs in the order they are added,
+// e.g. n1, n2 for the neurons and l1, l2 for the links, so two blueprints built the same way get the same IDs.
+// The trigger groups of each neuron are renamed in the order of their canonical link IDs, the named trigger groups,
+// the cast groups and the End neurons keep their names. Every reference to an ID is renamed along,
+// and the Neuron and Link returned by the blueprint see their new IDs. Canonicalize the blueprint before building the brain.
+func (b *brainprint) Canonicalize() {
+	neurons := make([]*neuron, 0, len(b.neurons))
+	for _, n := range b.neurons {
+		neurons = append(neurons, n)
+	}
+	sort.Slice(neurons, func(i, j int) bool {
+		return neurons[i].seq < neurons[j].seq
+	})
+	links := make([]*link, 0, len(b.links))
+	for _, l := range b.links {
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].seq < links[j].seq
+	})
+
+	neuronIDs := make(map[string]string, len(neurons))
+	seq := 0
+	for _, n := range neurons {
+		if core.IsEndNeuronID(n.id) {
+			neuronIDs[n.id] = n.id
+			continue
+		}
+		seq++
+		neuronIDs[n.id] = fmt.Sprintf("%s%d", canonicalNeuronPrefix, seq)
+	}
+	linkIDs := make(map[string]string, len(links))
+	for i, l := range links {
+		linkIDs[l.id] = fmt.Sprintf("%s%d", canonicalLinkPrefix, i+1)
+	}
+
+	b.neurons = make(map[string]*neuron, len(neurons))
+	for _, n := range neurons {
+		n.id = neuronIDs[n.id]
+		n.canonicalizeGroups(linkIDs)
+		b.neurons[n.id] = n
+	}
+	b.links = make(map[string]*link, len(links))
+	for _, l := range links {
+		l.id = linkIDs[l.id]
+		if id, ok := neuronIDs[l.src]; ok {
+			l.src = id
+		}
+		if id, ok := neuronIDs[l.dest]; ok {
+			l.dest = id
+		}
+		b.links[l.id] = l
+	}
+}
+
+// canonicalizeGroups renames the links of the groups of the neuron, and its generated trigger groups,
+// see brainprint.Canonicalize. key of linkIDs: link ID, value: canonical link ID.
+func (n *neuron) canonicalizeGroups(linkIDs map[string]string) {
+	for name, group := range n.castGroups {
+		renamed := make(map[string]struct{}, len(group))
+		for id := range group {
+			renamed[linkIDs[id]] = struct{}{}
+		}
+		n.castGroups[name] = renamed
+	}
+
+	type generated struct {
+		links []string
+		key   string
+	}
+	groups := make(triggerGroups, len(n.triggerGroups))
+	unnamed := make([]generated, 0)
+	for key, group := range n.triggerGroups {
+		renamed := make([]string, 0, len(group))
+		for _, id := range group {
+			renamed = append(renamed, linkIDs[id])
+		}
+		if _, named := n.namedTriggerGroups[key]; named {
+			groups[key] = renamed
+			continue
+		}
+		sorted := append([]string(nil), renamed...)
+		sort.Strings(sorted)
+		unnamed = append(unnamed, generated{links: sorted, key: key})
+		groups[key] = renamed
+	}
+	sort.Slice(unnamed, func(i, j int) bool {
+		return strings.Join(unnamed[i].links, ",") < strings.Join(unnamed[j].links, ",")
+	})
+
+	priorities := n.triggerGroupPriorities
+	n.triggerGroupPriorities = nil
+	n.triggerGroups = make(triggerGroups, len(groups))
+	for key := range n.namedTriggerGroups {
+		if group, ok := groups[key]; ok {
+			n.triggerGroups[key] = group
+		}
+	}
+	seq := 0
+	for _, g := range unnamed {
+		// a named trigger group may have a canonical key
+		key, taken := "", true
+		for taken {
+			seq++
+			key = fmt.Sprintf("%s%d", canonicalTriggerGroupPrefix, seq)
+			_, taken = n.triggerGroups[key]
+		}
+		n.triggerGroups[key] = groups[g.key]
+		if priority, ok := priorities[g.key]; ok {
+			n.SetTriggerGroupPriority(key, priority)
+		}
+	}
+	// the priorities of the named trigger groups, or of the groups to be added, keep their keys
+	for key, priority := range priorities {
+		if _, generated := groups[key]; generated {
+			if _, named := n.namedTriggerGroups[key]; !named {
+				continue
+			}
+		}
+		n.SetTriggerGroupPriority(key, priority)
+	}
+}
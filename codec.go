@@ -0,0 +1,156 @@
+package rModel
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+// EncodedValue is a memory value encoded by the codec of its type, see EncodeValue.
+type EncodedValue struct {
+	// Type is the name of the concrete type of the value, see TypeName, empty for a nil value
+	Type string
+	// Data is the value encoded by the codec of Type
+	Data []byte
+}
+
+var codecs = struct {
+	mu     sync.RWMutex
+	byType map[string]core.Codec
+}{byType: make(map[string]core.Codec)}
+
+func init() {
+	for _, sample := range []interface{}{
+		"", false, []byte(nil),
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+	} {
+		RegisterCodec(sample, NewGobCodec(sample))
+	}
+}
+
+// TypeName returns the name the codec of the concrete type of the value is registered by,
+// the package path and the name of a named type or a pointer to it, e.g. `*github.com/acme/app.Order`, otherwise the type literal, e.g. `[]string`.
+func TypeName(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	return typeName(reflect.TypeOf(v))
+}
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+
+	return t.String()
+}
+
+// RegisterCodec registers the codec of the concrete type of the sample value, replacing the codec registered before.
+// The strings, bools, byte slices and numbers of the builtin types are registered by gob.
+func RegisterCodec(sample interface{}, codec core.Codec) {
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+	codecs.byType[TypeName(sample)] = codec
+}
+
+func getCodec(typeName string) (core.Codec, error) {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	codec, ok := codecs.byType[typeName]
+	if !ok {
+		return nil, errors.ErrNoCodec(typeName)
+	}
+
+	return codec, nil
+}
+
+// EncodeValue encodes the value by the codec of its type, ErrNoCodec is returned if the type has no codec.
+func EncodeValue(v interface{}) (EncodedValue, error) {
+	if v == nil {
+		return EncodedValue{}, nil
+	}
+	typeName := TypeName(v)
+	codec, err := getCodec(typeName)
+	if err != nil {
+		return EncodedValue{}, err
+	}
+	data, err := codec.Encode(v)
+	if err != nil {
+		return EncodedValue{}, err
+	}
+
+	return EncodedValue{Type: typeName, Data: data}, nil
+}
+
+// DecodeValue decodes the value encoded by EncodeValue, ErrNoCodec is returned if its type has no codec.
+func DecodeValue(ev EncodedValue) (interface{}, error) {
+	if ev.Type == "" {
+		return nil, nil
+	}
+	codec, err := getCodec(ev.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Decode(ev.Data)
+}
+
+// NewJSONCodec new codec of the concrete type of the sample value by encoding/json
+func NewJSONCodec(sample interface{}) core.Codec {
+	return &jsonCodec{typ: reflect.TypeOf(sample)}
+}
+
+type jsonCodec struct {
+	typ reflect.Type
+}
+
+func (c *jsonCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Decode(data []byte) (interface{}, error) {
+	ptr := reflect.New(c.typ)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+// NewGobCodec new codec of the concrete type of the sample value by encoding/gob
+func NewGobCodec(sample interface{}) core.Codec {
+	return &gobCodec{typ: reflect.TypeOf(sample)}
+}
+
+type gobCodec struct {
+	typ reflect.Type
+}
+
+func (c *gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *gobCodec) Decode(data []byte) (interface{}, error) {
+	ptr := reflect.New(c.typ)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	return ptr.Elem().Interface(), nil
+}
@@ -0,0 +1,169 @@
+package core
+
+// Result is the result of one run in a batch.
+type Result struct {
+	// Index of the input in the batch
+	Index int
+	// Memory of the output keys after the run, see WithOutputKeys
+	Memory map[string]any
+	// ReachedEnds IDs of End neurons reached in the run
+	ReachedEnds []string
+	// Err of the run, the first neuron process error, or the context error if the run is not finished
+	Err error
+	// Trace neuron processes of the run, in order of finish
+	Trace Trace
+	// Labels of the run, see WithBatchLabels, shared by the results of the batch
+	Labels map[string]string
+
+	// memories of the run when it is over, see FinalMemory
+	finalMemory map[string]any
+	// size of the slowest list of Stats
+	statsSlowestN int
+	// redacts the memory of Failures
+	failureRedaction func(key string) bool
+}
+
+// Stats summarizes the trace of the run, with the slowest neuron processes, see WithStatsSlowestN.
+func (r Result) Stats() RunStats {
+	return NewRunStats(r.Trace, r.statsSlowestN)
+}
+
+// Summary summarizes the run in a JSON serializable form, e.g. for the assertions of an integration test
+func (r Result) Summary() RunSummary {
+	summary := NewRunSummary(r.ReachedEnds, r.Err, r.Trace)
+	summary.Labels = r.Labels
+
+	return summary
+}
+
+// Failures reports the failed neuron processes of the run in order of finish, e.g. to ship to an error tracking service.
+// The memory of each report is the memory of the result, without the keys redacted by WithFailureRedaction.
+// The failures are read from the trace, so there is none if the trace is not retained, see ResultRetention.
+func (r Result) Failures() []FailureReport {
+	reports := make([]FailureReport, 0)
+	for _, e := range r.Trace {
+		if e.Err == nil {
+			continue
+		}
+		var memory map[string]any
+		if len(r.Memory) != 0 {
+			memory = make(map[string]any, len(r.Memory))
+			for key, value := range r.Memory {
+				if r.failureRedaction == nil || !r.failureRedaction(key) {
+					memory[key] = value
+				}
+			}
+		}
+		reports = append(reports, NewFailureReport(e, memory))
+	}
+
+	return reports
+}
+
+// FinalMemory returns a copy of all the memories of the run when it is over, unlike Memory which holds the output keys.
+// The memory keys which are not strings are formatted by fmt.Sprint. The values are not copied, treat them as read-only.
+// It is kept with every ResultRetention, as the memory is.
+func (r Result) FinalMemory() map[string]interface{} {
+	memory := make(map[string]interface{}, len(r.finalMemory))
+	for key, value := range r.finalMemory {
+		memory[key] = value
+	}
+
+	return memory
+}
+
+// SetFinalMemory sets the memories of the run when it is over, by the brain running it
+func (r *Result) SetFinalMemory(memory map[string]interface{}) {
+	r.finalMemory = memory
+}
+
+// NewResult new result of the input index, the slowest list of its Stats is sized by the config.
+func NewResult(index int, config *BatchConfig) Result {
+	return Result{
+		Index:            index,
+		statsSlowestN:    config.StatsSlowestN,
+		failureRedaction: config.FailureRedaction,
+		Labels:           config.Labels,
+	}
+}
+
+// BatchConfig configures a batch run.
+type BatchConfig struct {
+	// OutputKeys memories collected into the result of each run
+	OutputKeys []string
+	// FailFast stops the batch on the first failed run, the rest runs fail with the context error
+	FailFast bool
+	// StatsSlowestN size of the slowest list of Result.Stats
+	StatsSlowestN int
+	// FailureRedaction returns true for the memory keys left out of the memory of Result.Failures
+	FailureRedaction func(key string) bool
+	// Labels of each run of the batch, see WithRunLabels
+	Labels map[string]string
+}
+
+// NewBatchConfig new batch config with options
+func NewBatchConfig(withOpts ...BatchOption) *BatchConfig {
+	config := &BatchConfig{
+		StatsSlowestN: DefaultStatsSlowestN,
+	}
+	for _, opt := range withOpts {
+		opt.Apply(config)
+	}
+
+	return config
+}
+
+// BatchOption configures a batch run.
+type BatchOption interface {
+	Apply(config *BatchConfig)
+}
+
+// batchOptionFunc wraps a func, so it satisfies the BatchOption interface.
+type batchOptionFunc func(*BatchConfig)
+
+func (f batchOptionFunc) Apply(config *BatchConfig) {
+	f(config)
+}
+
+// WithOutputKeys collects the memories of keys into the result of each run
+func WithOutputKeys(keys ...string) BatchOption {
+	return batchOptionFunc(func(config *BatchConfig) {
+		config.OutputKeys = append(config.OutputKeys, keys...)
+	})
+}
+
+// WithBatchLabels labels each run of the batch like WithRunLabels, the labels are in the result of each run
+func WithBatchLabels(labels map[string]string) BatchOption {
+	return batchOptionFunc(func(config *BatchConfig) {
+		if len(labels) == 0 {
+			return
+		}
+		if config.Labels == nil {
+			config.Labels = make(map[string]string, len(labels))
+		}
+		for key, value := range labels {
+			config.Labels[key] = value
+		}
+	})
+}
+
+// WithFailFast stops the batch on the first failed run, instead of capturing the failure in its result
+func WithFailFast() BatchOption {
+	return batchOptionFunc(func(config *BatchConfig) {
+		config.FailFast = true
+	})
+}
+
+// WithStatsSlowestN sets the size of the slowest list of Result.Stats, DefaultStatsSlowestN by default
+func WithStatsSlowestN(n int) BatchOption {
+	return batchOptionFunc(func(config *BatchConfig) {
+		config.StatsSlowestN = n
+	})
+}
+
+// WithFailureRedaction leaves the memory keys out of the memory of Result.Failures if redact returns true, e.g. secrets
+func WithFailureRedaction(redact func(key string) bool) BatchOption {
+	return batchOptionFunc(func(config *BatchConfig) {
+		config.FailureRedaction = redact
+	})
+}
@@ -22,13 +22,35 @@ type Blueprint interface {
 	ListEndLinks() []Link
 	ListInLinks(neuronID string) []Link
 	ListOutLinks(neuronID string) []Link
+	// ListCastGroupsResolved get the cast groups of the neuron with their links resolved, key: group name,
+	// the links of a group are sorted by ID. Returns error if the neuron or a link of its groups is not in the blueprint.
+	ListCastGroupsResolved(neuronID string) (map[string][]Link, error)
 
 	AddNeuron(processFn func(bc processor.BrainContext) error, withOpts ...NeuronOption) Neuron
 	AddNeuronWithProcessor(processor processor.Processor, withOpts ...NeuronOption) Neuron
 	AddLink(from, to Neuron, withOpts ...LinkOption) (Link, error)
 	AddEntryLinkTo(neuron Neuron, withOpts ...LinkOption) (Link, error)
 	AddEndLinkFrom(neuron Neuron, withOpts ...LinkOption) (Link, error)
+	// AddEndNeuron adds a named End neuron (returns the existing one if already added), link to it by AddLink.
+	// The default End neuron used by AddEndLinkFrom is returned when name is empty.
+	AddEndNeuron(name string) Neuron
 
+	// DefineGroupAlias defines the cast group alias, a selector returning the alias casts to the group of each neuron.
+	// key of perNeuron: neuron ID, value: group name of the neuron.
+	DefineGroupAlias(alias string, perNeuron map[string]string) error
+
+	// Validate checks the topology of the blueprint, it is not called by BuildBrain.
+	// It returns the first error of ValidateReport, warnings are ignored.
+	Validate() error
+	// ValidateReport runs all the checks of Validate, and reports every error found together with the non-fatal warnings.
+	ValidateReport() ValidationReport
+	// TopologicalOrder returns the neurons sorted so every neuron is after its upstream neurons, ties broken by neuron ID,
+	// so the order is stable for the same topology. A loop is allowed if one of its neurons has max revisits,
+	// and ordered from the neuron it is entered at, otherwise ErrCycle is returned.
+	TopologicalOrder() ([]Neuron, error)
+	// Canonicalize renames the neurons, links and generated trigger groups to canonical IDs by the order they are added,
+	// so blueprints built the same way get the same IDs, e.g. for golden files. Every reference is renamed along.
+	Canonicalize()
 	Clone() Blueprint
 }
 
@@ -1,5 +1,11 @@
 package core
 
+import (
+	"context"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
 const (
 	// BrainStateShutdown brain
 	BrainStateShutdown BrainState = "Shutdown"
@@ -9,10 +15,16 @@ const (
 
 type BrainState string
 
+// CompletionNeuronID is the current neuron ID of the completion processor, see Brain.SetCompletionProcessor
+const CompletionNeuronID = "__COMPLETION__"
+
 type Brain interface {
 	TrigLinks(links ...Link) error
 	Entry() error
 	EntryWithMemory(keysAndValues ...any) error
+	// Run enters the brain like Entry, with the options applied to the new run only, e.g. WithSelectorOverride.
+	// Returns ErrBrainRunning if the brain is running already, the options can not apply to a run in progress.
+	Run(withOpts ...RunOption) error
 
 	// SetMemory set memories for brain, one key value pair is one memory.
 	// memory will lazy initial util `SetMemory` or any link trig
@@ -27,8 +39,85 @@ type Brain interface {
 	ClearMemory()
 	// GetState get brain state
 	GetState() BrainState
+	// GetReachedEnds get IDs of End neurons reached in the current (or last) run
+	GetReachedEnds() []string
+	// GetRunError get the first error of the current (or last) run, e.g. a neuron process error or ErrDeadlock
+	GetRunError() error
+	// GetRunTrace get the neuron processes of the current (or last) run, in order of finish
+	GetRunTrace() Trace
+	// GetRunLabels get the labels of the current (or last) run, see WithRunLabels
+	GetRunLabels() map[string]string
+	// GetExecutedNeurons get the IDs of the neurons processed so far in the current (or last) run, in order of finish.
+	// Safe to poll while the brain is running, it is kept apart from the trace, so whatever the ResultRetention.
+	GetExecutedNeurons() []string
+	// WhyNotFired explains why the neuron has not fired since the brain is reset, from its trigger groups,
+	// the signals arrived and the cast groups selected by its upstream neurons
+	WhyNotFired(neuronID string) string
+	// RoutingReport get the routing of every neuron but the End neurons, its cast groups and its selector, sorted by neuron ID
+	RoutingReport() []NeuronRouting
+	// LinkSignalCount get the number of signals delivered by the link in the current (or last) run
+	LinkSignalCount(linkID string) int
+	// SignalQueueDepth get the number of signals and events waiting to be handled by the brain
+	SignalQueueDepth() int
+	// Reset clears memory and the status left by the last run, so the brain can be reused for the next run.
+	// Returns error if the brain is running.
+	Reset() error
+	// RunBatch runs each input as an independent run with at most concurrency runs in parallel,
+	// and returns the results in input order.
+	RunBatch(ctx context.Context, inputs []map[string]any, concurrency int, withOpts ...BatchOption) ([]Result, error)
+	// AddNeuron adds the neuron to the brain, with its processor, selector, labels and cast group aliases.
+	// Links of the neuron are not added, connect it by AddLink.
+	// Returns error if the brain is running.
+	AddNeuron(neuron Neuron) error
+	// RemoveNeuron removes the neuron from the brain.
+	// Returns error if the brain is running, the neuron is an End neuron, or links are still connected to the neuron.
+	RemoveNeuron(neuronID string) error
+	// AddLink adds the link to the brain, the link is cast in the default cast group of the source neuron,
+	// and triggers the destination neuron on its own. Missing End neurons are added automatically.
+	// Returns error if the brain is running.
+	AddLink(link Link) error
+	// RemoveLink removes the link from the brain, see RemoveLinks.
+	RemoveLink(linkID string) error
+	// RemoveLinks removes the links from the brain, with the trigger groups waiting for them.
+	// Returns error if the brain is running, a trigger group would lose only a part of its links,
+	// or no End neuron would be reachable from the entry links any more.
+	RemoveLinks(linkIDs ...string) error
+	// DefineSubsystem adds the neurons to the subsystem of the name, to enable, disable and measure them together.
+	// Returns error if a neuron is not found, or belongs to another subsystem.
+	DefineSubsystem(name string, neuronIDs ...string) error
+	// ListSubsystems key: subsystem name, value: sorted neuron IDs
+	ListSubsystems() map[string][]string
+	// SetSubsystemEnabled enables or disables the neurons of the subsystem, a disabled neuron is not activated by its in-links.
+	SetSubsystemEnabled(name string, enabled bool) error
+	// GetSubsystemStats get the stats of the neuron processes of the subsystem in the current (or last) run
+	GetSubsystemStats(name string) (RunStats, error)
+	// SetCompletionProcessor sets the processor run once at the end of every run which reaches an End neuron without error,
+	// before the brain sleeps. It has full memory access, its memories are part of the run result,
+	// and its error is the run error. Nil removes it.
+	SetCompletionProcessor(p processor.Processor)
+	// DryRun runs the brain with the decisions of the scenario without invoking the processors, e.g. to check before deploying
+	// that the intended neurons are reachable and an End neuron is reached. Neither the memory nor the status of the brain is changed.
+	DryRun(scenario Scenario) DryRunResult
+	// NewSession returns a session to run the brain incrementally, by feeding the memories required by the neurons,
+	// see WithRequiredMemory
+	NewSession() Session
 	// Wait wait util brain maintainer shutdown, which means brain state is `Sleeping`
 	Wait()
 	// Shutdown the brain
 	Shutdown()
 }
+
+// Session runs the brain incrementally. The first Feed enters the brain, the run advances as far as the neurons
+// ready allow, and pauses when the other neurons wait for their required memories, see WithRequiredMemory.
+type Session interface {
+	// Feed sets the memory, then returns when the run pauses again or completes.
+	// Returns ErrSessionDone if the run is completed already, or if the first Feed fails to enter the brain.
+	Feed(key, value any) error
+	// WaitForNeuron blocks until the neuron is executed in the run of the session, e.g. an approval neuron,
+	// and returns nil once its process returns, whether it fails or not. It waits for the first Feed to enter the brain.
+	// Returns ErrNeuronNotExecuted if the run completes without executing it, ErrSessionNotEntered wrapping the entry
+	// error if the first Feed fails to enter the brain, or the error of the context once it is done.
+	WaitForNeuron(ctx context.Context, neuronID string) error
+	// Done reports whether the run of the session is completed
+	Done() bool
+}
@@ -0,0 +1,18 @@
+package core
+
+import "time"
+
+// BudgetStrategy computes the timeout of a neuron process from the deadline budget of the run, see WithDeadlineBudget
+// of the brain. remaining is the budget left when the process starts, and pathLen the number of neurons on the longest
+// path from the neuron to an End neuron, the neuron included. A timeout beyond remaining is cut to remaining.
+type BudgetStrategy func(remaining time.Duration, pathLen int) time.Duration
+
+// BudgetEqualSplit gives the process an equal share of the remaining budget among the neurons left on its path,
+// so a process finishing early leaves more time to the downstream processes.
+func BudgetEqualSplit(remaining time.Duration, pathLen int) time.Duration {
+	if pathLen < 1 {
+		pathLen = 1
+	}
+
+	return remaining / time.Duration(pathLen)
+}
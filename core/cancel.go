@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CancelReason is the reason the context of a run is cancelled, see WithCancelReason.
+type CancelReason string
+
+const (
+	// CancelReasonNone the context is not cancelled, or not by a reason
+	CancelReasonNone CancelReason = ""
+	// CancelReasonDeadlineExceeded the run is out of time, e.g. the context of RunBatch is past its deadline
+	CancelReasonDeadlineExceeded CancelReason = "deadline_exceeded"
+	// CancelReasonRunAborted the run is aborted, e.g. by processor.ErrAbortRun or the step limit
+	CancelReasonRunAborted CancelReason = "run_aborted"
+	// CancelReasonEarlyExit a selector ends the run early by processor.SelectEnd, with WithCancelOnSelectEnd of the brain
+	CancelReasonEarlyExit CancelReason = "early_exit"
+	// CancelReasonShutdown the brain is shut down
+	CancelReasonShutdown CancelReason = "shutdown"
+	// CancelReasonRunOver the run is over and the next run started, the processes of the run are late
+	CancelReasonRunOver CancelReason = "run_over"
+)
+
+type cancelReasonKey struct{}
+
+type cancelReason struct {
+	mu     sync.Mutex
+	reason CancelReason
+}
+
+// WithCancelReason returns a copy of parent with a cancel func taking the reason. The first reason is kept,
+// it is set before the Done channel is closed, so CancelReasonOf returns it once Done is closed.
+func WithCancelReason(parent context.Context) (context.Context, func(reason CancelReason)) {
+	holder := &cancelReason{}
+	ctx, cancel := context.WithCancel(context.WithValue(parent, cancelReasonKey{}, holder))
+
+	return ctx, func(reason CancelReason) {
+		holder.mu.Lock()
+		if holder.reason == CancelReasonNone {
+			holder.reason = reason
+		}
+		holder.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelReasonOf returns the reason the context is cancelled, CancelReasonNone if it is not cancelled by WithCancelReason
+func CancelReasonOf(ctx context.Context) CancelReason {
+	holder, ok := ctx.Value(cancelReasonKey{}).(*cancelReason)
+	if !ok {
+		return CancelReasonNone
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	return holder.reason
+}
+
+// CancelReasonOfErr returns the reason of cancelling a run by the error, CancelReasonDeadlineExceeded for
+// context.DeadlineExceeded, otherwise CancelReasonRunAborted
+func CancelReasonOfErr(err error) CancelReason {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CancelReasonDeadlineExceeded
+	}
+
+	return CancelReasonRunAborted
+}
@@ -0,0 +1,8 @@
+package core
+
+// Codec encodes and decodes the memory values of one concrete type, e.g. to snapshot the memory
+// or to share it through a memory store across processes. Decode returns a value of the type of the codec.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
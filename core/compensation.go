@@ -0,0 +1,38 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompensationPolicy is the policy of calling the compensations registered by the neuron processes of a run,
+// see processor.BrainContext.RegisterCompensation.
+type CompensationPolicy int
+
+const (
+	// CompensateOnFailure calls the compensations once a run fails, i.e. it ends with a run error, the default.
+	CompensateOnFailure CompensationPolicy = iota
+	// CompensateNever drops the compensations at the end of every run.
+	CompensateNever
+)
+
+// CompensationError is the run error when compensations of a failed run fail too, it unwraps to the error of the run.
+type CompensationError struct {
+	// RunErr is the error the run failed with
+	RunErr error
+	// Failures of the compensations, in order of call
+	Failures []error
+}
+
+func (e *CompensationError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("%v; %d compensation(s) failed: %s", e.RunErr, len(e.Failures), strings.Join(msgs, "; "))
+}
+
+func (e *CompensationError) Unwrap() error {
+	return e.RunErr
+}
@@ -0,0 +1,24 @@
+package core
+
+// Scenario fixes the decisions of a dry run, see Brain.DryRun.
+type Scenario struct {
+	// Selections the cast group selected by each neuron, key: neuron ID, value: cast group name or alias
+	Selections map[string]string
+	// AllBranches a neuron not in Selections casts all of its out-links, instead of the default cast group
+	AllBranches bool
+}
+
+// DryRunResult is the result of a dry run, see Brain.DryRun.
+type DryRunResult struct {
+	// Executed IDs of the neurons which would execute, in order of the first finish
+	Executed []string
+	// ReachedEnds IDs of End neurons reached
+	ReachedEnds []string
+	// Err of the dry run, e.g. ErrDeadlock if a trigger group can never be satisfied in the scenario
+	Err error
+}
+
+// EndReached indicates whether an End neuron is reached without error.
+func (r DryRunResult) EndReached() bool {
+	return len(r.ReachedEnds) != 0 && r.Err == nil
+}
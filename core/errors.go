@@ -0,0 +1,59 @@
+package core
+
+import "errors"
+
+var (
+	// ErrNoReachableEnd the blueprint has End neurons, but none of them is reachable from an entry neuron
+	ErrNoReachableEnd = errors.New("no reachable end neuron")
+	// ErrUnsatisfiableTriggerGroup the trigger group contains a link which can never be cast by its source neuron
+	ErrUnsatisfiableTriggerGroup = errors.New("unsatisfiable trigger group")
+	// ErrEmptyCastGroup the named cast group has no link, a selector choosing it casts to nothing
+	//
+	// Deprecated: the validation reports an empty cast group as the non-fatal WarningEmptyCastGroup.
+	ErrEmptyCastGroup = errors.New("empty cast group")
+	// ErrBrainRunning the operation is not allowed while the brain is running
+	ErrBrainRunning = errors.New("brain is running")
+	// ErrDeadlock nothing is running in the brain, but the ready links can never activate their neurons
+	ErrDeadlock = errors.New("deadlock")
+	// ErrReservedMemoryKey the memory key is reserved for the internal state, see ReservedMemoryKeyPrefix
+	ErrReservedMemoryKey = errors.New("reserved memory key")
+	// ErrOrphanLinks the neuron can not be removed while links are still connected to it
+	ErrOrphanLinks = errors.New("removal would orphan links")
+	// ErrEndNeuronRemoval End neurons can not be removed from a brain
+	ErrEndNeuronRemoval = errors.New("end neuron can not be removed")
+	// ErrPartialTriggerGroupRemoval a link can only be removed together with the other links of its trigger groups
+	ErrPartialTriggerGroupRemoval = errors.New("removal would shrink a trigger group")
+	// ErrRunCancelled the run of the neuron is aborted or over, the neuron can not change the memory
+	ErrRunCancelled = errors.New("run is cancelled")
+	// ErrDoubleDelivery a neuron is activated by a link signal which is consumed already
+	ErrDoubleDelivery = errors.New("double delivery of link signal")
+	// ErrRecordingMismatch the recorded run can not be replayed, e.g. the topology changed since recording
+	ErrRecordingMismatch = errors.New("recording mismatch")
+	// ErrStepLimitExceeded the run executes more neurons than the step limit of the brain
+	ErrStepLimitExceeded = errors.New("step limit exceeded")
+	// ErrSessionDone the run of the session is completed, it can not be fed any more
+	ErrSessionDone = errors.New("session is done")
+	// ErrSessionNotEntered the first Feed of the session fails to enter the brain, see Session.WaitForNeuron
+	ErrSessionNotEntered = errors.New("session not entered")
+	// ErrNeuronNotExecuted the run of the session is completed without executing the neuron, see Session.WaitForNeuron
+	ErrNeuronNotExecuted = errors.New("neuron not executed")
+	// ErrSelfLink the link connects a neuron to itself, which is only allowed with bounded revisits, see WithMaxRevisits
+	ErrSelfLink = errors.New("self-link without max revisits")
+	// ErrRevisitLimitExceeded the neuron is activated more times in a run than its max revisits allow
+	ErrRevisitLimitExceeded = errors.New("revisit limit exceeded")
+	// ErrNilProcessor the neuron has no processor, e.g. it is added with a nil processor or process func
+	ErrNilProcessor = errors.New("nil processor")
+	// ErrCycle the neurons form a loop, none of which has max revisits, see WithMaxRevisits
+	ErrCycle = errors.New("cycle without max revisits")
+	// ErrInvalidMetricTags the metric tags are more than MaxMetricTags, or the metric tags or run labels overwrite a label
+	// of the metrics, e.g. MetricLabelNeuron
+	ErrInvalidMetricTags = errors.New("invalid metric tags")
+	// ErrNoNeurons the blueprint or brain has no neuron besides End neurons, there is nothing to run
+	ErrNoNeurons = errors.New("no neurons")
+	// ErrConflictingNeuronConfig the settings of the neuron contradict each other, e.g. WithRunOnce and WithMaxRevisits
+	ErrConflictingNeuronConfig = errors.New("conflicting neuron configuration")
+	// ErrUnknownCastGroup the selector returns a cast group the neuron does not have, see UnknownGroupPolicy
+	ErrUnknownCastGroup = errors.New("unknown cast group")
+	// ErrNoCodec the memory value is of a type without codec, see RegisterCodec of rModel
+	ErrNoCodec = errors.New("no codec")
+)
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PanicError is the error of a neuron process which panics, the panic is recovered by the brain
+// and the process fails with the error, like a process returning an error.
+type PanicError struct {
+	// Value passed to panic
+	Value any
+	// Stack of the goroutine when the process panics
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("process panic: %v", e.Value)
+}
+
+// FailureReport is a serializable report of a failed neuron process, e.g. to ship to an error tracking service.
+type FailureReport struct {
+	// NeuronID of the failed neuron
+	NeuronID string `json:"neuron_id"`
+	// Kind of the processor of the neuron, see processor.KindOf
+	Kind string `json:"kind"`
+	// Message of the process error
+	Message string `json:"message"`
+	// Panic is true if the process panics
+	Panic bool `json:"panic"`
+	// Stack of the panic, empty if the process returns an error
+	Stack string `json:"stack,omitempty"`
+	// Memory collected into the result of the run, without the keys redacted, see WithFailureRedaction
+	Memory map[string]any `json:"memory,omitempty"`
+}
+
+// NewFailureReport new report of the failed neuron process, with the memory snapshot.
+func NewFailureReport(execution NeuronExecution, memory map[string]any) FailureReport {
+	report := FailureReport{
+		NeuronID: execution.NeuronID,
+		Kind:     execution.ProcessorKind,
+		Memory:   memory,
+	}
+	if execution.Err != nil {
+		report.Message = execution.Err.Error()
+	}
+	var panicErr *PanicError
+	if errors.As(execution.Err, &panicErr) {
+		report.Panic = true
+		report.Stack = panicErr.Stack
+	}
+
+	return report
+}
@@ -0,0 +1,23 @@
+package core
+
+import "time"
+
+// Hooks are called by a brain at run granularity, e.g. to bracket every run in an external audit or metrics system.
+// Hooks are called synchronously on the goroutines of the brain, keep them quick, and never wait for the brain in them.
+type Hooks struct {
+	// OnRunStart is called when a run starts, before any neuron is activated.
+	// initialMemory holds the memories set by the caller since the last run, e.g. by EntryWithMemory.
+	OnRunStart func(runID string, initialMemory map[any]any)
+	// OnRunEnd is called exactly once for every started run, when the brain sleeps, also for an aborted or ended run,
+	// or when the brain is shut down during the run. It is called after the completion processor and before Wait returns.
+	// err is the run error, see Brain.GetRunError, and duration is the time from the start of the run.
+	OnRunEnd func(runID string, result RunResult, err error, duration time.Duration)
+}
+
+// RunResult is the result of a run passed to Hooks.OnRunEnd.
+type RunResult struct {
+	// ReachedEnds IDs of End neurons reached in the run
+	ReachedEnds []string
+	// Trace neuron processes of the run, in order of finish
+	Trace Trace
+}
@@ -1,5 +1,10 @@
 package core
 
+import (
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
+)
+
 const (
 	EntryLinkFrom = "__EXTERNAL_SIGNAL__"
 	EndLinkTo     = EndNeuronID
@@ -20,8 +25,18 @@ type Link interface {
 	GetDestNeuronID() string
 	IsEntryLink() bool
 	IsEndLink() bool
+	// IsOptional returns true if the link does not take part in the trigger of the destination neuron
+	IsOptional() bool
+	// GetOutcome returns the outcome of the source neuron process the link is gated by, empty if not gated
+	GetOutcome() processor.Outcome
 
 	SetLabels(labels map[string]string)
+	// SetOptional marks the link optional, its signal is delivered to the destination neuron
+	// if it arrived, but never blocks the trigger. It takes effect when the brain is built.
+	SetOptional(optional bool)
+	// OnlyOnOutcome gates the link by the outcome of the source neuron process, the link is only cast when the outcome
+	// matches. Empty outcome removes the gate. It takes effect when the brain is built.
+	OnlyOnOutcome(outcome processor.Outcome)
 }
 
 // LinkOption configures a link.
@@ -36,9 +51,10 @@ func (f linkOptionFunc) Apply(link Link) {
 	f(link)
 }
 
-// WithLinkLabels sets the specific labels for Link
+// WithLinkLabels sets the specific labels for Link, merged into the existing labels like WithNeuronLabels
 func WithLinkLabels(labels map[string]string) LinkOption {
 	return linkOptionFunc(func(link Link) {
-		link.SetLabels(labels)
+		origin := link.GetLabels()
+		link.SetLabels(utils.MergeLabels(origin, labels))
 	})
 }
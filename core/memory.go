@@ -0,0 +1,17 @@
+package core
+
+import "strings"
+
+// ReservedMemoryKeyPrefix is the prefix of memory keys reserved for the internal state of rModel.
+// Setting a memory with a string key starting with the prefix is rejected with ErrReservedMemoryKey.
+const ReservedMemoryKeyPrefix = "__rmodel."
+
+// IsReservedMemoryKey indicates whether the memory key is reserved for the internal state of rModel
+func IsReservedMemoryKey(key any) bool {
+	k, ok := key.(string)
+	return ok && strings.HasPrefix(k, ReservedMemoryKeyPrefix)
+}
+
+// MemoryMergeResolver merges the values of a memory key written by the parallel branches joining at a neuron,
+// given in order of write. The result is set to the key before the neuron processes.
+type MemoryMergeResolver func(values []interface{}) interface{}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryOperation string
+
+const (
+	MemoryOperationRead   MemoryOperation = "read"
+	MemoryOperationWrite  MemoryOperation = "write"
+	MemoryOperationDelete MemoryOperation = "delete"
+	MemoryOperationClear  MemoryOperation = "clear"
+)
+
+// MemoryAuditEvent is one memory access of a brain.
+type MemoryAuditEvent struct {
+	// Seq is the sequence number of the event in the audit stream, starting from 1
+	Seq uint64
+	// Time when the memory was accessed
+	Time time.Time
+	// BrainID of the accessed brain
+	BrainID string
+	// NeuronID of the neuron accessing memory, empty when accessed from outside the brain
+	NeuronID string
+	// Operation on memory
+	Operation MemoryOperation
+	// Key of the memory, nil for MemoryOperationClear
+	Key any
+	// Value written or read, only set when WithAuditValue is used
+	Value any
+}
+
+// MemoryAuditor delivers memory audit events to a sink in order.
+// An engine records the event in the same critical section as the memory access, so Seq follows the real order of accesses,
+// and flushes it after the critical section. Events are delivered in Seq order, one at a time, without any lock held,
+// so the sink may access the brain memory, its own accesses are delivered after the current event.
+// The sink is called on a goroutine accessing memory, so every audited memory access may pay for the sink.
+// Keep the sink cheap (e.g. push to a buffered channel), and use WithAuditKeyFilter or WithAuditSampling to reduce the volume on hot paths.
+type MemoryAuditor struct {
+	sink      func(event MemoryAuditEvent)
+	withValue bool
+	sampling  uint64
+	keyFilter func(key any) bool
+
+	mu    sync.Mutex
+	seq   uint64
+	count uint64
+	// events recorded but not delivered yet
+	pending []MemoryAuditEvent
+	// a goroutine is delivering the pending events
+	delivering bool
+}
+
+// NewMemoryAuditor new memory auditor with the sink
+func NewMemoryAuditor(sink func(event MemoryAuditEvent), withOpts ...MemoryAuditOption) *MemoryAuditor {
+	a := &MemoryAuditor{
+		sink:     sink,
+		sampling: 1,
+	}
+	for _, opt := range withOpts {
+		opt.Apply(a)
+	}
+
+	return a
+}
+
+// Audit records a memory access and delivers it, see Record and Flush.
+func (a *MemoryAuditor) Audit(brainID, neuronID string, op MemoryOperation, key, value any) {
+	a.Record(brainID, neuronID, op, key, value)
+	a.Flush()
+}
+
+// Record assigns the sequence number to a memory access if it passes the key filter and sampling,
+// and queues it for Flush. Call it in the critical section of the memory access.
+func (a *MemoryAuditor) Record(brainID, neuronID string, op MemoryOperation, key, value any) {
+	if a == nil || a.sink == nil {
+		return
+	}
+	if a.keyFilter != nil && op != MemoryOperationClear && !a.keyFilter(key) {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.count++
+	if a.sampling > 1 && (a.count-1)%a.sampling != 0 {
+		return
+	}
+	a.seq++
+	event := MemoryAuditEvent{
+		Seq:       a.seq,
+		Time:      time.Now(),
+		BrainID:   brainID,
+		NeuronID:  neuronID,
+		Operation: op,
+		Key:       key,
+	}
+	if a.withValue {
+		event.Value = value
+	}
+	a.pending = append(a.pending, event)
+}
+
+// Flush delivers the recorded events to the sink in order. If another goroutine is delivering,
+// it returns at once and the events are delivered by that goroutine.
+func (a *MemoryAuditor) Flush() {
+	if a == nil || a.sink == nil {
+		return
+	}
+
+	a.mu.Lock()
+	if a.delivering {
+		a.mu.Unlock()
+		return
+	}
+	a.delivering = true
+	for len(a.pending) != 0 {
+		event := a.pending[0]
+		a.pending = a.pending[1:]
+		a.mu.Unlock()
+		a.sink(event)
+		a.mu.Lock()
+	}
+	a.delivering = false
+	a.mu.Unlock()
+}
+
+// MemoryAuditOption configures a memory auditor.
+type MemoryAuditOption interface {
+	Apply(auditor *MemoryAuditor)
+}
+
+// memoryAuditOptionFunc wraps a func, so it satisfies the MemoryAuditOption interface.
+type memoryAuditOptionFunc func(*MemoryAuditor)
+
+func (f memoryAuditOptionFunc) Apply(auditor *MemoryAuditor) {
+	f(auditor)
+}
+
+// WithAuditValue includes the written or read value in audit events
+func WithAuditValue() MemoryAuditOption {
+	return memoryAuditOptionFunc(func(auditor *MemoryAuditor) {
+		auditor.withValue = true
+	})
+}
+
+// WithAuditSampling only delivers one of every n audit events
+func WithAuditSampling(n int) MemoryAuditOption {
+	return memoryAuditOptionFunc(func(auditor *MemoryAuditor) {
+		if n > 1 {
+			auditor.sampling = uint64(n)
+		}
+	})
+}
+
+// WithAuditKeyFilter only audits the memory keys which the filter returns true
+func WithAuditKeyFilter(filter func(key any) bool) MemoryAuditOption {
+	return memoryAuditOptionFunc(func(auditor *MemoryAuditor) {
+		auditor.keyFilter = filter
+	})
+}
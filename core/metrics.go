@@ -0,0 +1,19 @@
+package core
+
+const (
+	// MetricSelectorChoicesTotal counts the groups returned by the selectors, labeled by MetricLabelNeuron and MetricLabelGroup
+	MetricSelectorChoicesTotal = "selector_choices_total"
+
+	MetricLabelNeuron = "neuron"
+	MetricLabelGroup  = "group"
+
+	// MaxMetricTags the most metric tags of a neuron, see Neuron.SetMetricTags
+	MaxMetricTags = 4
+)
+
+// Metrics receives the metrics of a brain, e.g. to export them to Prometheus.
+// It is called synchronously on the goroutines of the brain, concurrently, keep it quick.
+type Metrics interface {
+	// IncCounter increases the counter of the name and labels by 1
+	IncCounter(name string, labels map[string]string)
+}
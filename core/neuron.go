@@ -1,14 +1,32 @@
 package core
 
 import (
+	"strings"
+	"time"
+
 	"github.com/Rovanta/rmodel/internal/utils"
 	"github.com/Rovanta/rmodel/processor"
 )
 
 const (
 	EndNeuronID = "__END_NEURON__"
+	// separator between EndNeuronID and the name of a named End neuron
+	endNeuronNameSep = ":"
 )
 
+// EndNeuronIDOf returns the ID of the End neuron with the specific name, the default End neuron if name is empty
+func EndNeuronIDOf(name string) string {
+	if name == "" {
+		return EndNeuronID
+	}
+	return EndNeuronID + endNeuronNameSep + name
+}
+
+// IsEndNeuronID indicates whether the ID belongs to the default End neuron or a named End neuron
+func IsEndNeuronID(neuronID string) bool {
+	return neuronID == EndNeuronID || strings.HasPrefix(neuronID, EndNeuronID+endNeuronNameSep)
+}
+
 type NeuronState string
 
 const (
@@ -21,16 +39,73 @@ type Neuron interface {
 	GetLabels() map[string]string
 	GetProcessor() processor.Processor
 	GetSelector() processor.Selector
+	// IsSelectorBound indicates whether the selector is bound explicitly, e.g. by WithSelector, even a
+	// processor.DefaultSelector. The selector of a neuron without is replaced by the default selector of the brain.
+	IsSelectorBound() bool
+	// GetTriggerEvaluator get the trigger evaluator, nil if the neuron is activated by its trigger groups
+	GetTriggerEvaluator() processor.TriggerEvaluator
+	// GetRequiredMemory get the memory keys required to activate the neuron, besides its trigger
+	GetRequiredMemory() []any
+	// GetInputDefaults get the values the processor reads for the memory keys which are not set, key: memory key
+	GetInputDefaults() map[string]interface{}
+	// GetMaxRevisits get the times the neuron can be activated again in a run, 0 for unbounded without self-links
+	GetMaxRevisits() int
+	// GetRunOnce indicates whether the neuron runs at most once per run, whatever triggers it again
+	GetRunOnce() bool
+	// GetRefireGuard get the guard consulted before the neuron fires again in a run, nil to always fire again
+	GetRefireGuard() func(bcr processor.BrainContextReader) bool
+	// GetTriggerGroupPriorities get the priorities of the trigger groups, key: trigger group key, 0 for the others
+	GetTriggerGroupPriorities() map[string]int
+	// GetTriggerTimeout get the window from the first signal arrived to fire the neuron with partial inputs,
+	// and the TriggeredBy of the firing, zero window for no timeout
+	GetTriggerTimeout() (time.Duration, string)
+	// GetMemoryMergeResolvers get the resolvers of the memory keys written by the branches joining at the neuron, key: memory key
+	GetMemoryMergeResolvers() map[string]MemoryMergeResolver
+	// GetMergeSingleWriter indicates whether the resolvers are called when only one branch wrote the key
+	GetMergeSingleWriter() bool
+	// GetMetricTags get the tags added to the labels of the metrics of the neuron, unlike GetLabels
+	GetMetricTags() map[string]string
 	ListInLinkIDs() []string
 	ListOutLinkIDs() []string
 	ListTriggerGroups() map[string][]string
+	// EffectiveTrigger the trigger condition in effect after the trigger groups are collapsed,
+	// any of the groups activates the neuron when all of its in-links are ready. Groups and link IDs are sorted.
+	EffectiveTrigger() [][]string
+	// EffectiveTriggerExpression the EffectiveTrigger as a boolean expression of link IDs, e.g. `(lA & lB) | lC`
+	EffectiveTriggerExpression() string
 	ListCastGroups() map[string][]string
+	// CastGroupOf the cast group the out-link is in, the first by name if it is in several, false if in none
+	CastGroupOf(linkID string) (string, bool)
+	// TriggerGroupsContaining the sorted keys of the trigger groups the in-link is in
+	TriggerGroupsContaining(linkID string) []string
+	// ListCastGroupAliases key: alias, value: cast group name
+	ListCastGroupAliases() map[string]string
 
 	SetLabels(labels map[string]string)
 	AddTriggerGroup(links ...Link) error
+	AddNamedTriggerGroup(name string, links ...Link) error
+	// AddTriggerGroupFromNeurons adds a trigger group of all the in-links of the neuron in the blueprint from the source
+	// neurons, e.g. a join fed by several links of each upstream neuron. Returns error if a source has no link to the neuron.
+	AddTriggerGroupFromNeurons(bp Blueprint, sourceNeuronIDs ...string) error
 	AddCastGroup(groupName string, links ...Link) error
+	// AllowEmptyCastGroup marks the cast group intentionally empty, e.g. to terminate the branch, so ValidateReport does not warn about it
+	AllowEmptyCastGroup(groupName string)
 	BindCastGroupSelectFunc(selectFn func(bcr processor.BrainContextReader) string)
 	BindCastGroupSelector(selector processor.Selector)
+	SetTriggerEvaluator(evaluator processor.TriggerEvaluator)
+	SetRequiredMemory(keys ...any)
+	SetInputDefaults(defaults map[string]interface{})
+	SetMaxRevisits(maxRevisits int)
+	SetRunOnce(runOnce bool)
+	SetRefireGuard(fn func(bcr processor.BrainContextReader) bool)
+	SetTriggerGroupPriority(key string, priority int)
+	SetTriggerTimeout(d time.Duration, onTimeoutGroup string)
+	SetMemoryMergeResolver(key string, fn func(values []interface{}) interface{})
+	SetMergeSingleWriter(merge bool)
+	// SetMetricTags sets the tags added to the labels of the metrics of the neuron, e.g. its team or tier. Keep the values
+	// a small fixed set, each one multiplies the series of the metrics. Returns ErrInvalidMetricTags if there are more
+	// than MaxMetricTags tags, or a tag overwrites a label of the metrics.
+	SetMetricTags(tags map[string]string) error
 }
 
 // NeuronOption configures a neuron.
@@ -67,6 +142,89 @@ func WithSelector(selector processor.Selector) NeuronOption {
 	})
 }
 
+// WithTriggerEvaluator sets the specific TriggerEvaluator for Neuron
+func WithTriggerEvaluator(evaluator processor.TriggerEvaluator) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetTriggerEvaluator(evaluator)
+	})
+}
+
+// WithRequiredMemory sets the memory keys required to activate Neuron. Once triggered, Neuron waits until
+// all the keys are in the memory, e.g. fed by a Session, and the run pauses meanwhile.
+func WithRequiredMemory(keys ...any) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetRequiredMemory(keys...)
+	})
+}
+
+// WithInputDefaults sets the values the processor of Neuron reads for the memory keys which are not set when it runs,
+// e.g. an optional input. The defaults are not written to the memory, so they never overwrite a value produced upstream,
+// and the other neurons do not see them.
+func WithInputDefaults(defaults map[string]interface{}) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetInputDefaults(defaults)
+	})
+}
+
+// WithMaxRevisits sets the times Neuron can be activated again in a run, the run is aborted with
+// ErrRevisitLimitExceeded beyond it. A link from Neuron to itself is only allowed with max revisits.
+func WithMaxRevisits(maxRevisits int) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetMaxRevisits(maxRevisits)
+	})
+}
+
+// WithRunOnce makes Neuron run at most once per run, e.g. a side effect reachable by several paths. Once it ran,
+// the triggers satisfied again in the run are ignored and their signals consumed, unlike the revisits which abort the run.
+func WithRunOnce() NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetRunOnce(true)
+	})
+}
+
+// WithRefireGuard sets the guard consulted each time Neuron fires again in a run, e.g. in a loop. Once it returns false,
+// the trigger is ignored and its signals consumed, which ends the loop without the check in the processor.
+// The guard is consulted before the max revisits, so a loop ended by the guard never exceeds them.
+func WithRefireGuard(fn func(bcr processor.BrainContextReader) bool) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetRefireGuard(fn)
+	})
+}
+
+// WithTriggerGroupPriority sets the priority of the trigger group of Neuron, e.g. a named trigger group.
+// If several trigger groups are satisfied at once, the one of the highest priority fires Neuron, and is its TriggeredBy,
+// e.g. for a processor.TriggerGroupSelector. The groups of the same priority, 0 by default, are taken in order of key.
+func WithTriggerGroupPriority(key string, priority int) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetTriggerGroupPriority(key, priority)
+	})
+}
+
+// WithTriggerTimeout sets the window from the first signal arrived to fire Neuron, if its trigger is not satisfied
+// within the window, Neuron fires anyway with the signals arrived, and GetTriggeredBy returns onTimeoutGroup.
+func WithTriggerTimeout(d time.Duration, onTimeoutGroup string) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetTriggerTimeout(d, onTimeoutGroup)
+	})
+}
+
+// WithMemoryMergeResolver sets the resolver of the memory key for Neuron joining parallel branches. When more than one
+// of the branches activating Neuron wrote the key, the resolver merges their last writes, e.g. sums or concatenates them,
+// instead of the last write winning.
+func WithMemoryMergeResolver(key string, fn func(values []interface{}) interface{}) NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetMemoryMergeResolver(key, fn)
+	})
+}
+
+// WithMergeSingleWriter makes the memory merge resolvers of Neuron called with one value when only one branch wrote
+// the key, by default they are skipped then.
+func WithMergeSingleWriter() NeuronOption {
+	return neuronOptionFunc(func(neuron Neuron) {
+		neuron.SetMergeSingleWriter(true)
+	})
+}
+
 // WithPyProcessExecCmd sets the specific python command for Neuron
 func WithPyProcessExecCmd(pythonCmd string) NeuronOption {
 	return neuronOptionFunc(func(neuron Neuron) {
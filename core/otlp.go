@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+)
+
+const (
+	// OTLPRunSpanName name of the root span of a run, see Trace.ExportOTLP
+	OTLPRunSpanName = "rmodel.run"
+
+	OTLPAttrNeuronID      = "rmodel.neuron.id"
+	OTLPAttrProcessorKind = "rmodel.processor.kind"
+	// OTLPAttrLabelPrefix prefix of the attributes of the run labels, see Trace.ExportOTLPWithLabels
+	OTLPAttrLabelPrefix = "rmodel.label."
+)
+
+// Span is a span of the OpenTelemetry (OTLP) data model converted from a run trace, see Trace.ExportOTLP.
+type Span struct {
+	// TraceID shared by the spans of the run
+	TraceID [16]byte
+	SpanID  [8]byte
+	// ParentSpanID span of the upstream process finished last, the root span of the run if none,
+	// zero for the root span itself
+	ParentSpanID [8]byte
+	// Links spans of the other upstream processes, e.g. the other inputs of a join
+	Links [][8]byte
+	Name  string
+	Start time.Time
+	End   time.Time
+	// Attributes e.g. OTLPAttrNeuronID and OTLPAttrProcessorKind
+	Attributes map[string]string
+	// Err of the process, the status of the span is error if not nil
+	Err error
+}
+
+// SpanExporter exports the spans to a tracing backend, e.g. an adapter of an OpenTelemetry SDK exporter.
+type SpanExporter interface {
+	ExportSpans(ctx context.Context, spans []Span) error
+}
+
+// ExportOTLP exports the trace of a completed run to the exporter as one OTLP trace, e.g. for a batch run outside
+// of a live request. A root span covers the run, and a span of each process is a child of the span of its upstream process
+// finished last, see NeuronExecution.Upstream, with links to the spans of the other upstream processes.
+func (t Trace) ExportOTLP(ctx context.Context, exporter SpanExporter) error {
+	return t.ExportOTLPWithLabels(ctx, exporter, nil)
+}
+
+// ExportOTLPWithLabels exports the trace like ExportOTLP, with the labels of the run as attributes of every span,
+// prefixed by OTLPAttrLabelPrefix, e.g. to filter the traces of a tenant. See WithRunLabels.
+func (t Trace) ExportOTLPWithLabels(ctx context.Context, exporter SpanExporter, labels map[string]string) error {
+	if len(t) == 0 {
+		return nil
+	}
+	var traceID [16]byte
+	if _, err := rand.Read(traceID[:]); err != nil {
+		return err
+	}
+	ids := make([][8]byte, len(t)+1)
+	for i := range ids {
+		if _, err := rand.Read(ids[i][:]); err != nil {
+			return err
+		}
+	}
+
+	root := Span{
+		TraceID:    traceID,
+		SpanID:     ids[len(t)],
+		Name:       OTLPRunSpanName,
+		Start:      t[0].Start,
+		End:        t[0].end(),
+		Attributes: labelAttributes(labels),
+	}
+	spans := make([]Span, 0, len(t)+1)
+	for i, e := range t {
+		if e.Start.Before(root.Start) {
+			root.Start = e.Start
+		}
+		if e.end().After(root.End) {
+			root.End = e.end()
+		}
+		span := Span{
+			TraceID:      traceID,
+			SpanID:       ids[i],
+			ParentSpanID: root.SpanID,
+			Links:        make([][8]byte, 0),
+			Name:         e.NeuronID,
+			Start:        e.Start,
+			End:          e.end(),
+			Attributes:   labelAttributes(labels),
+			Err:          e.Err,
+		}
+		span.Attributes[OTLPAttrNeuronID] = e.NeuronID
+		span.Attributes[OTLPAttrProcessorKind] = e.ProcessorKind
+		parent := -1
+		upstream := t.upstreamProcesses(i)
+		for _, j := range upstream {
+			if parent < 0 || t[j].end().After(t[parent].end()) {
+				parent = j
+			}
+		}
+		for _, j := range upstream {
+			if j == parent {
+				span.ParentSpanID = ids[j]
+			} else {
+				span.Links = append(span.Links, ids[j])
+			}
+		}
+		spans = append(spans, span)
+	}
+
+	return exporter.ExportSpans(ctx, append([]Span{root}, spans...))
+}
+
+// labelAttributes returns the span attributes of the run labels
+func labelAttributes(labels map[string]string) map[string]string {
+	attrs := make(map[string]string, len(labels)+2)
+	for key, value := range labels {
+		attrs[OTLPAttrLabelPrefix+key] = value
+	}
+
+	return attrs
+}
@@ -0,0 +1,31 @@
+package core
+
+// NeuronRouting is the routing of the signals out of a neuron, its cast groups and the selector choosing among them.
+type NeuronRouting struct {
+	NeuronID string
+	// SelectorKind is the kind of the selector of the neuron, see processor.KindOf
+	SelectorKind string
+	// CastGroups are the out-link IDs of each cast group of the neuron, sorted, key: cast group name
+	CastGroups map[string][]string
+	// Aliases of the cast groups, key: alias, value: cast group name
+	Aliases map[string]string
+	// PossibleGroups are the cast groups the selector may select, sorted, nil if the selector may select any group,
+	// see processor.PossibleGroupsSelector. For a neuron casting its only cast group without calling the selector,
+	// it is that group.
+	PossibleGroups []string
+}
+
+// UnknownGroupPolicy is the policy of handling a cast group returned by a selector which the neuron does not have,
+// neither as a cast group nor as an alias.
+type UnknownGroupPolicy int
+
+const (
+	// UnknownGroupError fails the run with ErrUnknownCastGroup like a failed process, the default: the neuron casts
+	// no link, and the other branches of the run go on.
+	UnknownGroupError UnknownGroupPolicy = iota
+	// UnknownGroupFallbackToDefault casts the default cast group instead, or fails like UnknownGroupError if the default
+	// cast group of the neuron has no link.
+	UnknownGroupFallbackToDefault
+	// UnknownGroupTerminate ends the run like a selector returning processor.SelectEnd.
+	UnknownGroupTerminate
+)
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// RunConfig configures a single run, see Brain.Run.
+type RunConfig struct {
+	// SelectorOverrides selectors used instead of the selectors of the neurons in the run, key: neuron ID
+	SelectorOverrides map[string]processor.Selector
+	// Labels of the run, e.g. the tenant ID, attached to its metrics, logs and context, see WithRunLabels
+	Labels map[string]string
+}
+
+// NewRunConfig new run config with options
+func NewRunConfig(withOpts ...RunOption) *RunConfig {
+	config := &RunConfig{}
+	for _, opt := range withOpts {
+		opt.Apply(config)
+	}
+
+	return config
+}
+
+// RunOption configures a single run.
+type RunOption interface {
+	Apply(config *RunConfig)
+}
+
+// runOptionFunc wraps a func, so it satisfies the RunOption interface.
+type runOptionFunc func(*RunConfig)
+
+func (f runOptionFunc) Apply(config *RunConfig) {
+	f(config)
+}
+
+// WithSelectorOverride selects the cast groups of the neuron by the selector in the run, instead of its own selector,
+// e.g. to force a routing decision for an experiment. The brain is not changed, the next runs use the selector of the neuron.
+// The run gets a clone of the selector, so the option can be passed to concurrent runs. A nil selector is ignored.
+func WithSelectorOverride(neuronID string, selector processor.Selector) RunOption {
+	return runOptionFunc(func(config *RunConfig) {
+		if selector == nil {
+			return
+		}
+		if config.SelectorOverrides == nil {
+			config.SelectorOverrides = make(map[string]processor.Selector)
+		}
+		config.SelectorOverrides[neuronID] = selector
+	})
+}
+
+// WithRunLabels labels the run, e.g. with the tenant ID of a multi-tenant service. The labels are added to the metrics
+// and the logs of the run, set to its context, see RunLabelsOf, and returned by the brain with the trace of the run.
+// The labels of more options are merged, the later wins.
+//
+// Every distinct value of a label is a new time series of the metrics: label by values of a small and bounded set only,
+// e.g. a tenant ID, and never by a request or user ID. A label can not be MetricLabelNeuron nor MetricLabelGroup.
+func WithRunLabels(labels map[string]string) RunOption {
+	return runOptionFunc(func(config *RunConfig) {
+		if len(labels) == 0 {
+			return
+		}
+		if config.Labels == nil {
+			config.Labels = make(map[string]string, len(labels))
+		}
+		for key, value := range labels {
+			config.Labels[key] = value
+		}
+	})
+}
+
+type runLabelsKey struct{}
+
+// ContextWithRunLabels returns a copy of parent carrying the labels of the run, see RunLabelsOf
+func ContextWithRunLabels(parent context.Context, labels map[string]string) context.Context {
+	return context.WithValue(parent, runLabelsKey{}, labels)
+}
+
+// RunLabelsOf returns the labels of the run of the context, see WithRunLabels, nil if the run has no label.
+// The labels are shared by the processes of the run, treat them as read-only.
+func RunLabelsOf(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(runLabelsKey{}).(map[string]string)
+
+	return labels
+}
@@ -0,0 +1,55 @@
+package core
+
+import "fmt"
+
+// RunSummary is a compact, JSON serializable summary of a run, e.g. for the assertions of an integration test.
+// Its fields are stable, unlike the logs of the brain.
+type RunSummary struct {
+	// NeuronsExecuted number of neuron processes of the run
+	NeuronsExecuted int `json:"neurons_executed"`
+	// EndsReached IDs of End neurons reached in the run
+	EndsReached []string `json:"ends_reached"`
+	// Errors the error of the run first, if any, then the failed processes as `<neuronID>: <error>` in order of finish
+	Errors []string `json:"errors"`
+	// DurationMs from the start of the first process to the end of the last one, in milliseconds
+	DurationMs int64 `json:"duration_ms"`
+	// Path the neuron IDs of the processes in order of finish
+	Path []string `json:"path"`
+	// Labels of the run, see WithRunLabels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewRunSummary summarizes the run by its result, the reached ends, the run error and the trace,
+// e.g. of Brain.GetReachedEnds, Brain.GetRunError and Brain.GetRunTrace. The counts and the path are read from the trace,
+// so they are empty if the trace is not retained, see ResultRetention.
+func NewRunSummary(reachedEnds []string, err error, trace Trace) RunSummary {
+	summary := RunSummary{
+		NeuronsExecuted: len(trace),
+		EndsReached:     append(make([]string, 0, len(reachedEnds)), reachedEnds...),
+		Errors:          make([]string, 0),
+		Path:            make([]string, 0, len(trace)),
+	}
+	if err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+	if len(trace) == 0 {
+		return summary
+	}
+
+	start, end := trace[0].Start, trace[0].end()
+	for _, e := range trace {
+		summary.Path = append(summary.Path, e.NeuronID)
+		if e.Err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %s", e.NeuronID, e.Err))
+		}
+		if e.Start.Before(start) {
+			start = e.Start
+		}
+		if e.end().After(end) {
+			end = e.end()
+		}
+	}
+	summary.DurationMs = end.Sub(start).Milliseconds()
+
+	return summary
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Rovanta/rmodel/internal/utils"
+)
+
+// DefaultStatsSlowestN default size of the slowest list of RunStats, see WithStatsSlowestN
+const DefaultStatsSlowestN = 5
+
+// NeuronExecution is one process of a neuron in a run.
+type NeuronExecution struct {
+	NeuronID string
+	// ProcessorKind kind of the processor of the neuron, see processor.KindOf
+	ProcessorKind string
+	// Start time of the process
+	Start time.Time
+	// Duration of the process
+	Duration time.Duration
+	// Err of the process, nil if succeeded
+	Err error
+	// Upstream IDs of the neurons whose signals activated the process, empty if activated by entry links only
+	Upstream []string
+}
+
+// ResultRetention is the policy of keeping the neuron processes of a run in the trace.
+type ResultRetention int
+
+const (
+	// ResultRetentionFull keeps every neuron process in the trace of the run, the default.
+	ResultRetentionFull ResultRetention = iota
+	// ResultRetentionMinimal keeps no neuron process, only the memory and the result of the run, e.g. the reached ends
+	// and the run error. The memory footprint of long or looping runs is bounded, but the trace is empty,
+	// so the stats, the critical path and the subsystem stats of the run are empty too.
+	ResultRetentionMinimal
+)
+
+// Trace is the neuron processes of a run, in order of finish.
+type Trace []NeuronExecution
+
+// CriticalPath returns the neuron IDs of the chain of dependent processes which determines the duration of the run,
+// from the first process to the process finishing last. Each process of the chain is preceded by its upstream process
+// finishing last before it started, the signal it waited for. Unlike the slowest list of RunStats,
+// a slow process off the chain does not delay the run.
+func (t Trace) CriticalPath() []string {
+	if len(t) == 0 {
+		return nil
+	}
+	last := 0
+	for i, e := range t {
+		if e.end().After(t[last].end()) {
+			last = i
+		}
+	}
+
+	path := make([]string, 0)
+	for i := last; i >= 0; {
+		path = append(path, t[i].NeuronID)
+		prev := -1
+		for _, j := range t.upstreamProcesses(i) {
+			if prev < 0 || t[j].end().After(t[prev].end()) {
+				prev = j
+			}
+		}
+		i = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// upstreamProcesses returns the indexes of the processes whose signals activated the process i,
+// the last process of each upstream neuron finished before the process i started.
+func (t Trace) upstreamProcesses(i int) []int {
+	last := make(map[string]int)
+	// an upstream process finishes before the process is activated, so it is earlier in the trace
+	for j := 0; j < i; j++ {
+		e := t[j]
+		if !utils.SlicesContains(t[i].Upstream, []string{e.NeuronID}) || e.end().After(t[i].Start) {
+			continue
+		}
+		if k, ok := last[e.NeuronID]; !ok || e.end().After(t[k].end()) {
+			last[e.NeuronID] = j
+		}
+	}
+	ret := make([]int, 0, len(last))
+	for _, j := range last {
+		ret = append(ret, j)
+	}
+	sort.Ints(ret)
+
+	return ret
+}
+
+func (e NeuronExecution) end() time.Time {
+	return e.Start.Add(e.Duration)
+}
+
+// RunStats summarizes the execution trace of a run.
+type RunStats struct {
+	// Executed number of neuron processes
+	Executed int
+	// Failed number of failed neuron processes
+	Failed int
+	// Duration from the start of the first process to the end of the last one
+	Duration time.Duration
+	// Slowest neuron processes, slowest first
+	Slowest []NeuronExecution
+}
+
+// NewRunStats summarizes the trace, with the slowest n processes.
+func NewRunStats(trace []NeuronExecution, slowestN int) RunStats {
+	stats := RunStats{
+		Executed: len(trace),
+	}
+	if len(trace) == 0 {
+		return stats
+	}
+
+	start, end := trace[0].Start, trace[0].Start.Add(trace[0].Duration)
+	for _, e := range trace {
+		if e.Err != nil {
+			stats.Failed++
+		}
+		if e.Start.Before(start) {
+			start = e.Start
+		}
+		if e.Start.Add(e.Duration).After(end) {
+			end = e.Start.Add(e.Duration)
+		}
+	}
+	stats.Duration = end.Sub(start)
+
+	sorted := make([]NeuronExecution, len(trace))
+	copy(sorted, trace)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+	if slowestN < 0 {
+		slowestN = 0
+	}
+	if slowestN < len(sorted) {
+		sorted = sorted[:slowestN]
+	}
+	stats.Slowest = sorted
+
+	return stats
+}
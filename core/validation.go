@@ -0,0 +1,53 @@
+package core
+
+import "fmt"
+
+// WarningKind is the kind of a Warning of the validation
+type WarningKind string
+
+const (
+	// WarningUnreachableNeuron the neuron is not reachable from any entry neuron, it only runs when its links are triggered
+	WarningUnreachableNeuron WarningKind = "unreachable_neuron"
+	// WarningUnselectedCastGroup the cast group is not in the possible groups of the selector of its neuron,
+	// see processor.PossibleGroupsSelector, its links are never cast
+	WarningUnselectedCastGroup WarningKind = "unselected_cast_group"
+	// WarningEmptyCastGroup the named cast group has no link and is not allowed to be empty, see Neuron.AllowEmptyCastGroup,
+	// a selector choosing it casts to nothing
+	WarningEmptyCastGroup WarningKind = "empty_cast_group"
+)
+
+// Warning is a non-fatal finding of the validation, the blueprint can be built and run, but is likely not as intended.
+type Warning struct {
+	// Kind of the warning
+	Kind WarningKind `json:"kind"`
+	// NeuronID of the neuron the warning is about
+	NeuronID string `json:"neuron_id"`
+	// Message describes the finding
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Kind, w.Message)
+}
+
+// ValidationReport is the result of Blueprint.ValidateReport.
+type ValidationReport struct {
+	// Errors the blueprint should not be built with, in the order of the checks of Validate
+	Errors []error
+	// Warnings for review, sorted by neuron ID
+	Warnings []Warning
+}
+
+// OK reports whether the report has no errors, warnings are allowed.
+func (r ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the first error of the report, it is the error returned by Validate. nil if there is no error.
+func (r ValidationReport) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return r.Errors[0]
+}
@@ -1,18 +1,47 @@
 package errors
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
+
+	"github.com/Rovanta/rmodel/core"
 )
 
 var (
-	errNeuronNotFound = errors.New("neuron not found")
-	errLinkNotFound   = errors.New("link not found")
+	errNeuronNotFound    = errors.New("neuron not found")
+	errLinkNotFound      = errors.New("link not found")
+	errInvalidLink       = errors.New("invalid link")
+	errGroupNotFound     = errors.New("group not found")
+	errNeuronExists      = errors.New("neuron already exists")
+	errLinkExists        = errors.New("link already exists")
+	errSubsystemNotFound = errors.New("subsystem not found")
+	errSubsystemConflict = errors.New("neuron belongs to another subsystem")
 )
 
 func Wrapf(err error, format string, args ...interface{}) error {
 	return errors.Wrapf(err, format, args...)
 }
 
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+func ErrRunCancelled(neuronID string) error {
+	return errors.Wrapf(core.ErrRunCancelled, "neuron: %s", neuronID)
+}
+
+func ErrDoubleDelivery(linkID, neuronID string, seq int) error {
+	return errors.Wrapf(core.ErrDoubleDelivery, "signal %d of link %s is consumed by neuron %s already", seq, linkID, neuronID)
+}
+
+func ErrStepLimitExceeded(steps int, lastNeuronID, nextNeuronID string) error {
+	return errors.Wrapf(core.ErrStepLimitExceeded, "%d neurons executed, last executed neuron %s, neuron %s not executed",
+		steps, lastNeuronID, nextNeuronID)
+}
+
 func ErrNeuronNotFound(neuronID string) error {
 	return errors.Wrapf(errNeuronNotFound, "neuron: %s", neuronID)
 }
@@ -25,6 +54,115 @@ func ErrInLinkNotFound(linkID, neuronID string) error {
 	return errors.Wrapf(errLinkNotFound, "in-link %s of neuron %s", linkID, neuronID)
 }
 
+func ErrInLinkFromNeuronNotFound(srcNeuronID, neuronID string) error {
+	return errors.Wrapf(errLinkNotFound, "in-link of neuron %s from neuron %s", neuronID, srcNeuronID)
+}
+
 func ErrOutLinkNotFound(linkID, neuronID string) error {
 	return errors.Wrapf(errLinkNotFound, "out-link %s of neuron %s", linkID, neuronID)
 }
+
+func ErrOutLinkFromEndNeuron(neuronID string) error {
+	return errors.Wrapf(errInvalidLink, "end neuron %s can not have out-link", neuronID)
+}
+
+func ErrBrainRunning(brainID string) error {
+	return errors.Wrapf(core.ErrBrainRunning, "brain: %s", brainID)
+}
+
+func ErrNeuronExists(neuronID string) error {
+	return errors.Wrapf(errNeuronExists, "neuron: %s", neuronID)
+}
+
+func ErrLinkExists(linkID string) error {
+	return errors.Wrapf(errLinkExists, "link: %s", linkID)
+}
+
+func ErrSubsystemNotFound(name string) error {
+	return errors.Wrapf(errSubsystemNotFound, "subsystem: %s", name)
+}
+
+func ErrSubsystemConflict(neuronID, subsystem string) error {
+	return errors.Wrapf(errSubsystemConflict, "neuron %s belongs to subsystem %s", neuronID, subsystem)
+}
+
+func ErrOrphanLinks(neuronID string, linkIDs []string) error {
+	return errors.Wrapf(core.ErrOrphanLinks, "neuron %s still has links %v", neuronID, linkIDs)
+}
+
+func ErrEndNeuronRemoval(neuronID string) error {
+	return errors.Wrapf(core.ErrEndNeuronRemoval, "neuron: %s", neuronID)
+}
+
+func ErrPartialTriggerGroupRemoval(linkID, groupKey, neuronID, otherLinkID string) error {
+	return errors.Wrapf(core.ErrPartialTriggerGroupRemoval,
+		"link %s is in trigger group %s of neuron %s with link %s, remove them together", linkID, groupKey, neuronID, otherLinkID)
+}
+
+func ErrReservedMemoryKey(key any) error {
+	return errors.Wrapf(core.ErrReservedMemoryKey, "key: %v", key)
+}
+
+func ErrDeadlock(stuck []string) error {
+	return errors.Wrapf(core.ErrDeadlock, "stuck neurons: %s", strings.Join(stuck, "; "))
+}
+
+func ErrSessionDone(brainID string) error {
+	return errors.Wrapf(core.ErrSessionDone, "brain %s", brainID)
+}
+
+func ErrSessionNotEntered(brainID string, err error) error {
+	return errors.Wrapf(core.ErrSessionNotEntered, "brain %s: %v", brainID, err)
+}
+
+func ErrNeuronNotExecuted(neuronID, brainID string) error {
+	return errors.Wrapf(core.ErrNeuronNotExecuted, "neuron %s, brain %s", neuronID, brainID)
+}
+
+func ErrSelfLink(neuronID string) error {
+	return errors.Wrapf(core.ErrSelfLink, "neuron %s", neuronID)
+}
+
+func ErrDeadlineBudgetSpent(budget time.Duration, neuronID string) error {
+	return errors.Wrapf(context.DeadlineExceeded, "deadline budget %s of the run is spent before neuron %s", budget, neuronID)
+}
+
+func ErrRevisitLimitExceeded(neuronID string, maxRevisits int) error {
+	return errors.Wrapf(core.ErrRevisitLimitExceeded, "neuron %s revisited more than %d times", neuronID, maxRevisits)
+}
+
+func ErrResolveAtBuild(neuronID string, err error) error {
+	return errors.Wrapf(err, "resolve processor of neuron %s at build", neuronID)
+}
+
+func ErrNilProcessor(neuronID string) error {
+	return errors.Wrapf(core.ErrNilProcessor, "neuron %s", neuronID)
+}
+
+func ErrInvalidMetricTags(neuronID, reason string) error {
+	return errors.Wrapf(core.ErrInvalidMetricTags, "neuron %s: %s", neuronID, reason)
+}
+
+func ErrInvalidRunLabels(label string) error {
+	return errors.Wrapf(core.ErrInvalidMetricTags, "run label %s overwrites the label of the metrics", label)
+}
+
+func ErrConflictingNeuronConfig(neuronID, setting, conflicting string) error {
+	return errors.Wrapf(core.ErrConflictingNeuronConfig, "neuron %s: %s conflicts with %s", neuronID, setting, conflicting)
+}
+
+func ErrNoCodec(typeName string) error {
+	return errors.Wrapf(core.ErrNoCodec, "type %s", typeName)
+}
+
+func ErrCycle(neuronIDs []string) error {
+	return errors.Wrapf(core.ErrCycle, "neurons %v", neuronIDs)
+}
+
+func ErrCastGroupNotFound(groupName, neuronID string) error {
+	return errors.Wrapf(errGroupNotFound, "cast group %s of neuron %s", groupName, neuronID)
+}
+
+func ErrUnknownCastGroup(groupName, neuronID string) error {
+	return errors.Wrapf(core.ErrUnknownCastGroup, "cast group %s selected by neuron %s", groupName, neuronID)
+}
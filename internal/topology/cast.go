@@ -0,0 +1,39 @@
+package topology
+
+import (
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// SoleCastGroup returns the cast group a neuron casts without calling its selector: the only non-empty cast group
+// of a neuron with the processor.DefaultSelector not bound explicitly.
+// groupSizes key: cast group name, value: number of links in the group.
+func SoleCastGroup(selector processor.Selector, selectorBound bool, groupSizes map[string]int) (string, bool) {
+	if _, ok := selector.(*processor.DefaultSelector); !ok || selectorBound {
+		return "", false
+	}
+	sole := ""
+	for name, size := range groupSizes {
+		if size == 0 {
+			continue
+		}
+		if sole != "" {
+			return "", false
+		}
+		sole = name
+	}
+
+	return sole, sole != ""
+}
+
+// PossibleGroups returns the cast groups a neuron may cast, as SoleCastGroup for a neuron casting without calling
+// its selector, or the groups of a processor.PossibleGroupsSelector. ok is false if the selector may select any group.
+func PossibleGroups(selector processor.Selector, selectorBound bool, groupSizes map[string]int) ([]string, bool) {
+	if sole, ok := SoleCastGroup(selector, selectorBound, groupSizes); ok {
+		return []string{sole}, true
+	}
+	if s, ok := selector.(processor.PossibleGroupsSelector); ok {
+		return s.PossibleGroups(), true
+	}
+
+	return nil, false
+}
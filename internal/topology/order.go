@@ -0,0 +1,190 @@
+package topology
+
+import (
+	"sort"
+
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+// Order returns the neuron IDs sorted so every neuron is after its upstream neurons, ties are broken by neuron ID.
+// Each loop, i.e. a strongly connected set of neurons, is checked by loopAllowed, ErrCycle is returned if it is not allowed.
+// An allowed loop is ordered from the neuron it is entered at, ignoring the links back into it.
+func Order(neuronIDs []string, links map[string]Link, loopAllowed func(loop []string) bool) ([]string, error) {
+	ids := append([]string(nil), neuronIDs...)
+	sort.Strings(ids)
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	succ := make(map[string][]string, len(ids))
+	for _, linkID := range sortedLinkIDs(links) {
+		l := links[linkID]
+		if known[l.From] && known[l.To] && !contains(succ[l.From], l.To) {
+			succ[l.From] = append(succ[l.From], l.To)
+		}
+	}
+	for _, to := range succ {
+		sort.Strings(to)
+	}
+
+	loops := stronglyConnected(ids, succ)
+	loopOf := make(map[string]int, len(ids))
+	for i, loop := range loops {
+		for _, id := range loop {
+			loopOf[id] = i
+		}
+	}
+	// the loops form a DAG, sorted by Kahn's algorithm, the loop of the smallest neuron ID first
+	inDegree := make([]int, len(loops))
+	for from, to := range succ {
+		for _, id := range to {
+			if loopOf[from] != loopOf[id] {
+				inDegree[loopOf[id]]++
+			}
+		}
+	}
+	ready := make([]int, 0)
+	for i := range loops {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	order := make([]string, 0, len(ids))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return loops[ready[i]][0] < loops[ready[j]][0] })
+		i := ready[0]
+		ready = ready[1:]
+		loop := loops[i]
+		if len(loop) > 1 || contains(succ[loop[0]], loop[0]) {
+			if !loopAllowed(loop) {
+				return nil, errors.ErrCycle(loop)
+			}
+			order = append(order, orderLoop(loop, succ)...)
+		} else {
+			order = append(order, loop[0])
+		}
+		for _, id := range loop {
+			for _, to := range succ[id] {
+				if j := loopOf[to]; j != i {
+					inDegree[j]--
+					if inDegree[j] == 0 {
+						ready = append(ready, j)
+					}
+				}
+			}
+		}
+	}
+
+	return order, nil
+}
+
+// stronglyConnected returns the strongly connected sets of neurons by Tarjan's algorithm, each set sorted by neuron ID
+func stronglyConnected(ids []string, succ map[string][]string) [][]string {
+	index := make(map[string]int, len(ids))
+	lowLink := make(map[string]int, len(ids))
+	onStack := make(map[string]bool, len(ids))
+	stack := make([]string, 0)
+	loops := make([][]string, 0)
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, to := range succ[id] {
+			if _, ok := index[to]; !ok {
+				visit(to)
+				if lowLink[to] < lowLink[id] {
+					lowLink[id] = lowLink[to]
+				}
+			} else if onStack[to] && index[to] < lowLink[id] {
+				lowLink[id] = index[to]
+			}
+		}
+		if lowLink[id] != index[id] {
+			return
+		}
+		loop := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			loop = append(loop, top)
+			if top == id {
+				break
+			}
+		}
+		sort.Strings(loop)
+		loops = append(loops, loop)
+	}
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+
+	return loops
+}
+
+// orderLoop sorts the neurons of a loop, a neuron is next once its upstream neurons in the loop are sorted,
+// or else the loop is broken at the smallest neuron ID, preferring the neurons linked from outside the loop
+func orderLoop(loop []string, succ map[string][]string) []string {
+	inLoop := make(map[string]bool, len(loop))
+	for _, id := range loop {
+		inLoop[id] = true
+	}
+	inDegree := make(map[string]int, len(loop))
+	entered := make(map[string]bool, len(loop))
+	for from, to := range succ {
+		for _, id := range to {
+			if !inLoop[id] {
+				continue
+			}
+			if inLoop[from] {
+				if from != id {
+					inDegree[id]++
+				}
+			} else {
+				entered[id] = true
+			}
+		}
+	}
+
+	order := make([]string, 0, len(loop))
+	done := make(map[string]bool, len(loop))
+	for len(order) < len(loop) {
+		next := ""
+		for _, id := range loop {
+			if !done[id] && inDegree[id] == 0 {
+				next = id
+				break
+			}
+		}
+		if next == "" {
+			for _, id := range loop {
+				if !done[id] && (next == "" || entered[id] && !entered[next]) {
+					next = id
+				}
+			}
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, to := range succ[next] {
+			if inLoop[to] && to != next && !done[to] {
+				inDegree[to]--
+			}
+		}
+	}
+
+	return order
+}
+
+func sortedLinkIDs(links map[string]Link) []string {
+	ids := make([]string, 0, len(links))
+	for id := range links {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,229 @@
+package topology
+
+import (
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+)
+
+// Topology is a copy of the neurons and links of a brain.
+// Edits of a built brain are checked on it, so all engines share the same rules.
+type Topology struct {
+	// Links key: link ID
+	Links map[string]Link
+	// TriggerGroups key: neuron ID, value: trigger groups of the neuron (key: trigger group key, value: link IDs)
+	TriggerGroups map[string]map[string][]string
+}
+
+type Link struct {
+	// From source neuron ID, core.EntryLinkFrom for entry links
+	From string
+	// To destination neuron ID
+	To string
+}
+
+func New() *Topology {
+	return &Topology{
+		Links:         make(map[string]Link),
+		TriggerGroups: make(map[string]map[string][]string),
+	}
+}
+
+func (t *Topology) CheckAddNeuron(neuronID string) error {
+	if _, ok := t.TriggerGroups[neuronID]; ok {
+		return errors.ErrNeuronExists(neuronID)
+	}
+
+	return nil
+}
+
+// CheckRemoveNeuron End neurons and neurons with links can not be removed.
+func (t *Topology) CheckRemoveNeuron(neuronID string) error {
+	if _, ok := t.TriggerGroups[neuronID]; !ok {
+		return errors.ErrNeuronNotFound(neuronID)
+	}
+	if core.IsEndNeuronID(neuronID) {
+		return errors.ErrEndNeuronRemoval(neuronID)
+	}
+	linkIDs := make([]string, 0)
+	for id, l := range t.Links {
+		if l.From == neuronID || l.To == neuronID {
+			linkIDs = append(linkIDs, id)
+		}
+	}
+	if len(linkIDs) != 0 {
+		sort.Strings(linkIDs)
+		return errors.ErrOrphanLinks(neuronID, linkIDs)
+	}
+
+	return nil
+}
+
+// CheckAddLink the source neuron should exist and not be an End neuron,
+// the destination neuron should exist unless it is an End neuron, which is added with the link.
+func (t *Topology) CheckAddLink(linkID string, l Link) error {
+	if _, ok := t.Links[linkID]; ok {
+		return errors.ErrLinkExists(linkID)
+	}
+	if l.From != core.EntryLinkFrom {
+		if _, ok := t.TriggerGroups[l.From]; !ok {
+			return errors.ErrNeuronNotFound(l.From)
+		}
+		if core.IsEndNeuronID(l.From) {
+			return errors.ErrOutLinkFromEndNeuron(l.From)
+		}
+	}
+	if _, ok := t.TriggerGroups[l.To]; !ok && !core.IsEndNeuronID(l.To) {
+		return errors.ErrNeuronNotFound(l.To)
+	}
+
+	return nil
+}
+
+// CheckRemoveLinks the links removed together should contain all links of the trigger groups they are in,
+// so a trigger group of many links, e.g. a join of a and b, never silently shrinks to fire on a part of its links.
+// The removal should not make all End neurons unreachable from the entry links, if any was reachable.
+func (t *Topology) CheckRemoveLinks(linkIDs ...string) error {
+	removed := make(map[string]bool, len(linkIDs))
+	for _, id := range linkIDs {
+		if _, ok := t.Links[id]; !ok {
+			return errors.ErrLinkNotFound(id)
+		}
+		removed[id] = true
+	}
+
+	for _, id := range sortedKeys(removed) {
+		dest := t.Links[id].To
+		groups := t.TriggerGroups[dest]
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !contains(groups[key], id) {
+				continue
+			}
+			for _, other := range groups[key] {
+				if !removed[other] {
+					return errors.ErrPartialTriggerGroupRemoval(id, key, dest, other)
+				}
+			}
+		}
+	}
+
+	if !EndReachable(t.Links) {
+		return nil
+	}
+	remain := make(map[string]Link, len(t.Links))
+	for id, l := range t.Links {
+		if !removed[id] {
+			remain[id] = l
+		}
+	}
+	if !EndReachable(remain) {
+		return errors.Wrapf(core.ErrNoReachableEnd, "after removing links %v", sortedKeys(removed))
+	}
+
+	return nil
+}
+
+// EndReachable if any End neuron is reachable from the entry links, ignoring cast group selection.
+func EndReachable(links map[string]Link) bool {
+	for id := range Reachable(links) {
+		if core.IsEndNeuronID(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reachable returns all neurons reachable from the entry links, ignoring cast group selection.
+func Reachable(links map[string]Link) map[string]bool {
+	return ReachableFrom(links, core.EntryLinkFrom)
+}
+
+// ReachableFrom returns all neurons reachable from the neurons, excluding themselves unless linked back,
+// ignoring cast group selection. core.EntryLinkFrom starts from the entry links.
+func ReachableFrom(links map[string]Link, neuronIDs ...string) map[string]bool {
+	reachable := make(map[string]bool)
+	queue := make([]string, 0)
+	for _, l := range links {
+		if contains(neuronIDs, l.From) && !reachable[l.To] {
+			reachable[l.To] = true
+			queue = append(queue, l.To)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, l := range links {
+			if l.From == id && !reachable[l.To] {
+				reachable[l.To] = true
+				queue = append(queue, l.To)
+			}
+		}
+	}
+
+	return reachable
+}
+
+func contains(ids []string, target string) bool {
+	for _, id := range ids {
+		if id == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// PathLen returns the number of neurons on the longest path from the neuron, the neuron included and End neurons
+// excluded, ignoring cast group selection. The links back to a neuron of the path are not followed, so a loop counts once.
+func PathLen(links map[string]Link, neuronID string) int {
+	succ := make(map[string][]string)
+	for _, id := range sortedLinkIDs(links) {
+		l := links[id]
+		succ[l.From] = append(succ[l.From], l.To)
+	}
+
+	memo := make(map[string]int)
+	onPath := make(map[string]bool)
+	var walk func(id string) int
+	walk = func(id string) int {
+		if core.IsEndNeuronID(id) {
+			return 0
+		}
+		if n, ok := memo[id]; ok {
+			return n
+		}
+		onPath[id] = true
+		longest := 0
+		for _, next := range succ[id] {
+			if onPath[next] {
+				continue
+			}
+			if n := walk(next); n > longest {
+				longest = n
+			}
+		}
+		onPath[id] = false
+		memo[id] = longest + 1
+
+		return longest + 1
+	}
+
+	return walk(neuronID)
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// NewConsoleLogger new logger writes human-readable logs of info level to stdout, with the caller and timestamp
+func NewConsoleLogger() zerolog.Logger {
+	return zerolog.New(zerolog.ConsoleWriter{
+		Out:        os.Stdout,
+		TimeFormat: time.RFC3339,
+		FormatCaller: func(i interface{}) string {
+			var c string
+			if cc, ok := i.(string); ok {
+				c = cc
+			}
+			if len(c) > 0 && strings.Contains(c, "/") {
+				lastIndex := strings.LastIndex(c, "/")
+				left := c[:lastIndex]
+				c = c[lastIndex+1:]
+				if strings.Contains(left, "/") {
+					lastIndex = strings.LastIndex(left, "/")
+					c = left[lastIndex+1:] + "/" + c
+				}
+			}
+			return c
+		},
+	}).With().Caller().Timestamp().Logger().Level(zerolog.InfoLevel)
+}
@@ -0,0 +1,13 @@
+package rModel
+
+import (
+	"context"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// RunLabels returns the labels of the run of the context, e.g. processor.BrainContext.Context(), see core.WithRunLabels.
+// A processor can label its own telemetry with them, e.g. the tenant ID, nil if the run has no label.
+func RunLabels(ctx context.Context) map[string]string {
+	return core.RunLabelsOf(ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/Rovanta/rmodel/core"
 	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
 )
 
 func newLink(srcNeuronID, destNeuronID string) *link {
@@ -42,6 +43,12 @@ type link struct {
 	src string
 	// to destination neuron ID
 	dest string
+	// optional link does not block the trigger of the destination neuron
+	optional bool
+	// outcome of the source neuron process the link is cast on, empty for any
+	outcome processor.Outcome
+	// sequence of the link in the blueprint, see Canonicalize
+	seq int
 }
 
 func (l *link) GetSrcNeuronID() string {
@@ -69,15 +76,34 @@ func (l *link) IsEntryLink() bool {
 }
 
 func (l *link) IsEndLink() bool {
-	return l.dest == core.EndLinkTo
+	return core.IsEndNeuronID(l.dest)
+}
+
+func (l *link) IsOptional() bool {
+	return l.optional
+}
+
+func (l *link) SetOptional(optional bool) {
+	l.optional = optional
+}
+
+func (l *link) GetOutcome() processor.Outcome {
+	return l.outcome
+}
+
+func (l *link) OnlyOnOutcome(outcome processor.Outcome) {
+	l.outcome = outcome
 }
 
 func (l *link) deepCopy() *link {
 	return &link{
-		id:     l.id,
-		labels: utils.LabelsDeepCopy(l.labels),
-		src:    l.src,
-		dest:   l.dest,
+		id:       l.id,
+		labels:   utils.LabelsDeepCopy(l.labels),
+		src:      l.src,
+		dest:     l.dest,
+		optional: l.optional,
+		outcome:  l.outcome,
+		seq:      l.seq,
 	}
 }
 
@@ -85,5 +111,7 @@ func (l *link) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("id", l.id).
 		Any("labels", l.labels).
 		Str("src", l.src).
-		Str("dest", l.dest)
+		Str("dest", l.dest).
+		Bool("optional", l.optional).
+		Str("outcome", string(l.outcome))
 }
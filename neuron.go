@@ -2,6 +2,9 @@ package rModel
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/Rovanta/rmodel/core"
@@ -18,14 +21,15 @@ func newNeuron(p processor.Processor) *neuron {
 		triggerGroups: make(triggerGroups),
 		castGroups:    make(castGroups),
 		selector:      &processor.DefaultSelector{},
+		groupAliases:  make(map[string]string),
 	}
 
 	return n
 }
 
-func newEndNeuron() *neuron {
+func newEndNeuron(neuronID string) *neuron {
 	n := &neuron{
-		id:            core.EndNeuronID,
+		id:            neuronID,
 		labels:        make(map[string]string),
 		processor:     &processor.EmptyProcessor{},
 		triggerGroups: make(triggerGroups),
@@ -49,24 +53,131 @@ type neuron struct {
 	castGroups castGroups
 	// After neuron runs successfully, use Selector to decide which propagation group to transmit to.
 	selector processor.Selector
+	// The selector is bound explicitly, even a processor.DefaultSelector, so the default selector of the brain does not apply
+	selectorBound bool
+	// Aliases of propagation group, a selector returning the alias casts to the underlying group
+	// key: alias, value: group Name
+	groupAliases map[string]string
+	// Names of the trigger groups added by AddNamedTriggerGroup, the keys of other groups are generated
+	namedTriggerGroups map[string]struct{}
+	// Trigger evaluator replaces the trigger groups to decide whether Neuron is activated, nil to use the trigger groups
+	triggerEvaluator processor.TriggerEvaluator
+	// Memory keys required to activate Neuron, besides the trigger
+	requiredMemory []any
+	// Times Neuron can be activated again in a run, 0 for unbounded but no self-link
+	maxRevisits int
+	// Neuron runs at most once per run, the triggers satisfied again are ignored
+	runOnce bool
+	// Guard consulted before Neuron fires again in a run, the trigger is ignored if it returns false
+	refireGuard func(bcr processor.BrainContextReader) bool
+	// Priorities of the trigger groups satisfied at once, the highest fires Neuron, key: trigger group key
+	triggerGroupPriorities map[string]int
+	// Window from the first signal arrived to fire Neuron with partial inputs, and the TriggeredBy of the firing
+	triggerTimeout time.Duration
+	timeoutGroup   string
+	// Resolvers of the memory keys written by the branches joining at Neuron, key: memory key
+	mergeResolvers map[string]core.MemoryMergeResolver
+	// Resolvers are called when only one branch wrote the key
+	mergeSingleWriter bool
+	// Tags added to the labels of the metrics of Neuron, unlike the labels which are free-form
+	metricTags map[string]string
+	// Values the processor of Neuron reads for the memory keys which are not set, key: memory key
+	inputDefaults map[string]interface{}
+	// Names of the cast groups allowed to be empty by AllowEmptyCastGroup
+	emptyCastGroups map[string]struct{}
+	// sequence of Neuron in the blueprint, see Canonicalize
+	seq int
 }
 
 func (n *neuron) deepCopy() *neuron {
 	return &neuron{
-		id:            n.id,
-		labels:        utils.LabelsDeepCopy(n.labels),
-		processor:     n.processor,
-		triggerGroups: n.triggerGroups.deepCopy(),
-		castGroups:    n.castGroups.deepCopy(),
-		selector:      n.selector,
+		id:                     n.id,
+		labels:                 utils.LabelsDeepCopy(n.labels),
+		processor:              n.processor,
+		triggerGroups:          n.triggerGroups.deepCopy(),
+		castGroups:             n.castGroups.deepCopy(),
+		selector:               n.selector,
+		selectorBound:          n.selectorBound,
+		groupAliases:           utils.LabelsDeepCopy(n.groupAliases),
+		namedTriggerGroups:     copySet(n.namedTriggerGroups),
+		triggerEvaluator:       n.triggerEvaluator,
+		requiredMemory:         append([]any(nil), n.requiredMemory...),
+		maxRevisits:            n.maxRevisits,
+		runOnce:                n.runOnce,
+		refireGuard:            n.refireGuard,
+		triggerGroupPriorities: copyPriorities(n.triggerGroupPriorities),
+		triggerTimeout:         n.triggerTimeout,
+		timeoutGroup:           n.timeoutGroup,
+		mergeResolvers:         copyResolvers(n.mergeResolvers),
+		mergeSingleWriter:      n.mergeSingleWriter,
+		metricTags:             utils.LabelsDeepCopy(n.metricTags),
+		inputDefaults:          copyDefaults(n.inputDefaults),
+		emptyCastGroups:        copySet(n.emptyCastGroups),
+		seq:                    n.seq,
 	}
 }
 
+func copySet(set map[string]struct{}) map[string]struct{} {
+	newSet := make(map[string]struct{}, len(set))
+	for k := range set {
+		newSet[k] = struct{}{}
+	}
+
+	return newSet
+}
+
+func copyResolvers(resolvers map[string]core.MemoryMergeResolver) map[string]core.MemoryMergeResolver {
+	newMap := make(map[string]core.MemoryMergeResolver, len(resolvers))
+	for k, v := range resolvers {
+		newMap[k] = v
+	}
+
+	return newMap
+}
+
+func copyDefaults(defaults map[string]interface{}) map[string]interface{} {
+	if defaults == nil {
+		return nil
+	}
+	newMap := make(map[string]interface{}, len(defaults))
+	for k, v := range defaults {
+		newMap[k] = v
+	}
+
+	return newMap
+}
+
 func (n *neuron) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("id", n.id).
 		Interface("labels", n.labels).
 		Interface("triggerGroups", n.triggerGroups).
+		Str("trigger", n.triggerGroups.format(n.namedTriggerGroups)).
 		Interface("castGroups", n.castGroups.format())
+	if n.triggerEvaluator != nil {
+		e.Str("triggerEvaluator", processor.KindOf(n.triggerEvaluator))
+	}
+	if len(n.requiredMemory) != 0 {
+		e.Interface("requiredMemory", n.requiredMemory)
+	}
+	if len(n.inputDefaults) != 0 {
+		e.Interface("inputDefaults", n.inputDefaults)
+	}
+	if n.runOnce {
+		e.Bool("runOnce", true)
+	}
+	if n.refireGuard != nil {
+		e.Bool("refireGuard", true)
+	}
+	if len(n.triggerGroupPriorities) != 0 {
+		priorities := zerolog.Dict()
+		for key, priority := range n.triggerGroupPriorities {
+			priorities.Int(key, priority)
+		}
+		e.Dict("triggerGroupPriorities", priorities)
+	}
+	if n.triggerTimeout > 0 {
+		e.Dur("triggerTimeout", n.triggerTimeout).Str("timeoutGroup", n.timeoutGroup)
+	}
 }
 
 type castGroups map[string]map[string]struct{}
@@ -93,6 +204,8 @@ func (cgs castGroups) format() map[string][]string {
 		for innerKey := range value {
 			newSlice = append(newSlice, innerKey)
 		}
+		// sorted, so the links of a group are cast in a stable order
+		sort.Strings(newSlice)
 
 		newMap[key] = newSlice
 	}
@@ -114,6 +227,29 @@ func (tgs triggerGroups) deepCopy() triggerGroups {
 	return newGs
 }
 
+// format renders the trigger groups as a boolean expression of link IDs, e.g. `(lA & lB) | lC`,
+// links in a group are AND'd and groups are OR'd. A named group is prefixed with its name, e.g. `fromA:(lA & lB)`.
+func (tgs triggerGroups) format(named map[string]struct{}) string {
+	exprs := make([]string, 0, len(tgs))
+	for key, group := range tgs {
+		links := make([]string, len(group))
+		copy(links, group)
+		sort.Strings(links)
+		expr := strings.Join(links, " & ")
+		_, isNamed := named[key]
+		if len(links) > 1 && (len(tgs) > 1 || isNamed) {
+			expr = "(" + expr + ")"
+		}
+		if isNamed {
+			expr = key + ":" + expr
+		}
+		exprs = append(exprs, expr)
+	}
+	sort.Strings(exprs)
+
+	return strings.Join(exprs, " | ")
+}
+
 func (n *neuron) GetID() string {
 	return n.id
 }
@@ -164,21 +300,170 @@ func (n *neuron) ListTriggerGroups() map[string][]string {
 	return n.triggerGroups.deepCopy()
 }
 
+// EffectiveTrigger is not in effect if a trigger evaluator is set.
+func (n *neuron) EffectiveTrigger() [][]string {
+	return n.triggerGroups.effective()
+}
+
+func (n *neuron) EffectiveTriggerExpression() string {
+	return formatTrigger(n.triggerGroups.effective())
+}
+
 func (n *neuron) ListCastGroups() map[string][]string {
 	return n.castGroups.format()
 }
 
+// CastGroupOf returns the first cast group by name if the link is in several
+func (n *neuron) CastGroupOf(linkID string) (string, bool) {
+	names := make([]string, 0, len(n.castGroups))
+	for name, group := range n.castGroups {
+		if _, ok := group[linkID]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+
+	return names[0], true
+}
+
+func (n *neuron) TriggerGroupsContaining(linkID string) []string {
+	keys := make([]string, 0)
+	for key, group := range n.triggerGroups {
+		for _, l := range group {
+			if l == linkID {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (n *neuron) ListCastGroupAliases() map[string]string {
+	return utils.LabelsDeepCopy(n.groupAliases)
+}
+
+// SetLabels sets a copy of the labels, so the caller changing the labels afterwards does not change the neuron
 func (n *neuron) SetLabels(labels map[string]string) {
-	n.labels = labels
+	n.labels = utils.LabelsDeepCopy(labels)
 }
 
 // After AddTriggerGroup in-link is connected to neuron, it forms a group by default, that is, an in-link is divided into a trigger group.
 // In other words, any in-link can trigger neuron by default.
+// effective returns the link IDs of the groups without the group keys, deduplicated and sorted
+func (tgs triggerGroups) effective() [][]string {
+	groups := make([][]string, 0, len(tgs))
+	for _, group := range tgs {
+		links := make([]string, 0, len(group))
+		for _, l := range group {
+			if !utils.SlicesContains(links, []string{l}) {
+				links = append(links, l)
+			}
+		}
+		sort.Strings(links)
+		groups = append(groups, links)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.Join(groups[i], " ") < strings.Join(groups[j], " ")
+	})
+
+	return groups
+}
+
+// formatTrigger renders groups of link IDs as a boolean expression, links in a group are AND'd and groups are OR'd
+func formatTrigger(groups [][]string) string {
+	exprs := make([]string, 0, len(groups))
+	for _, links := range groups {
+		expr := strings.Join(links, " & ")
+		if len(links) > 1 && len(groups) > 1 {
+			expr = "(" + expr + ")"
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return strings.Join(exprs, " | ")
+}
+
 // AddTriggerGroup is used to put specified links into the same trigger group.
-// If the newly divided trigger group contains the existing trigger group, the existing trigger group will be removed.
-// If the newly divided trigger group is included in the existing trigger group, the newly divided group will not be created.
+// If the newly divided trigger group is included in (or equal to) any existing trigger group, nothing changes and the new group will not be created.
+// Otherwise, every existing trigger group included in the newly divided trigger group is removed, and the new group is added.
 // Because only the largest trigger condition needs to be defined, smaller trigger conditions will be included. For example: when {A,B,C} is satisfied, {A,B} must be satisfied.
+// All existing groups are classified before any of them is changed, so the result does not depend on map iteration order.
 func (n *neuron) AddTriggerGroup(links ...core.Link) error {
+	return n.addTriggerGroup(utils.GenIDShort(), links...)
+}
+
+// AddTriggerGroupFromNeurons adds a trigger group of the in-links of the neuron from the source neurons, looked up
+// in the blueprint, in the order of the sources and then of the link IDs.
+func (n *neuron) AddTriggerGroupFromNeurons(bp core.Blueprint, sourceNeuronIDs ...string) error {
+	inLinks := bp.ListInLinks(n.id)
+	sort.Slice(inLinks, func(i, j int) bool {
+		return inLinks[i].GetID() < inLinks[j].GetID()
+	})
+	links := make([]core.Link, 0, len(inLinks))
+	for _, src := range sourceNeuronIDs {
+		found := false
+		for _, l := range inLinks {
+			if l.GetSrcNeuronID() == src {
+				links = append(links, l)
+				found = true
+			}
+		}
+		if !found {
+			return errors.ErrInLinkFromNeuronNotFound(src, n.id)
+		}
+	}
+
+	return n.AddTriggerGroup(links...)
+}
+
+// AddNamedTriggerGroup is the same as AddTriggerGroup, but the key of the new group is the name instead of a generated ID,
+// so it can be referenced by BrainContext.GetTriggeredBy, e.g. in a processor.TriggerGroupSelector.
+// The name of an existing group with other links can not be reused.
+func (n *neuron) AddNamedTriggerGroup(name string, links ...core.Link) error {
+	if name == "" {
+		return fmt.Errorf("group name is empty")
+	}
+	linkIDs := make([]string, 0, len(links))
+	for _, l := range links {
+		linkIDs = append(linkIDs, l.GetID())
+	}
+	if group, ok := n.triggerGroups[name]; ok {
+		if utils.SlicesContainEqual(group, linkIDs) {
+			return nil
+		}
+		return fmt.Errorf("trigger group %s of neuron %s already exists with links %v", name, n.id, group)
+	}
+	// an equal group is renamed, e.g. the trigger group of a single in-link
+	for key, group := range n.triggerGroups {
+		if utils.SlicesContainEqual(group, linkIDs) {
+			delete(n.triggerGroups, key)
+			n.triggerGroups[name] = group
+			n.markNamedTriggerGroup(name)
+			return nil
+		}
+	}
+	if err := n.addTriggerGroup(name, links...); err != nil {
+		return err
+	}
+	n.markNamedTriggerGroup(name)
+
+	return nil
+}
+
+func (n *neuron) markNamedTriggerGroup(name string) {
+	if n.namedTriggerGroups == nil {
+		n.namedTriggerGroups = make(map[string]struct{})
+	}
+	n.namedTriggerGroups[name] = struct{}{}
+}
+
+func (n *neuron) addTriggerGroup(key string, links ...core.Link) error {
 	if len(links) == 0 {
 		return nil
 	}
@@ -193,16 +478,23 @@ func (n *neuron) AddTriggerGroup(links ...core.Link) error {
 		newGroup = append(newGroup, l.GetID())
 	}
 
+	// classify existing groups first
+	subsets := make([]string, 0)
 	for key, group := range n.triggerGroups {
 		if utils.SlicesContains(group, newGroup) {
+			// new group is redundant
 			return nil
 		}
 		if utils.SlicesContains(newGroup, group) {
-			delete(n.triggerGroups, key)
+			subsets = append(subsets, key)
 		}
 	}
+	// remove groups included in the new group
+	for _, key := range subsets {
+		delete(n.triggerGroups, key)
+	}
 	// add new group
-	n.triggerGroups[utils.GenIDShort()] = newGroup
+	n.triggerGroups[key] = newGroup
 
 	return nil
 }
@@ -241,6 +533,36 @@ func (n *neuron) AddCastGroup(groupName string, links ...core.Link) error {
 	return nil
 }
 
+func (n *neuron) AllowEmptyCastGroup(groupName string) {
+	if n.emptyCastGroups == nil {
+		n.emptyCastGroups = make(map[string]struct{})
+	}
+	n.emptyCastGroups[groupName] = struct{}{}
+}
+
+// checkCastGroupAlias checks the alias can refer to the cast group of the neuron
+func (n *neuron) checkCastGroupAlias(alias, groupName string) error {
+	if alias == "" {
+		return fmt.Errorf("group alias is empty")
+	}
+	if _, ok := n.castGroups[groupName]; !ok {
+		return errors.ErrCastGroupNotFound(groupName, n.id)
+	}
+	if _, ok := n.castGroups[alias]; ok && alias != groupName {
+		return fmt.Errorf("group alias %s conflicts with cast group of neuron %s", alias, n.id)
+	}
+
+	return nil
+}
+
+// setCastGroupAlias should be called after checkCastGroupAlias
+func (n *neuron) setCastGroupAlias(alias, groupName string) {
+	if n.groupAliases == nil {
+		n.groupAliases = make(map[string]string)
+	}
+	n.groupAliases[alias] = groupName
+}
+
 func (n *neuron) BindCastGroupSelectFunc(selectFn func(bcr processor.BrainContextReader) string) {
 	n.bindCastGroupSelector(processor.NewFuncSelector(selectFn))
 }
@@ -251,6 +573,148 @@ func (n *neuron) BindCastGroupSelector(selector processor.Selector) {
 
 func (n *neuron) bindCastGroupSelector(selector processor.Selector) {
 	n.selector = selector
+	n.selectorBound = true
+}
+
+func (n *neuron) IsSelectorBound() bool {
+	return n.selectorBound
+}
+
+func (n *neuron) GetTriggerEvaluator() processor.TriggerEvaluator {
+	return n.triggerEvaluator
+}
+
+// SetTriggerEvaluator sets the evaluator deciding whether the neuron is activated instead of the trigger groups,
+// nil to use the trigger groups again.
+func (n *neuron) SetTriggerEvaluator(evaluator processor.TriggerEvaluator) {
+	n.triggerEvaluator = evaluator
+}
+
+func (n *neuron) GetRequiredMemory() []any {
+	return append([]any(nil), n.requiredMemory...)
+}
+
+// SetRequiredMemory sets the memory keys required to activate the neuron besides the trigger, none to clear them.
+func (n *neuron) SetRequiredMemory(keys ...any) {
+	n.requiredMemory = append([]any(nil), keys...)
+}
+
+func (n *neuron) GetInputDefaults() map[string]interface{} {
+	return copyDefaults(n.inputDefaults)
+}
+
+// SetInputDefaults sets the values the processor of the neuron reads for the memory keys which are not set, nil to clear them.
+func (n *neuron) SetInputDefaults(defaults map[string]interface{}) {
+	n.inputDefaults = copyDefaults(defaults)
+}
+
+func (n *neuron) GetMaxRevisits() int {
+	return n.maxRevisits
+}
+
+// SetMaxRevisits sets the times the neuron can be activated again in a run, which allows a link to itself.
+func (n *neuron) SetMaxRevisits(maxRevisits int) {
+	n.maxRevisits = maxRevisits
+}
+
+func (n *neuron) GetRunOnce() bool {
+	return n.runOnce
+}
+
+// SetRunOnce sets whether the neuron runs at most once per run, the triggers satisfied again in the run are ignored.
+func (n *neuron) SetRunOnce(runOnce bool) {
+	n.runOnce = runOnce
+}
+
+func (n *neuron) GetRefireGuard() func(bcr processor.BrainContextReader) bool {
+	return n.refireGuard
+}
+
+// SetRefireGuard sets the guard consulted before the neuron fires again in a run, a false ignores the trigger
+// and ends the loop. nil fn fires again always.
+func (n *neuron) SetRefireGuard(fn func(bcr processor.BrainContextReader) bool) {
+	n.refireGuard = fn
+}
+
+func (n *neuron) GetTriggerGroupPriorities() map[string]int {
+	return copyPriorities(n.triggerGroupPriorities)
+}
+
+// SetTriggerGroupPriority sets the priority of the trigger group, 0 by default. The group needs not exist yet,
+// e.g. a named trigger group added afterwards.
+func (n *neuron) SetTriggerGroupPriority(key string, priority int) {
+	if n.triggerGroupPriorities == nil {
+		n.triggerGroupPriorities = make(map[string]int)
+	}
+	n.triggerGroupPriorities[key] = priority
+}
+
+func copyPriorities(priorities map[string]int) map[string]int {
+	if priorities == nil {
+		return nil
+	}
+	newMap := make(map[string]int, len(priorities))
+	for key, priority := range priorities {
+		newMap[key] = priority
+	}
+
+	return newMap
+}
+
+func (n *neuron) GetTriggerTimeout() (time.Duration, string) {
+	return n.triggerTimeout, n.timeoutGroup
+}
+
+// SetTriggerTimeout sets the window from the first signal arrived to fire the neuron with the signals arrived,
+// if the trigger is not satisfied within it. The processor gets onTimeoutGroup by GetTriggeredBy then. Zero d disables it.
+func (n *neuron) SetTriggerTimeout(d time.Duration, onTimeoutGroup string) {
+	n.triggerTimeout = d
+	n.timeoutGroup = onTimeoutGroup
+}
+
+func (n *neuron) GetMemoryMergeResolvers() map[string]core.MemoryMergeResolver {
+	return copyResolvers(n.mergeResolvers)
+}
+
+// SetMemoryMergeResolver sets the resolver merging the values of the memory key written by the branches joining at
+// the neuron, nil to remove it.
+func (n *neuron) SetMemoryMergeResolver(key string, fn func(values []interface{}) interface{}) {
+	if fn == nil {
+		delete(n.mergeResolvers, key)
+		return
+	}
+	if n.mergeResolvers == nil {
+		n.mergeResolvers = make(map[string]core.MemoryMergeResolver)
+	}
+	n.mergeResolvers[key] = fn
+}
+
+func (n *neuron) GetMergeSingleWriter() bool {
+	return n.mergeSingleWriter
+}
+
+// SetMergeSingleWriter sets whether the memory merge resolvers are called when only one branch wrote the key.
+func (n *neuron) SetMergeSingleWriter(merge bool) {
+	n.mergeSingleWriter = merge
+}
+
+func (n *neuron) GetMetricTags() map[string]string {
+	return utils.LabelsDeepCopy(n.metricTags)
+}
+
+// SetMetricTags sets the tags added to the labels of the metrics of the neuron, nil to clear them.
+func (n *neuron) SetMetricTags(tags map[string]string) error {
+	if len(tags) > core.MaxMetricTags {
+		return errors.ErrInvalidMetricTags(n.id, fmt.Sprintf("%d tags, at most %d", len(tags), core.MaxMetricTags))
+	}
+	for key := range tags {
+		if key == core.MetricLabelNeuron || key == core.MetricLabelGroup {
+			return errors.ErrInvalidMetricTags(n.id, fmt.Sprintf("tag %s overwrites the label of the metrics", key))
+		}
+	}
+	n.metricTags = utils.LabelsDeepCopy(tags)
+
+	return nil
 }
 
 func (n *neuron) addInLink(linkID string) {
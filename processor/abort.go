@@ -0,0 +1,16 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAbortRun returned by a processor aborts the whole run, instead of failing the neuron only.
+// The brain stops scheduling at once, and the run error is the returned error.
+// Neurons still processing are cancelled: their memory changes are rejected and their results discarded.
+var ErrAbortRun = errors.New("abort run")
+
+// AbortRun returns an error wrapping ErrAbortRun with the reason.
+func AbortRun(reason string) error {
+	return fmt.Errorf("%w: %s", ErrAbortRun, reason)
+}
@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrAssertionFailed is wrapped by the error of an AssertProcessor on a failed assertion
+var ErrAssertionFailed = errors.New("assertion failed")
+
+type AssertOperator string
+
+const (
+	// AssertEq the memory equals the expected value, numbers of different types are compared by value
+	AssertEq AssertOperator = "eq"
+	// AssertNe the memory does not equal the expected value
+	AssertNe AssertOperator = "ne"
+	// AssertGt the memory is greater than the expected value, both should be numbers or strings
+	AssertGt AssertOperator = "gt"
+	// AssertLt the memory is less than the expected value, both should be numbers or strings
+	AssertLt AssertOperator = "lt"
+	// AssertExists the memory exists, the expected value is ignored
+	AssertExists AssertOperator = "exists"
+)
+
+// Assertion asserts the memory of Key against the Expected value by the operator.
+type Assertion struct {
+	Key      string
+	Op       AssertOperator
+	Expected any
+}
+
+func (a Assertion) String() string {
+	if a.Op == AssertExists {
+		return fmt.Sprintf("%s exists", a.Key)
+	}
+	return fmt.Sprintf("%s %s %v", a.Key, a.Op, a.Expected)
+}
+
+// NewAssertProcessor new processor checks the assertions in order, and returns an error naming the first failed one,
+// so the neuron fails and does not cast.
+func NewAssertProcessor(asserts []Assertion) *AssertProcessor {
+	a := make([]Assertion, len(asserts))
+	copy(a, asserts)
+
+	return &AssertProcessor{
+		asserts: a,
+	}
+}
+
+type AssertProcessor struct {
+	asserts []Assertion
+}
+
+func (p *AssertProcessor) Process(ctx BrainContext) error {
+	for _, a := range p.asserts {
+		if !ctx.ExistMemory(a.Key) {
+			return fmt.Errorf("%w: %s, memory %s not found", ErrAssertionFailed, a, a.Key)
+		}
+		if a.Op == AssertExists {
+			continue
+		}
+		actual := ctx.GetMemory(a.Key)
+		ok, err := assert(a.Op, actual, a.Expected)
+		if err != nil {
+			return fmt.Errorf("assertion %s error: %w", a, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s, got %v", ErrAssertionFailed, a, actual)
+		}
+	}
+
+	return nil
+}
+
+func (p *AssertProcessor) Kind() string {
+	return "assert"
+}
+
+func (p *AssertProcessor) Clone() Processor {
+	asserts := make([]Assertion, len(p.asserts))
+	copy(asserts, p.asserts)
+	return &AssertProcessor{
+		asserts: asserts,
+	}
+}
+
+func assert(op AssertOperator, actual, expected any) (bool, error) {
+	switch op {
+	case AssertEq:
+		return assertEqual(actual, expected), nil
+	case AssertNe:
+		return !assertEqual(actual, expected), nil
+	case AssertGt, AssertLt:
+		cmp, err := assertCompare(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		if op == AssertGt {
+			return cmp > 0, nil
+		}
+		return cmp < 0, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func assertEqual(actual, expected any) bool {
+	a, aok := toFloat64(actual)
+	e, eok := toFloat64(expected)
+	if aok && eok {
+		return a == e
+	}
+
+	return reflect.DeepEqual(actual, expected)
+}
+
+// assertCompare compares numbers by value and strings lexically
+func assertCompare(actual, expected any) (int, error) {
+	if a, ok := toFloat64(actual); ok {
+		e, ok := toFloat64(expected)
+		if !ok {
+			return 0, fmt.Errorf("can not compare number %v with %T", actual, expected)
+		}
+		switch {
+		case a > e:
+			return 1, nil
+		case a < e:
+			return -1, nil
+		}
+		return 0, nil
+	}
+	if a, ok := actual.(string); ok {
+		e, ok := expected.(string)
+		if !ok {
+			return 0, fmt.Errorf("can not compare string %q with %T", a, expected)
+		}
+		switch {
+		case a > e:
+			return 1, nil
+		case a < e:
+			return -1, nil
+		}
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("can not compare %T, should be a number or a string", actual)
+}
+
+func toFloat64(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownSignal the signal is not one of the signals the barrier waits for
+	ErrUnknownSignal = errors.New("unknown signal")
+	// ErrBarrierCancelled the context of the run is done before every signal of the barrier arrived,
+	// e.g. the run is aborted or past its deadline
+	ErrBarrierCancelled = errors.New("barrier cancelled")
+)
+
+// NewBarrierProcessor new processor waits until every named external signal arrived, e.g. the approvals of several
+// people or the callbacks of several systems, delivered by Signal. The payload of each signal is set to the memory
+// with the signal name as the key, for the downstream neurons. The signals are consumed by the process, a signal
+// arrived before the process is kept for it.
+// The process returns ErrBarrierCancelled once the context of the run is done before, with the missing signals.
+//
+// Clones share the signals, so Signal reaches the barrier of the cloned brains of RunBatch too.
+func NewBarrierProcessor(signalNames []string) *BarrierProcessor {
+	names := make([]string, 0, len(signalNames))
+	seen := make(map[string]bool, len(signalNames))
+	for _, name := range signalNames {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return &BarrierProcessor{
+		barrier: &barrier{
+			names:    names,
+			received: make(map[string]interface{}),
+			arrived:  make(chan struct{}),
+		},
+	}
+}
+
+type BarrierProcessor struct {
+	// shared by clones
+	barrier *barrier
+}
+
+type barrier struct {
+	mu sync.Mutex
+	// sorted signal names
+	names []string
+	// payloads of the signals arrived, key: signal name
+	received map[string]interface{}
+	// closed and renewed when a signal arrives
+	arrived chan struct{}
+}
+
+// Signal delivers the named signal with its payload, a signal arrived again replaces the payload.
+// Returns ErrUnknownSignal if the barrier does not wait for the signal.
+func (p *BarrierProcessor) Signal(name string, payload interface{}) error {
+	b := p.barrier
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.waitsFor(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownSignal, name)
+	}
+	b.received[name] = payload
+	close(b.arrived)
+	b.arrived = make(chan struct{})
+
+	return nil
+}
+
+func (p *BarrierProcessor) Process(ctx BrainContext) error {
+	b := p.barrier
+	for {
+		b.mu.Lock()
+		missing := b.missing()
+		if len(missing) == 0 {
+			kv := make([]interface{}, 0, 2*len(b.names))
+			for _, name := range b.names {
+				kv = append(kv, name, b.received[name])
+			}
+			b.received = make(map[string]interface{})
+			b.mu.Unlock()
+			return ctx.SetMemory(kv...)
+		}
+		arrived := b.arrived
+		b.mu.Unlock()
+
+		select {
+		case <-arrived:
+		case <-ctx.Context().Done():
+			return fmt.Errorf("%w: missing signals %v: %v", ErrBarrierCancelled, missing, ctx.Context().Err())
+		}
+	}
+}
+
+// Missing returns the sorted names of the signals not arrived yet
+func (p *BarrierProcessor) Missing() []string {
+	b := p.barrier
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.missing()
+}
+
+func (p *BarrierProcessor) Kind() string {
+	return "barrier"
+}
+
+func (p *BarrierProcessor) Clone() Processor {
+	return &BarrierProcessor{barrier: p.barrier}
+}
+
+// missing should be called with mu locked
+func (b *barrier) missing() []string {
+	missing := make([]string, 0)
+	for _, name := range b.names {
+		if _, ok := b.received[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+func (b *barrier) waitsFor(name string) bool {
+	i := sort.SearchStrings(b.names, name)
+	return i < len(b.names) && b.names[i] == name
+}
@@ -1,5 +1,15 @@
 package processor
 
+import (
+	"context"
+	"io"
+	"math/rand"
+)
+
+// IdempotencyMemoryKey is the memory key of the base of BrainContext.IdempotencyKey, e.g. the ID of the request served by the run.
+// Replays and reruns with the same base get the same idempotency keys.
+const IdempotencyMemoryKey = "idempotency_key"
+
 type BrainContext interface {
 	// SetMemory set memories for brain, one key value pair is one memory.
 	// memory will lazy initial util `SetMemory` or any link trig
@@ -12,8 +22,29 @@ type BrainContext interface {
 	DeleteMemory(key interface{})
 	// ClearMemory clear all memories
 	ClearMemory()
+	// GetInternalMemory get the internal state of a processor of rModel, e.g. the markers of Once, by a key
+	// under the reserved prefix of memory keys, see core.ReservedMemoryKeyPrefix. Not for user processors.
+	GetInternalMemory(key string) (interface{}, bool)
+	// SetInternalMemory set the internal state of a processor of rModel by a key under the reserved prefix.
+	// Unlike SetMemory, it is not audited, not in the snapshots of the memory, e.g. Result.FinalMemory,
+	// and not merged at the joins of the branches. It is cleared with the memory. Not for user processors.
+	SetInternalMemory(key string, value interface{}) error
+	// SetStream set a memory backed by the reader, e.g. a large file, instead of the data, see Stream.
+	// The brain closes it once the run is over, if its reader did not.
+	SetStream(key interface{}, r io.ReadCloser) error
+	// GetStream get the reader of the stream memory set by SetStream, the caller closes it. Reading consumes the stream,
+	// the next GetStream of the key fails with ErrStreamConsumed. It fails with ErrNotStream if the memory is not a stream.
+	GetStream(key interface{}) (io.ReadCloser, error)
 	// GetCurrentNeuronID get current neuron id
 	GetCurrentNeuronID() string
+	// GetTriggeredBy get the key of the trigger group which activated the current neuron
+	GetTriggeredBy() string
+	// Rand get the random source of the current run, seeded once per run, see WithRandSeed of the brain.
+	// Use it instead of the global source, so a run is reproducible. It is safe for concurrent use, except Read.
+	Rand() *rand.Rand
+	// IdempotencyKey get the key identifying the current process of the neuron, the same for the retries of the process.
+	// It is derived from memory IdempotencyMemoryKey if set, e.g. by the entry of the run, otherwise from the brain and the run.
+	IdempotencyKey() string
 	// GetCurrentNeuronLabels get current neuron labels
 	GetCurrentNeuronLabels() map[string]string
 	// GetBrainID get brain id
@@ -22,8 +53,37 @@ type BrainContext interface {
 	GetBrainLabels() map[string]string
 	// ContinueCast keep current process running, and continue cast
 	ContinueCast()
-	// TODO Context extends context.Context
-	//context.Context
+	// SetCastPayload set the payload delivered only along the links of the cast group, when the neuron casts the group,
+	// e.g. a summary for one branch and the details for another. The links of a group without payload deliver nil.
+	SetCastPayload(group string, payload interface{}) error
+	// GetPayloads get the payloads of the signals which activated the current neuron, key: in-link ID.
+	// Only the in-links whose signals arrived are there: all links of the trigger group which activated the neuron,
+	// or the links arrived when the trigger evaluator is satisfied or the trigger timeout fires, and the optional links arrived.
+	GetPayloads() map[string]interface{}
+	// GetAbsentInputs get the IDs of the in-links without signal when the current neuron fired by the trigger evaluator
+	// or the trigger timeout, sorted. Empty for a trigger group, which fires once all of its links arrived, and optional links are left out.
+	GetAbsentInputs() []string
+	// Context get the context of the current run, it is done once the run is aborted or over,
+	// pass it to the calls of the process so they are cancelled with the run.
+	Context() context.Context
+	// RegisterCompensation register a function undoing the side effect of the current process, e.g. a refund for a charge.
+	// Once the run fails, the compensations are called in reverse order of registration, see CompensationPolicy of the brain.
+	// A failed compensation does not stop the others. Registrations after the run is aborted are ignored.
+	RegisterCompensation(fn func(ctx context.Context) error)
+	// Defer register a function releasing a resource of the current process, e.g. closing a connection or removing a temp file.
+	// Once the run is over, whether it succeeds, fails or is cancelled, the deferred functions are called in reverse order
+	// of registration, after the compensations. A panic of a function is logged and does not stop the others.
+	// The function is called at once if the run is already over.
+	Defer(fn func())
+	// Local get the scratch store of the current process of the neuron, e.g. the intermediate state of a computation.
+	// It is discarded once the process returns, it never reaches the memory, and is not visible to the downstream neurons.
+	Local() *LocalStore
+	// Yield lets the neurons ready to run take the worker of the current process before it resumes, e.g. between the
+	// chunks of a long CPU-bound computation, so it does not starve them with few neuron workers. It returns once a
+	// worker is free again, or at once if none is waiting. The time waiting counts toward the timeout and the deadline
+	// budget of the current process, like any other time it takes. Most processors do not need it, the neurons waiting
+	// for I/O free their goroutine anyway.
+	Yield()
 }
 
 type BrainContextReader interface {
@@ -33,6 +93,14 @@ type BrainContextReader interface {
 	ExistMemory(key interface{}) bool
 	// GetCurrentNeuronID get current neuron id
 	GetCurrentNeuronID() string
+	// GetTriggeredBy get the key of the trigger group which activated the current neuron
+	GetTriggeredBy() string
+	// Rand get the random source of the current run, seeded once per run, see WithRandSeed of the brain.
+	// Use it instead of the global source, so a run is reproducible. It is safe for concurrent use, except Read.
+	Rand() *rand.Rand
+	// IdempotencyKey get the key identifying the current process of the neuron, the same for the retries of the process.
+	// It is derived from memory IdempotencyMemoryKey if set, e.g. by the entry of the run, otherwise from the brain and the run.
+	IdempotencyKey() string
 	// TODO Context extends context.Context
 	//context.Context
 }
@@ -0,0 +1,140 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen returned by a circuit breaker processor when the circuit is open, the process is short-circuited.
+// Route it to a fallback cast group with an ErrorAwareSelector.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitState is the state of a circuit breaker processor.
+type CircuitState string
+
+const (
+	// CircuitClosed the processor runs, consecutive failures are counted
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen the processor is short-circuited with ErrCircuitOpen until the cooldown is over
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen the cooldown is over, one probe process decides whether the circuit closes or opens again
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig configures a circuit breaker processor, see WithCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold consecutive failures which open the circuit, 1 if not positive
+	FailureThreshold int
+	// Cooldown the circuit stays open before a probe
+	Cooldown time.Duration
+	// Now the clock, time.Now if nil
+	Now func() time.Time
+}
+
+// WithCircuitBreaker new processor runs p behind a circuit breaker, e.g. for a neuron calling an unreliable dependency.
+// After FailureThreshold consecutive failures of p, the circuit opens: the process returns ErrCircuitOpen without running p,
+// until the Cooldown is over. Then one probe runs p, its success closes the circuit and its failure opens it again,
+// the other processes meanwhile are short-circuited.
+//
+// Clones share the circuit, so the state is kept across the runs of a brain and the cloned brains of RunBatch.
+func WithCircuitBreaker(p Processor, cfg CircuitBreakerConfig) *CircuitBreakerProcessor {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &CircuitBreakerProcessor{
+		p:       p,
+		circuit: &circuit{cfg: cfg, state: CircuitClosed},
+	}
+}
+
+type CircuitBreakerProcessor struct {
+	p Processor
+	// shared by clones
+	circuit *circuit
+}
+
+type circuit struct {
+	mu       sync.Mutex
+	cfg      CircuitBreakerConfig
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// a probe is running in the half-open state
+	probing bool
+}
+
+func (p *CircuitBreakerProcessor) Process(ctx BrainContext) (err error) {
+	c := p.circuit
+	c.mu.Lock()
+	if c.state == CircuitOpen {
+		if wait := c.cfg.Cooldown - c.cfg.Now().Sub(c.openedAt); wait > 0 {
+			c.mu.Unlock()
+			return fmt.Errorf("%w: retry after %s", ErrCircuitOpen, wait)
+		}
+		c.state = CircuitHalfOpen
+	}
+	if c.state == CircuitHalfOpen {
+		if c.probing {
+			c.mu.Unlock()
+			return fmt.Errorf("%w: probe in progress", ErrCircuitOpen)
+		}
+		c.probing = true
+	}
+	c.mu.Unlock()
+
+	// a panic of p is a failure, it is re-panicked for the brain to recover
+	panicked := true
+	defer func() {
+		c.record(err == nil && !panicked)
+	}()
+	err = p.p.Process(ctx)
+	panicked = false
+
+	return err
+}
+
+// record ends the probe if any, and counts the result of a process of p
+func (c *circuit) record(succeeded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+	if succeeded {
+		c.state = CircuitClosed
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.cfg.FailureThreshold {
+		c.state = CircuitOpen
+		c.openedAt = c.cfg.Now()
+	}
+}
+
+// State returns the current state of the circuit, an open circuit is reported half-open once the cooldown is over.
+func (p *CircuitBreakerProcessor) State() CircuitState {
+	c := p.circuit
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CircuitOpen && c.cfg.Now().Sub(c.openedAt) >= c.cfg.Cooldown {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+func (p *CircuitBreakerProcessor) Kind() string {
+	return "circuit_breaker"
+}
+
+func (p *CircuitBreakerProcessor) Clone() Processor {
+	return &CircuitBreakerProcessor{
+		p:       p.p.Clone(),
+		circuit: p.circuit,
+	}
+}
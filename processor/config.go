@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BuildResolver is implemented by the processors resolving their state once as the brain is built, e.g. ConfigProcessor.
+type BuildResolver interface {
+	// ResolveAtBuild is called by the brain build for the processor of each neuron, its error fails the build
+	ResolveAtBuild() error
+}
+
+// RunSeeder is implemented by the processors setting memories at the start of every run, e.g. ConfigProcessor.
+type RunSeeder interface {
+	// RunSeed returns the memories the brain sets at the start of a run, in pairs of key and value
+	RunSeed() []interface{}
+}
+
+// NewConfigProcessor new processor for the static config returned by resolver, one memory for each key.
+// The resolver is called once as the brain is built, see BuildResolver, a resolution error fails the build.
+// The brain sets the config to memory at the start of every run, see RunSeeder, before any neuron processes,
+// and the neuron of the processor sets it again when it processes. The resolution is shared by all runs and clones,
+// e.g. the brains of RunBatch, treat the values as read-only.
+func NewConfigProcessor(resolver func() (map[string]interface{}, error)) *ConfigProcessor {
+	return &ConfigProcessor{config: &resolvedConfig{resolver: resolver}}
+}
+
+type ConfigProcessor struct {
+	// shared by clones
+	config *resolvedConfig
+}
+
+// resolvedConfig resolves the config once
+type resolvedConfig struct {
+	once     sync.Once
+	resolver func() (map[string]interface{}, error)
+	// resolved config in order of keys
+	keysAndValues []interface{}
+	err           error
+}
+
+func (c *resolvedConfig) resolve() error {
+	c.once.Do(func() {
+		if c.resolver == nil {
+			return
+		}
+		config, err := c.resolver()
+		if err != nil {
+			c.err = fmt.Errorf("resolve config error: %w", err)
+			return
+		}
+		keys := make([]string, 0, len(config))
+		for key := range config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		c.keysAndValues = make([]interface{}, 0, 2*len(keys))
+		for _, key := range keys {
+			c.keysAndValues = append(c.keysAndValues, key, config[key])
+		}
+	})
+
+	return c.err
+}
+
+func (p *ConfigProcessor) ResolveAtBuild() error {
+	if p.config == nil {
+		return nil
+	}
+
+	return p.config.resolve()
+}
+
+// RunSeed is empty if the config is not resolved
+func (p *ConfigProcessor) RunSeed() []interface{} {
+	if p.config == nil || p.config.resolve() != nil {
+		return nil
+	}
+
+	return p.config.keysAndValues
+}
+
+// Process resolves the config if it is not yet, e.g. the processor is not in a brain
+func (p *ConfigProcessor) Process(ctx BrainContext) error {
+	if err := p.ResolveAtBuild(); err != nil {
+		return err
+	}
+	if keysAndValues := p.RunSeed(); len(keysAndValues) != 0 {
+		return ctx.SetMemory(keysAndValues...)
+	}
+
+	return nil
+}
+
+func (p *ConfigProcessor) Kind() string {
+	return "config"
+}
+
+func (p *ConfigProcessor) Clone() Processor {
+	return &ConfigProcessor{config: p.config}
+}
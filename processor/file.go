@@ -0,0 +1,134 @@
+package processor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// WriteFileFS writes files, the writing counterpart of fs.FS.
+type WriteFileFS interface {
+	WriteFile(name string, data []byte) error
+}
+
+// OSFS the file system of the operating system, names are os paths, relative to the working directory.
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (OSFS) WriteFile(name string, data []byte) error {
+	return os.WriteFile(name, data, 0o644)
+}
+
+// NewFileReadProcessor new processor reads the file of the path in memory pathKey, and sets its bytes to memory outKey.
+// The file is read from OSFS.
+func NewFileReadProcessor(pathKey, outKey string) *FileReadProcessor {
+	return NewFileReadProcessorWithFS(OSFS{}, pathKey, outKey)
+}
+
+// NewFileReadProcessorWithFS new processor like NewFileReadProcessor, with the file read from fsys.
+func NewFileReadProcessorWithFS(fsys fs.FS, pathKey, outKey string) *FileReadProcessor {
+	return &FileReadProcessor{
+		fsys:    fsys,
+		pathKey: pathKey,
+		outKey:  outKey,
+	}
+}
+
+type FileReadProcessor struct {
+	fsys    fs.FS
+	pathKey string
+	outKey  string
+}
+
+func (p *FileReadProcessor) Process(ctx BrainContext) error {
+	path, err := pathFromMemory(ctx, p.pathKey)
+	if err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(p.fsys, path)
+	if err != nil {
+		return fmt.Errorf("read file %s error: %w", path, err)
+	}
+
+	return ctx.SetMemory(p.outKey, data)
+}
+
+func (p *FileReadProcessor) Kind() string {
+	return "file_read"
+}
+
+func (p *FileReadProcessor) Clone() Processor {
+	return &FileReadProcessor{
+		fsys:    p.fsys,
+		pathKey: p.pathKey,
+		outKey:  p.outKey,
+	}
+}
+
+// NewFileWriteProcessor new processor writes the memory dataKey to the file of the path in memory pathKey.
+// The data memory should be []byte or string. The file is written to OSFS.
+func NewFileWriteProcessor(pathKey, dataKey string) *FileWriteProcessor {
+	return NewFileWriteProcessorWithFS(OSFS{}, pathKey, dataKey)
+}
+
+// NewFileWriteProcessorWithFS new processor like NewFileWriteProcessor, with the file written to fsys.
+func NewFileWriteProcessorWithFS(fsys WriteFileFS, pathKey, dataKey string) *FileWriteProcessor {
+	return &FileWriteProcessor{
+		fsys:    fsys,
+		pathKey: pathKey,
+		dataKey: dataKey,
+	}
+}
+
+type FileWriteProcessor struct {
+	fsys    WriteFileFS
+	pathKey string
+	dataKey string
+}
+
+func (p *FileWriteProcessor) Process(ctx BrainContext) error {
+	path, err := pathFromMemory(ctx, p.pathKey)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch v := ctx.GetMemory(p.dataKey).(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		return fmt.Errorf("data memory %s not found", p.dataKey)
+	default:
+		return fmt.Errorf("data memory %s should be []byte or string, got %T", p.dataKey, v)
+	}
+	if err = p.fsys.WriteFile(path, data); err != nil {
+		return fmt.Errorf("write file %s error: %w", path, err)
+	}
+
+	return nil
+}
+
+func (p *FileWriteProcessor) Kind() string {
+	return "file_write"
+}
+
+func (p *FileWriteProcessor) Clone() Processor {
+	return &FileWriteProcessor{
+		fsys:    p.fsys,
+		pathKey: p.pathKey,
+		dataKey: p.dataKey,
+	}
+}
+
+func pathFromMemory(ctx BrainContext, pathKey string) (string, error) {
+	path, ok := ctx.GetMemory(pathKey).(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("path memory %s should be a non-empty string, got %v", pathKey, ctx.GetMemory(pathKey))
+	}
+
+	return path, nil
+}
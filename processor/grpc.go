@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+)
+
+// GRPCInvoker invokes unary gRPC methods, e.g. a *grpc.ClientConn, so the grpc dependency stays out of rModel.
+// Invoke fills resp with the response of the method, the full method name like "/pkg.Service/Method".
+type GRPCInvoker interface {
+	Invoke(ctx context.Context, method string, req, resp interface{}) error
+}
+
+// NewGRPCProcessor new processor invokes the method in memory methodKey with the request in memory reqKey,
+// and sets the response to memory respKey. The call is made with BrainContext.Context, so it is cancelled with the run.
+// The response is decoded into an interface{}, use NewGRPCProcessorWithResponse for invokers need a typed response, e.g. a proto message.
+func NewGRPCProcessor(invoker GRPCInvoker, methodKey, reqKey, respKey string) *GRPCProcessor {
+	return NewGRPCProcessorWithResponse(invoker, methodKey, reqKey, respKey, nil)
+}
+
+// NewGRPCProcessorWithResponse new processor like NewGRPCProcessor, with the response created by newResp for each call,
+// e.g. `func() interface{} { return &pb.Reply{} }`. The response created is set to memory respKey as it is.
+func NewGRPCProcessorWithResponse(invoker GRPCInvoker, methodKey, reqKey, respKey string,
+	newResp func() interface{}) *GRPCProcessor {
+	return &GRPCProcessor{
+		invoker:   invoker,
+		methodKey: methodKey,
+		reqKey:    reqKey,
+		respKey:   respKey,
+		newResp:   newResp,
+	}
+}
+
+type GRPCProcessor struct {
+	invoker   GRPCInvoker
+	methodKey string
+	reqKey    string
+	respKey   string
+	newResp   func() interface{}
+}
+
+func (p *GRPCProcessor) Process(ctx BrainContext) error {
+	method, ok := ctx.GetMemory(p.methodKey).(string)
+	if !ok || method == "" {
+		return fmt.Errorf("method memory %s should be a non-empty string, got %v", p.methodKey, ctx.GetMemory(p.methodKey))
+	}
+	if !ctx.ExistMemory(p.reqKey) {
+		return fmt.Errorf("request memory %s not found", p.reqKey)
+	}
+	req := ctx.GetMemory(p.reqKey)
+
+	if p.newResp == nil {
+		var resp interface{}
+		if err := p.invoker.Invoke(ctx.Context(), method, req, &resp); err != nil {
+			return fmt.Errorf("invoke grpc method %s error: %w", method, err)
+		}
+		return ctx.SetMemory(p.respKey, resp)
+	}
+	resp := p.newResp()
+	if err := p.invoker.Invoke(ctx.Context(), method, req, resp); err != nil {
+		return fmt.Errorf("invoke grpc method %s error: %w", method, err)
+	}
+
+	return ctx.SetMemory(p.respKey, resp)
+}
+
+func (p *GRPCProcessor) Kind() string {
+	return "grpc"
+}
+
+func (p *GRPCProcessor) Clone() Processor {
+	return NewGRPCProcessorWithResponse(p.invoker, p.methodKey, p.reqKey, p.respKey, p.newResp)
+}
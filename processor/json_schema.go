@@ -0,0 +1,347 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// SchemaCompiler compiles a JSON Schema into a SchemaValidator.
+// Implement it with a full-featured JSON Schema library when the BasicSchemaCompiler is not enough.
+type SchemaCompiler interface {
+	Compile(schema []byte) (SchemaValidator, error)
+}
+
+// SchemaValidator validates a JSON document against a compiled JSON Schema.
+type SchemaValidator interface {
+	Validate(document []byte) error
+}
+
+// NewJSONSchemaValidator new processor validates the memories of keys against the JSON Schema, compiled by BasicSchemaCompiler.
+// The memories are validated as one JSON object, the memory key is the property name, and missing memories are absent properties.
+// Process returns an error on violation, so the neuron fails and does not cast.
+// A malformed schema, or a schema with keywords BasicSchemaCompiler does not support, is an error.
+func NewJSONSchemaValidator(schema []byte, keys ...string) (*JSONSchemaValidatorProcessor, error) {
+	return NewJSONSchemaValidatorWithCompiler(&BasicSchemaCompiler{}, schema, keys...)
+}
+
+// NewJSONSchemaValidatorWithCompiler new processor like NewJSONSchemaValidator, with the schema compiled by the compiler.
+func NewJSONSchemaValidatorWithCompiler(compiler SchemaCompiler, schema []byte, keys ...string) (*JSONSchemaValidatorProcessor, error) {
+	validator, err := compiler.Compile(schema)
+	if err != nil {
+		return nil, fmt.Errorf("compile json schema error: %w", err)
+	}
+	k := make([]string, len(keys))
+	copy(k, keys)
+
+	return &JSONSchemaValidatorProcessor{
+		keys:      k,
+		validator: validator,
+	}, nil
+}
+
+type JSONSchemaValidatorProcessor struct {
+	keys []string
+	// validator of the compiled schema, shared by clones
+	validator SchemaValidator
+}
+
+func (p *JSONSchemaValidatorProcessor) Process(ctx BrainContext) error {
+	doc := make(map[string]interface{})
+	for _, key := range p.keys {
+		if ctx.ExistMemory(key) {
+			doc[key] = ctx.GetMemory(key)
+		}
+	}
+	document, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal memories %v error: %w", p.keys, err)
+	}
+	if err = p.validator.Validate(document); err != nil {
+		return fmt.Errorf("memories %v violate json schema: %w", p.keys, err)
+	}
+
+	return nil
+}
+
+func (p *JSONSchemaValidatorProcessor) Kind() string {
+	return "json_schema_validator"
+}
+
+func (p *JSONSchemaValidatorProcessor) Clone() Processor {
+	keys := make([]string, len(p.keys))
+	copy(keys, p.keys)
+	return &JSONSchemaValidatorProcessor{
+		keys:      keys,
+		validator: p.validator,
+	}
+}
+
+// BasicSchemaCompiler is a dependency-free compiler supporting a subset of JSON Schema:
+// type, enum, const, required, properties, additionalProperties (bool), items (schema), minimum, maximum,
+// minLength, maxLength, minItems, maxItems, and the annotations which do not affect validation, e.g. title and description.
+// Other keywords (e.g. pattern, $ref, anyOf) are rejected on Compile, so a schema is never partially enforced.
+type BasicSchemaCompiler struct{}
+
+func (c *BasicSchemaCompiler) Compile(schema []byte) (SchemaValidator, error) {
+	var s map[string]interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	if err := checkSchema(s, "$"); err != nil {
+		return nil, err
+	}
+
+	return &basicSchemaValidator{schema: s}, nil
+}
+
+// basicSchemaValidator validates documents against the schema compiled by BasicSchemaCompiler, it is read-only after Compile.
+type basicSchemaValidator struct {
+	schema map[string]interface{}
+}
+
+func (v *basicSchemaValidator) Validate(document []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return fmt.Errorf("invalid json document: %w", err)
+	}
+
+	return validateSchema(v.schema, doc, "$")
+}
+
+// basicSchemaKeywords are the keywords supported by BasicSchemaCompiler, with the check of the keyword value.
+var basicSchemaKeywords = map[string]func(value interface{}) bool{
+	"type":                 isSchemaType,
+	"enum":                 isJSONArray,
+	"const":                func(interface{}) bool { return true },
+	"required":             isStringArray,
+	"properties":           isJSONObject,
+	"additionalProperties": isJSONBool,
+	"items":                isJSONObject,
+	"minimum":              isJSONNumber,
+	"maximum":              isJSONNumber,
+	"minLength":            isJSONNumber,
+	"maxLength":            isJSONNumber,
+	"minItems":             isJSONNumber,
+	"maxItems":             isJSONNumber,
+	// annotations
+	"$schema":     isJSONString,
+	"$id":         isJSONString,
+	"$comment":    isJSONString,
+	"title":       isJSONString,
+	"description": isJSONString,
+	"default":     func(interface{}) bool { return true },
+	"examples":    isJSONArray,
+}
+
+// checkSchema checks all keywords of the schema and its subschemas are supported, with values of the right type.
+func checkSchema(schema map[string]interface{}, path string) error {
+	keywords := make([]string, 0, len(schema))
+	for k := range schema {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	for _, k := range keywords {
+		check, ok := basicSchemaKeywords[k]
+		if !ok {
+			return fmt.Errorf("%s: unsupported json schema keyword %q", path, k)
+		}
+		if !check(schema[k]) {
+			return fmt.Errorf("%s: invalid value of json schema keyword %q: %v", path, k, schema[k])
+		}
+	}
+
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, ok := props[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s.%s: json schema should be an object", path, name)
+			}
+			if err := checkSchema(propSchema, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if err := checkSchema(items, path+"[]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isSchemaType(value interface{}) bool {
+	if _, ok := value.(string); ok {
+		return true
+	}
+	return isStringArray(value)
+}
+
+func isStringArray(value interface{}) bool {
+	arr, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range arr {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isJSONArray(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
+
+func isJSONObject(value interface{}) bool {
+	_, ok := value.(map[string]interface{})
+	return ok
+}
+
+func isJSONBool(value interface{}) bool {
+	_, ok := value.(bool)
+	return ok
+}
+
+func isJSONNumber(value interface{}) bool {
+	_, ok := value.(float64)
+	return ok
+}
+
+func isJSONString(value interface{}) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if t, ok := schema["type"]; ok && !matchSchemaType(t, value) {
+		return fmt.Errorf("%s: expect type %v, got %s", path, t, jsonTypeOf(value))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: expect %v, got %v", path, c, value)
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, found := val[name]; !found {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		for name, propValue := range val {
+			propSchema, ok := props[name].(map[string]interface{})
+			if !ok {
+				if additional, isBool := schema["additionalProperties"].(bool); isBool && !additional {
+					return fmt.Errorf("%s: additional property %q is not allowed", path, name)
+				}
+				continue
+			}
+			if err := validateSchema(propSchema, propValue, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := checkSchemaBound(schema, "minItems", "maxItems", float64(len(val)), path, "items count"); err != nil {
+			return err
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if err := checkSchemaBound(schema, "minLength", "maxLength", float64(len([]rune(val))), path, "length"); err != nil {
+			return err
+		}
+	case float64:
+		if err := checkSchemaBound(schema, "minimum", "maximum", val, path, "value"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkSchemaBound(schema map[string]interface{}, minKey, maxKey string, n float64, path, what string) error {
+	if minV, ok := schema[minKey].(float64); ok && n < minV {
+		return fmt.Errorf("%s: %s %v is less than %s %v", path, what, n, minKey, minV)
+	}
+	if maxV, ok := schema[maxKey].(float64); ok && n > maxV {
+		return fmt.Errorf("%s: %s %v is greater than %s %v", path, what, n, maxKey, maxV)
+	}
+	return nil
+}
+
+func matchSchemaType(t interface{}, value interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return matchJSONType(tt, value)
+	case []interface{}:
+		for _, one := range tt {
+			if s, ok := one.(string); ok && matchJSONType(s, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchJSONType(t string, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	if t == "number" && actual == "integer" {
+		return true
+	}
+	return t == actual
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,44 @@
+package processor
+
+import "sync"
+
+// LocalStore is the scratch key/value store of one process of a neuron, see BrainContext.Local.
+// It is discarded once the process returns: it is not in the memory, the memory audit nor the result of the run,
+// and it is not visible to the downstream neurons, nor to the next process of the same neuron.
+// The zero value is an empty store, it is safe for concurrent use.
+type LocalStore struct {
+	mu     sync.Mutex
+	values map[interface{}]interface{}
+}
+
+// Set sets the value of the key
+func (s *LocalStore) Set(key, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[interface{}]interface{})
+	}
+	s.values[key] = value
+}
+
+// Get gets the value of the key, nil if it is not set
+func (s *LocalStore) Get(key interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// Exist indicates whether the key is set
+func (s *LocalStore) Exist(key interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.values[key]
+	return ok
+}
+
+// Delete deletes the key
+func (s *LocalStore) Delete(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"reflect"
+)
+
+// Named is implemented by processors and selectors with a stable kind name,
+// which is used to identify them in logs, metric labels and serialization.
+type Named interface {
+	Kind() string
+}
+
+// KindOf returns the kind of the processor or selector if it implements Named,
+// otherwise the Go type name, e.g. "MyProcessor" for *pkg.MyProcessor.
+func KindOf(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if named, ok := v.(Named); ok {
+		return named.Kind()
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+
+	return t.Name()
+}
@@ -0,0 +1,47 @@
+package processor
+
+// OnceMemoryKeyPrefix is the prefix of the internal memory keys recording the idempotency keys processed by Once,
+// under core.ReservedMemoryKeyPrefix, so they do not collide with the user keys. See BrainContext.GetInternalMemory.
+const OnceMemoryKeyPrefix = "__rmodel.once."
+
+// Once new processor runs p at most once successfully for each idempotency key, e.g. a payment under retries and replays.
+// The key is returned by keyFn, or BrainContext.IdempotencyKey if keyFn is nil. A processed key is recorded in the internal memory,
+// so the process is skipped once the key is seen, in this run or in a run with the same memory.
+// Concurrent processes with the same key are not deduplicated.
+func Once(p Processor, keyFn func(ctx BrainContextReader) string) *OnceProcessor {
+	return &OnceProcessor{
+		p:     p,
+		keyFn: keyFn,
+	}
+}
+
+type OnceProcessor struct {
+	p     Processor
+	keyFn func(ctx BrainContextReader) string
+}
+
+func (p *OnceProcessor) Process(ctx BrainContext) error {
+	key := ""
+	if p.keyFn != nil {
+		key = p.keyFn(ctx)
+	} else {
+		key = ctx.IdempotencyKey()
+	}
+	memKey := OnceMemoryKeyPrefix + key
+	if _, ok := ctx.GetInternalMemory(memKey); ok {
+		return nil
+	}
+	if err := p.p.Process(ctx); err != nil {
+		return err
+	}
+
+	return ctx.SetInternalMemory(memKey, true)
+}
+
+func (p *OnceProcessor) Kind() string {
+	return "once"
+}
+
+func (p *OnceProcessor) Clone() Processor {
+	return Once(p.p.Clone(), p.keyFn)
+}
@@ -0,0 +1,11 @@
+package processor
+
+// Outcome is the result of a process, a link can be gated by it, see core.Link.OnlyOnOutcome
+type Outcome string
+
+const (
+	// OutcomeSuccess the process returned no error
+	OutcomeSuccess Outcome = "success"
+	// OutcomeFailure the process returned an error
+	OutcomeFailure Outcome = "failure"
+)
@@ -20,6 +20,10 @@ func (p *FuncProcessor) Process(ctx BrainContext) error {
 	return p.processFn(ctx)
 }
 
+func (p *FuncProcessor) Kind() string {
+	return "func"
+}
+
 func (p *FuncProcessor) Clone() Processor {
 	return &FuncProcessor{
 		processFn: p.processFn,
@@ -32,6 +36,10 @@ func (p *EmptyProcessor) Process(ctx BrainContext) error {
 	return nil
 }
 
+func (p *EmptyProcessor) Kind() string {
+	return "empty"
+}
+
 func (p *EmptyProcessor) Clone() Processor {
 	return &EmptyProcessor{}
 }
@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"sort"
+	"sync"
+)
+
+// NewQuotaSelector new selector selects each cast group exactly quotas[group] times, e.g. a batch of 100 runs
+// with quotas {"a": 30, "b": 70} sends exactly 30 runs to "a". The groups are interleaved in a fixed schedule,
+// smooth weighted round-robin in order of the group names. The default cast group is selected once the quotas are used up.
+//
+// Clones share the quota state, so the runs of a RunBatch, on cloned brains, are coordinated. It is the quota of one batch:
+// construct a new selector for each batch, and never share it across unrelated batches or brains. Every Select takes
+// a quota, so the neuron should select once per run, and a failed run still takes its quota.
+func NewQuotaSelector(quotas map[string]int) *QuotaSelector {
+	groups := make([]string, 0, len(quotas))
+	q := make(map[string]int, len(quotas))
+	for group, quota := range quotas {
+		if quota <= 0 {
+			continue
+		}
+		groups = append(groups, group)
+		q[group] = quota
+	}
+	sort.Strings(groups)
+
+	return &QuotaSelector{state: &quotaState{
+		groups:    groups,
+		quotas:    q,
+		remaining: copyQuotas(q),
+		current:   make(map[string]int, len(q)),
+	}}
+}
+
+type QuotaSelector struct {
+	// shared by clones
+	state *quotaState
+}
+
+type quotaState struct {
+	mu        sync.Mutex
+	groups    []string
+	quotas    map[string]int
+	remaining map[string]int
+	// current weights of the smooth weighted round-robin
+	current map[string]int
+}
+
+func (s *QuotaSelector) Select(ctx BrainContextReader) string {
+	st := s.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	total, selected := 0, ""
+	for _, group := range st.groups {
+		if st.remaining[group] == 0 {
+			continue
+		}
+		total += st.quotas[group]
+		st.current[group] += st.quotas[group]
+		if selected == "" || st.current[group] > st.current[selected] {
+			selected = group
+		}
+	}
+	if selected == "" {
+		return DefaultCastGroupName
+	}
+	st.current[selected] -= total
+	st.remaining[selected]--
+
+	return selected
+}
+
+// Remaining returns the quotas not used yet, key: cast group name
+func (s *QuotaSelector) Remaining() map[string]int {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return copyQuotas(s.state.remaining)
+}
+
+func (s *QuotaSelector) PossibleGroups() []string {
+	groups := []string{DefaultCastGroupName}
+	return append(groups, s.state.groups...)
+}
+
+func (s *QuotaSelector) Kind() string {
+	return "quota"
+}
+
+func (s *QuotaSelector) Clone() Selector {
+	return &QuotaSelector{state: s.state}
+}
+
+func copyQuotas(quotas map[string]int) map[string]int {
+	ret := make(map[string]int, len(quotas))
+	for group, quota := range quotas {
+		ret[group] = quota
+	}
+	return ret
+}
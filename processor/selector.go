@@ -1,7 +1,11 @@
 package processor
 
+import "sort"
+
 const (
 	DefaultCastGroupName = "__DEFAULT_CAST_GROUP__"
+	// SelectEnd returned by a selector ends the run at the default End neuron, without casting any link.
+	SelectEnd = "__SELECT_END__"
 )
 
 type Selector interface {
@@ -9,12 +13,35 @@ type Selector interface {
 	Clone() Selector
 }
 
+// PossibleGroupsSelector is a Selector which knows all the cast groups it may select,
+// it is used to validate the blueprint statically.
+type PossibleGroupsSelector interface {
+	Selector
+	PossibleGroups() []string
+}
+
+// ErrorAwareSelector is a Selector which also routes the failures of the process.
+// SelectOnError returns the cast group to cast when the process returns err, the failure is handled by the route
+// and is not the run error. Returns empty string to fail the run as usual.
+type ErrorAwareSelector interface {
+	Selector
+	SelectOnError(ctx BrainContextReader, err error) string
+}
+
 type DefaultSelector struct{}
 
 func (s *DefaultSelector) Select(ctx BrainContextReader) string {
 	return DefaultCastGroupName
 }
 
+func (s *DefaultSelector) PossibleGroups() []string {
+	return []string{DefaultCastGroupName}
+}
+
+func (s *DefaultSelector) Kind() string {
+	return "default"
+}
+
 func (s *DefaultSelector) Clone() Selector {
 	return &DefaultSelector{}
 }
@@ -33,8 +60,122 @@ func (s *FuncSelector) Select(ctx BrainContextReader) string {
 	return s.selectFn(ctx)
 }
 
+func (s *FuncSelector) Kind() string {
+	return "func"
+}
+
 func (s *FuncSelector) Clone() Selector {
 	return &FuncSelector{
 		selectFn: s.selectFn,
 	}
 }
+
+// NewErrorAwareFuncSelector new selector like NewFuncSelector, with selectOnErrorFn routing the failures of the process.
+func NewErrorAwareFuncSelector(selectFn func(ctx BrainContextReader) string,
+	selectOnErrorFn func(ctx BrainContextReader, err error) string) *ErrorAwareFuncSelector {
+	return &ErrorAwareFuncSelector{
+		FuncSelector:    FuncSelector{selectFn: selectFn},
+		selectOnErrorFn: selectOnErrorFn,
+	}
+}
+
+type ErrorAwareFuncSelector struct {
+	FuncSelector
+	selectOnErrorFn func(ctx BrainContextReader, err error) string
+}
+
+func (s *ErrorAwareFuncSelector) SelectOnError(ctx BrainContextReader, err error) string {
+	return s.selectOnErrorFn(ctx, err)
+}
+
+func (s *ErrorAwareFuncSelector) Kind() string {
+	return "error_aware_func"
+}
+
+func (s *ErrorAwareFuncSelector) Clone() Selector {
+	return NewErrorAwareFuncSelector(s.selectFn, s.selectOnErrorFn)
+}
+
+// NewNoOpSelector new selector always selects the group, e.g. the only cast group of the neuron
+func NewNoOpSelector(group string) *NoOpSelector {
+	return &NoOpSelector{
+		group: group,
+	}
+}
+
+// NoOpSelector selects its group without reading the brain context, the brain casts the group without calling Select.
+// A neuron with the DefaultSelector and exactly one non-empty cast group casts that group the same way.
+type NoOpSelector struct {
+	group string
+}
+
+func (s *NoOpSelector) Select(ctx BrainContextReader) string {
+	return s.group
+}
+
+// Group returns the group the selector selects
+func (s *NoOpSelector) Group() string {
+	return s.group
+}
+
+func (s *NoOpSelector) PossibleGroups() []string {
+	return []string{s.group}
+}
+
+func (s *NoOpSelector) Kind() string {
+	return "noop"
+}
+
+func (s *NoOpSelector) Clone() Selector {
+	return &NoOpSelector{
+		group: s.group,
+	}
+}
+
+// NewTriggerGroupSelector new selector routes to the cast group mapped from the trigger group which activated the neuron.
+// key of mapping: trigger group key, value: cast group name. Unmapped trigger groups select the default cast group.
+func NewTriggerGroupSelector(mapping map[string]string) *TriggerGroupSelector {
+	m := make(map[string]string, len(mapping))
+	for k, v := range mapping {
+		m[k] = v
+	}
+	return &TriggerGroupSelector{
+		mapping: m,
+	}
+}
+
+type TriggerGroupSelector struct {
+	mapping map[string]string
+}
+
+func (s *TriggerGroupSelector) Select(ctx BrainContextReader) string {
+	if group, ok := s.mapping[ctx.GetTriggeredBy()]; ok {
+		return group
+	}
+	return DefaultCastGroupName
+}
+
+// PossibleGroups returns the default cast group, then the mapped cast groups, sorted and without duplicates
+func (s *TriggerGroupSelector) PossibleGroups() []string {
+	mapped := make(map[string]struct{}, len(s.mapping))
+	for _, group := range s.mapping {
+		if group != DefaultCastGroupName {
+			mapped[group] = struct{}{}
+		}
+	}
+	groups := make([]string, 0, len(mapped))
+	for group := range mapped {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	return append([]string{DefaultCastGroupName}, groups...)
+}
+
+func (s *TriggerGroupSelector) Kind() string {
+	return "trigger_group"
+}
+
+func (s *TriggerGroupSelector) Clone() Selector {
+	return NewTriggerGroupSelector(s.mapping)
+}
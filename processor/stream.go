@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrStreamConsumed the stream is read already, a stream is read once, see BrainContext.GetStream
+	ErrStreamConsumed = errors.New("stream is consumed")
+	// ErrStreamClosed the stream is closed before it is read, e.g. the run which set it is over
+	ErrStreamClosed = errors.New("stream is closed")
+	// ErrNotStream the memory value is not a stream set by BrainContext.SetStream
+	ErrNotStream = errors.New("memory is not a stream")
+	// ErrStreamUnsupported the memory of the brain can not hold a stream, e.g. it serializes its values
+	ErrStreamUnsupported = errors.New("stream is not supported by the memory")
+)
+
+// Stream is a memory value backed by a reader, e.g. a large file, so the brain does not hold the data.
+// Reading consumes it: Take returns the reader once. The brain closes the streams set in a run once the run is over,
+// whether they are read or not, closing a stream more than once is a no-op.
+type Stream struct {
+	mu     sync.Mutex
+	r      io.ReadCloser
+	taken  bool
+	closed bool
+}
+
+// NewStream new stream of the reader
+func NewStream(r io.ReadCloser) *Stream {
+	return &Stream{r: r}
+}
+
+// Take returns the reader of the stream, the caller closes it.
+// It fails with ErrStreamConsumed once the reader is taken, and with ErrStreamClosed once the stream is closed.
+func (s *Stream) Take() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.taken {
+		return nil, ErrStreamConsumed
+	}
+	if s.closed {
+		return nil, ErrStreamClosed
+	}
+	s.taken = true
+
+	return &streamReader{s: s}, nil
+}
+
+// Close closes the reader of the stream, unless it is closed already
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	return s.r.Close()
+}
+
+// streamReader is the reader taken from the stream, its Close closes the stream
+type streamReader struct {
+	s *Stream
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	return r.s.r.Read(p)
+}
+
+func (r *streamReader) Close() error {
+	return r.s.Close()
+}
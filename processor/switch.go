@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNilSwitchCase the chooser or a case of the switch is nil
+	ErrNilSwitchCase = errors.New("nil switch case")
+	// ErrSwitchCaseNotFound the key chosen is not in the cases of the switch, and there is no default case
+	ErrSwitchCaseNotFound = errors.New("switch case not found")
+)
+
+// NewSwitch new processor dispatches to one of the cases, by the key chosen from the brain context.
+// The default case processes keys not in the cases, it is optional, nil for none. An unmatched key without
+// the default case is ErrSwitchCaseNotFound. A nil chooser or a nil case is ErrNilSwitchCase.
+// Use cast groups instead to branch between neurons.
+func NewSwitch(chooser func(bcr BrainContextReader) string, cases map[string]Processor, defaultCase Processor) (*SwitchProcessor, error) {
+	if chooser == nil {
+		return nil, fmt.Errorf("%w: chooser", ErrNilSwitchCase)
+	}
+	c := make(map[string]Processor, len(cases))
+	for key, p := range cases {
+		if p == nil {
+			return nil, fmt.Errorf("%w: case %s", ErrNilSwitchCase, key)
+		}
+		c[key] = p
+	}
+
+	return &SwitchProcessor{
+		chooser:     chooser,
+		cases:       c,
+		defaultCase: defaultCase,
+	}, nil
+}
+
+type SwitchProcessor struct {
+	chooser     func(bcr BrainContextReader) string
+	cases       map[string]Processor
+	defaultCase Processor
+}
+
+func (p *SwitchProcessor) Process(ctx BrainContext) error {
+	key := p.chooser(ctx)
+	c, ok := p.cases[key]
+	if !ok {
+		if p.defaultCase == nil {
+			return fmt.Errorf("%w: %s, and no default case", ErrSwitchCaseNotFound, key)
+		}
+		c = p.defaultCase
+	}
+
+	return c.Process(ctx)
+}
+
+func (p *SwitchProcessor) Kind() string {
+	return "switch"
+}
+
+func (p *SwitchProcessor) Clone() Processor {
+	cases := make(map[string]Processor, len(p.cases))
+	for key, c := range p.cases {
+		cases[key] = c.Clone()
+	}
+	var defaultCase Processor
+	if p.defaultCase != nil {
+		defaultCase = p.defaultCase.Clone()
+	}
+
+	return &SwitchProcessor{
+		chooser:     p.chooser,
+		cases:       cases,
+		defaultCase: defaultCase,
+	}
+}
@@ -0,0 +1,223 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+type TemplateOption int
+
+const (
+	// MissingKeyZero renders missing memories and missing map keys as empty instead of failing
+	MissingKeyZero TemplateOption = iota + 1
+)
+
+// orEmptyFunc is piped to every output action with MissingKeyZero,
+// since a missing value of map[string]interface{} is printed as "<no value>" by text/template
+const orEmptyFunc = "rmodelOrEmpty"
+
+// NewTemplateProcessor new processor renders the text/template with memory as the data context,
+// and writes the rendered string to memory outKey.
+// `{{.name}}` reads memory "name", and `{{.user.name}}` reads the "name" key or field of memory "user".
+// Only memories with string keys are reachable from the template.
+// A missing memory or map key is an error, unless MissingKeyZero is set to render it as empty.
+// Fields of a missing value, e.g. `{{.user.name}}` without memory "user", are still an error.
+func NewTemplateProcessor(tmpl string, outKey string, opts ...TemplateOption) *TemplateProcessor {
+	p := &TemplateProcessor{
+		outKey: outKey,
+	}
+	for _, opt := range opts {
+		if opt == MissingKeyZero {
+			p.missingKeyZero = true
+		}
+	}
+
+	missingKey := "missingkey=error"
+	if p.missingKeyZero {
+		missingKey = "missingkey=zero"
+	}
+	p.tmpl, p.err = template.New("template").Option(missingKey).Funcs(template.FuncMap{
+		orEmptyFunc: orEmpty,
+	}).Parse(tmpl)
+	if p.err == nil {
+		p.keys = templateRootKeys(p.tmpl)
+		if p.missingKeyZero {
+			pipeOrEmpty(p.tmpl)
+		}
+	}
+
+	return p
+}
+
+type TemplateProcessor struct {
+	tmpl           *template.Template
+	outKey         string
+	missingKeyZero bool
+	// keys are the memories referenced by the template
+	keys []string
+	// err is the template parse error, returned on Process
+	err error
+}
+
+func (p *TemplateProcessor) Process(ctx BrainContext) error {
+	if p.err != nil {
+		return fmt.Errorf("parse template error: %w", p.err)
+	}
+
+	data := make(map[string]interface{}, len(p.keys))
+	for _, key := range p.keys {
+		if !ctx.ExistMemory(key) {
+			if p.missingKeyZero {
+				continue
+			}
+			return fmt.Errorf("memory %q referenced by template not found", key)
+		}
+		data[key] = ctx.GetMemory(key)
+	}
+
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return fmt.Errorf("execute template error: %w", err)
+	}
+
+	return ctx.SetMemory(p.outKey, sb.String())
+}
+
+func (p *TemplateProcessor) Kind() string {
+	return "template"
+}
+
+func (p *TemplateProcessor) Clone() Processor {
+	// a parsed template is safe for parallel execution, so it is shared
+	keys := make([]string, len(p.keys))
+	copy(keys, p.keys)
+	return &TemplateProcessor{
+		tmpl:           p.tmpl,
+		outKey:         p.outKey,
+		missingKeyZero: p.missingKeyZero,
+		keys:           keys,
+		err:            p.err,
+	}
+}
+
+func orEmpty(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// pipeOrEmpty appends orEmptyFunc to the pipeline of every action printing a value, e.g. `{{.title}}` becomes `{{.title | rmodelOrEmpty}}`.
+// Conditions of if, range and with are not changed, a missing value is already false.
+func pipeOrEmpty(tmpl *template.Template) {
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			// actions declaring or assigning variables print nothing
+			if n.Pipe == nil || len(n.Pipe.Decl) != 0 {
+				return
+			}
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(orEmptyFunc).SetPos(n.Pos)},
+			})
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+}
+
+// templateRootKeys returns the fields read from the data context of the template,
+// e.g. "user" of `{{.user.name}}` and `{{$.user}}`. Fields read inside range and with are relative to the element, not the data context.
+func templateRootKeys(tmpl *template.Template) []string {
+	seen := make(map[string]struct{})
+	keys := make([]string, 0)
+	add := func(key string) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	var walk func(node parse.Node, atRoot bool)
+	walk = func(node parse.Node, atRoot bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, atRoot)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, atRoot)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, atRoot)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, atRoot)
+			}
+		case *parse.ChainNode:
+			walk(n.Node, atRoot)
+		case *parse.FieldNode:
+			if atRoot {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				add(n.Ident[1])
+			}
+		case *parse.IfNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, atRoot)
+			walk(n.ElseList, atRoot)
+		case *parse.RangeNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		case *parse.WithNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		case *parse.TemplateNode:
+			walk(n.Pipe, atRoot)
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root, true)
+		}
+	}
+
+	return keys
+}
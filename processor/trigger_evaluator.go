@@ -0,0 +1,94 @@
+package processor
+
+import "time"
+
+// TriggerEvaluatorGroupKey is the trigger group key of a neuron activated by its TriggerEvaluator, see BrainContext.GetTriggeredBy
+const TriggerEvaluatorGroupKey = "__TRIGGER_EVALUATOR__"
+
+// SignalInfo is a signal arrived at a neuron by an in-link, and not consumed yet.
+type SignalInfo struct {
+	LinkID string
+	// Seq sequence of the signal of the link in the run, starts from 1
+	Seq int
+	// ArrivedAt time of the signal arrival
+	ArrivedAt time.Time
+	// Payload of the signal, set by the upstream neuron for the cast group of the link, see BrainContext.SetCastPayload
+	Payload interface{}
+}
+
+// TriggerEvaluator decides whether a neuron is activated by the signals arrived at its in-links, key: link ID.
+// It is called whenever a new signal arrives, the neuron is activated once it is satisfied,
+// and all arrived signals are consumed. A neuron without a TriggerEvaluator is activated by its trigger groups.
+type TriggerEvaluator interface {
+	IsSatisfied(arrived map[string]SignalInfo) bool
+	Clone() TriggerEvaluator
+}
+
+func NewFuncTriggerEvaluator(evaluateFn func(arrived map[string]SignalInfo) bool) *FuncTriggerEvaluator {
+	return &FuncTriggerEvaluator{
+		evaluateFn: evaluateFn,
+	}
+}
+
+type FuncTriggerEvaluator struct {
+	evaluateFn func(arrived map[string]SignalInfo) bool
+}
+
+func (e *FuncTriggerEvaluator) IsSatisfied(arrived map[string]SignalInfo) bool {
+	return e.evaluateFn(arrived)
+}
+
+func (e *FuncTriggerEvaluator) Kind() string {
+	return "func"
+}
+
+func (e *FuncTriggerEvaluator) Clone() TriggerEvaluator {
+	return &FuncTriggerEvaluator{
+		evaluateFn: e.evaluateFn,
+	}
+}
+
+// CountIf decides whether the signal of the link with the payload counts toward the threshold of a ThresholdTriggerEvaluator
+type CountIf func(linkID string, value interface{}) bool
+
+// NewThresholdTriggerEvaluator new evaluator satisfied once threshold of the arrived signals count, e.g. a quorum of the
+// parallel branches. With countIf, only the signals it accepts count, e.g. the valid results, nil counts every signal.
+// The signals which do not count are still consumed with the others once the neuron is activated.
+func NewThresholdTriggerEvaluator(threshold int, countIf CountIf) *ThresholdTriggerEvaluator {
+	return &ThresholdTriggerEvaluator{
+		threshold: threshold,
+		countIf:   countIf,
+	}
+}
+
+type ThresholdTriggerEvaluator struct {
+	threshold int
+	countIf   CountIf
+}
+
+func (e *ThresholdTriggerEvaluator) IsSatisfied(arrived map[string]SignalInfo) bool {
+	return e.Count(arrived) >= e.threshold
+}
+
+// Count returns the number of the arrived signals counting toward the threshold
+func (e *ThresholdTriggerEvaluator) Count(arrived map[string]SignalInfo) int {
+	count := 0
+	for linkID, signal := range arrived {
+		if e.countIf == nil || e.countIf(linkID, signal.Payload) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (e *ThresholdTriggerEvaluator) Kind() string {
+	return "threshold"
+}
+
+func (e *ThresholdTriggerEvaluator) Clone() TriggerEvaluator {
+	return &ThresholdTriggerEvaluator{
+		threshold: e.threshold,
+		countIf:   e.countIf,
+	}
+}
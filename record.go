@@ -0,0 +1,467 @@
+package rModel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// Recording is the recorded run of a blueprint, the results of its processors and the decisions of its selectors.
+// It is encoded by gob, memory values of custom types should be registered by gob.Register.
+type Recording struct {
+	// Version is the topology of the recorded blueprint, see TopologyVersion
+	Version string
+	// Executions of each neuron in order, key: neuron ID
+	Executions map[string][]RecordedExecution
+	// Selections of each neuron in order, key: neuron ID, value: selected cast groups
+	Selections map[string][]string
+}
+
+// RecordedExecution is the result of a process of the neuron.
+type RecordedExecution struct {
+	// Ops are the calls to the BrainContext changing the brain, in order
+	Ops []RecordedOp
+	// Err is the message of the process error, empty if the process succeeded
+	Err string
+	// Aborted indicates the process error aborts the run, see processor.AbortRun
+	Aborted bool
+}
+
+// RecordedOp is a call to the BrainContext changing the brain.
+type RecordedOp struct {
+	// Kind is one of RecordedOpSetMemory, RecordedOpDeleteMemory, RecordedOpClearMemory and RecordedOpContinueCast
+	Kind string
+	// Args are the keys and values of SetMemory, or the key of DeleteMemory
+	Args []interface{}
+}
+
+const (
+	RecordedOpSetMemory    = "set_memory"
+	RecordedOpDeleteMemory = "delete_memory"
+	RecordedOpClearMemory  = "clear_memory"
+	RecordedOpContinueCast = "continue_cast"
+)
+
+// Encode writes the recording to w by gob.
+func (r *Recording) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(r)
+}
+
+// DecodeRecording reads a recording written by Recording.Encode.
+func DecodeRecording(r io.Reader) (*Recording, error) {
+	rec := &Recording{}
+	if err := gob.NewDecoder(r).Decode(rec); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// TopologyVersion returns the digest of the neurons and links of the blueprint.
+// Neuron and link IDs are generated when they are added, so a blueprint built again has another version, its Clone has the same.
+func TopologyVersion(bp core.Blueprint) string {
+	entries := make([]string, 0)
+	for _, n := range bp.ListNeurons() {
+		entries = append(entries, "neuron "+n.GetID())
+	}
+	for _, l := range bp.ListLinks() {
+		entries = append(entries, fmt.Sprintf("link %s %s->%s", l.GetID(), l.GetSrcNeuronID(), l.GetDestNeuronID()))
+	}
+	sort.Strings(entries)
+	h := sha256.New()
+	for _, e := range entries {
+		h.Write([]byte(e + "\n"))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewRecorder new recorder of a run, build the brain from the blueprint returned by Record.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Recorder records the results of the processors and the decisions of the selectors of a run.
+// Links triggered by TrigLinks of the brain and the memories of the entry are not recorded.
+type Recorder struct {
+	mu        sync.Mutex
+	recording *Recording
+}
+
+// Record returns a clone of bp whose processors and selectors record to the recorder, and resets the recording.
+func (r *Recorder) Record(bp core.Blueprint) (core.Blueprint, error) {
+	cp, ok := bp.Clone().(*brainprint)
+	if !ok {
+		return nil, fmt.Errorf("blueprint %T is not built by NewBlueprint", bp)
+	}
+	r.mu.Lock()
+	r.recording = &Recording{
+		Version:    TopologyVersion(cp),
+		Executions: make(map[string][]RecordedExecution),
+		Selections: make(map[string][]string),
+	}
+	r.mu.Unlock()
+	for id, n := range cp.neurons {
+		n.processor = &recordingProcessor{neuronID: id, p: n.processor, r: r}
+		if n.selector != nil {
+			n.selector = &recordingSelector{neuronID: id, s: n.selector, r: r}
+		}
+	}
+
+	return cp, nil
+}
+
+// Recording returns a copy of the recording so far, nil if Record is not called.
+func (r *Recorder) Recording() *Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recording == nil {
+		return nil
+	}
+	rec := &Recording{
+		Version:    r.recording.Version,
+		Executions: make(map[string][]RecordedExecution, len(r.recording.Executions)),
+		Selections: make(map[string][]string, len(r.recording.Selections)),
+	}
+	for id, executions := range r.recording.Executions {
+		rec.Executions[id] = append([]RecordedExecution(nil), executions...)
+	}
+	for id, selections := range r.recording.Selections {
+		rec.Selections[id] = append([]string(nil), selections...)
+	}
+
+	return rec
+}
+
+func (r *Recorder) addExecution(neuronID string, e RecordedExecution) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording.Executions[neuronID] = append(r.recording.Executions[neuronID], e)
+}
+
+func (r *Recorder) addSelection(neuronID, group string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording.Selections[neuronID] = append(r.recording.Selections[neuronID], group)
+}
+
+type recordingProcessor struct {
+	neuronID string
+	p        processor.Processor
+	r        *Recorder
+}
+
+func (p *recordingProcessor) Process(ctx processor.BrainContext) error {
+	rc := &recordingContext{BrainContext: ctx}
+	err := p.p.Process(rc)
+	e := RecordedExecution{Ops: rc.ops}
+	if err != nil {
+		e.Err = err.Error()
+		e.Aborted = errors.Is(err, processor.ErrAbortRun)
+	}
+	p.r.addExecution(p.neuronID, e)
+
+	return err
+}
+
+func (p *recordingProcessor) Kind() string {
+	return processor.KindOf(p.p)
+}
+
+func (p *recordingProcessor) Clone() processor.Processor {
+	return &recordingProcessor{neuronID: p.neuronID, p: p.p.Clone(), r: p.r}
+}
+
+// recordingContext records the calls changing the brain, a process calls its BrainContext sequentially
+type recordingContext struct {
+	processor.BrainContext
+	ops []RecordedOp
+}
+
+func (c *recordingContext) SetMemory(keysAndValues ...interface{}) error {
+	if err := c.BrainContext.SetMemory(keysAndValues...); err != nil {
+		return err
+	}
+	c.ops = append(c.ops, RecordedOp{Kind: RecordedOpSetMemory, Args: keysAndValues})
+
+	return nil
+}
+
+func (c *recordingContext) DeleteMemory(key interface{}) {
+	c.BrainContext.DeleteMemory(key)
+	c.ops = append(c.ops, RecordedOp{Kind: RecordedOpDeleteMemory, Args: []interface{}{key}})
+}
+
+func (c *recordingContext) ClearMemory() {
+	c.BrainContext.ClearMemory()
+	c.ops = append(c.ops, RecordedOp{Kind: RecordedOpClearMemory})
+}
+
+func (c *recordingContext) ContinueCast() {
+	c.BrainContext.ContinueCast()
+	c.ops = append(c.ops, RecordedOp{Kind: RecordedOpContinueCast})
+}
+
+// RegisterCompensation is not recorded, compensations are not replayed
+func (c *recordingContext) RegisterCompensation(fn func(ctx context.Context) error) {
+	c.BrainContext.RegisterCompensation(fn)
+}
+
+type recordingSelector struct {
+	neuronID string
+	s        processor.Selector
+	r        *Recorder
+}
+
+func (s *recordingSelector) Select(ctx processor.BrainContextReader) string {
+	group := s.s.Select(ctx)
+	s.r.addSelection(s.neuronID, group)
+
+	return group
+}
+
+// SelectOnError records only if the selector routes the failures, otherwise the failure is not routed.
+func (s *recordingSelector) SelectOnError(ctx processor.BrainContextReader, err error) string {
+	selector, ok := s.s.(processor.ErrorAwareSelector)
+	if !ok {
+		return ""
+	}
+	group := selector.SelectOnError(ctx, err)
+	s.r.addSelection(s.neuronID, group)
+
+	return group
+}
+
+func (s *recordingSelector) Kind() string {
+	return processor.KindOf(s.s)
+}
+
+func (s *recordingSelector) Clone() processor.Selector {
+	return &recordingSelector{neuronID: s.neuronID, s: s.s.Clone(), r: s.r}
+}
+
+// NewReplayRunner new runner replays the recording on a clone of bp, without calling its processors and selectors.
+// Returns core.ErrRecordingMismatch if the topology of bp differs from the recorded one.
+func NewReplayRunner(bp core.Blueprint, rec *Recording) (*ReplayRunner, error) {
+	if version := TopologyVersion(bp); version != rec.Version {
+		return nil, errors.Wrapf(core.ErrRecordingMismatch, "recorded version %s, blueprint version %s", rec.Version, version)
+	}
+	cp, ok := bp.Clone().(*brainprint)
+	if !ok {
+		return nil, fmt.Errorf("blueprint %T is not built by NewBlueprint", bp)
+	}
+	runner := &ReplayRunner{
+		blueprint:  cp,
+		recording:  rec,
+		executions: make(map[string]int),
+		selections: make(map[string]int),
+	}
+	for id, n := range cp.neurons {
+		n.processor = &replayProcessor{neuronID: id, runner: runner}
+		if n.selector != nil {
+			n.selector = &replaySelector{neuronID: id, s: n.selector, runner: runner}
+		}
+	}
+
+	return runner, nil
+}
+
+// ReplayRunner re-executes a recorded run, every process applies its recorded changes and returns its recorded error,
+// every selector returns its recorded decision.
+type ReplayRunner struct {
+	blueprint *brainprint
+	recording *Recording
+
+	mu sync.Mutex
+	// replayed executions and selections of each neuron, key: neuron ID
+	executions map[string]int
+	selections map[string]int
+}
+
+// Blueprint returns the blueprint replaying the recording, build the brain to replay from it.
+func (r *ReplayRunner) Blueprint() core.Blueprint {
+	return r.blueprint
+}
+
+// Run replays the recording on the brain built from Blueprint, from the entry links with the memories of the recorded entry.
+// Returns the run error, or core.ErrRecordingMismatch if the replay diverges from the recording.
+func (r *ReplayRunner) Run(brain core.Brain, keysAndValues ...interface{}) error {
+	r.mu.Lock()
+	r.executions = make(map[string]int)
+	r.selections = make(map[string]int)
+	r.mu.Unlock()
+
+	if err := brain.Reset(); err != nil {
+		return err
+	}
+	if err := brain.EntryWithMemory(keysAndValues...); err != nil {
+		return err
+	}
+	brain.Wait()
+	runErr := brain.GetRunError()
+	if errors.Is(runErr, core.ErrRecordingMismatch) {
+		return runErr
+	}
+	if err := r.checkReplayed(); err != nil {
+		return err
+	}
+
+	return runErr
+}
+
+// checkReplayed every recorded execution and selection should be replayed
+func (r *ReplayRunner) checkReplayed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, executions := range r.recording.Executions {
+		if n := r.executions[id]; n != len(executions) {
+			return errors.Wrapf(core.ErrRecordingMismatch, "neuron %s replayed %d of %d executions", id, n, len(executions))
+		}
+	}
+	for id, selections := range r.recording.Selections {
+		if n := r.selections[id]; n != len(selections) {
+			return errors.Wrapf(core.ErrRecordingMismatch, "neuron %s replayed %d of %d selections", id, n, len(selections))
+		}
+	}
+
+	return nil
+}
+
+func (r *ReplayRunner) nextExecution(neuronID string) (RecordedExecution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	executions := r.recording.Executions[neuronID]
+	i := r.executions[neuronID]
+	if i >= len(executions) {
+		return RecordedExecution{}, errors.Wrapf(core.ErrRecordingMismatch,
+			"neuron %s executes more than the %d recorded executions", neuronID, len(executions))
+	}
+	r.executions[neuronID]++
+
+	return executions[i], nil
+}
+
+func (r *ReplayRunner) nextSelection(neuronID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	selections := r.recording.Selections[neuronID]
+	i := r.selections[neuronID]
+	if i >= len(selections) {
+		// casts nothing, the replay diverges, see checkReplayed
+		return ""
+	}
+	r.selections[neuronID]++
+
+	return selections[i]
+}
+
+type replayProcessor struct {
+	neuronID string
+	runner   *ReplayRunner
+}
+
+func (p *replayProcessor) Process(ctx processor.BrainContext) error {
+	e, err := p.runner.nextExecution(p.neuronID)
+	if err != nil {
+		return &replayMismatchError{err: err}
+	}
+	for _, op := range e.Ops {
+		switch op.Kind {
+		case RecordedOpSetMemory:
+			if err = ctx.SetMemory(op.Args...); err != nil {
+				return err
+			}
+		case RecordedOpDeleteMemory:
+			ctx.DeleteMemory(op.Args[0])
+		case RecordedOpClearMemory:
+			ctx.ClearMemory()
+		case RecordedOpContinueCast:
+			ctx.ContinueCast()
+		default:
+			return fmt.Errorf("unsupported recorded op: %s", op.Kind)
+		}
+	}
+	if e.Err == "" {
+		return nil
+	}
+	if e.Aborted {
+		return &replayedError{msg: e.Err, wrapped: processor.ErrAbortRun}
+	}
+
+	return &replayedError{msg: e.Err}
+}
+
+func (p *replayProcessor) Kind() string {
+	return "replay"
+}
+
+func (p *replayProcessor) Clone() processor.Processor {
+	return &replayProcessor{neuronID: p.neuronID, runner: p.runner}
+}
+
+// replayedError has the message of the recorded error
+type replayedError struct {
+	msg     string
+	wrapped error
+}
+
+func (e *replayedError) Error() string {
+	return e.msg
+}
+
+func (e *replayedError) Unwrap() error {
+	return e.wrapped
+}
+
+// replayMismatchError aborts the run diverged from the recording
+type replayMismatchError struct {
+	err error
+}
+
+func (e *replayMismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *replayMismatchError) Unwrap() error {
+	return e.err
+}
+
+func (e *replayMismatchError) Is(target error) bool {
+	return target == processor.ErrAbortRun
+}
+
+type replaySelector struct {
+	neuronID string
+	// the selector of the blueprint, only to know whether it routes the failures
+	s      processor.Selector
+	runner *ReplayRunner
+}
+
+func (s *replaySelector) Select(ctx processor.BrainContextReader) string {
+	return s.runner.nextSelection(s.neuronID)
+}
+
+func (s *replaySelector) SelectOnError(ctx processor.BrainContextReader, err error) string {
+	if _, ok := s.s.(processor.ErrorAwareSelector); !ok {
+		return ""
+	}
+
+	return s.runner.nextSelection(s.neuronID)
+}
+
+func (s *replaySelector) Kind() string {
+	return "replay"
+}
+
+func (s *replaySelector) Clone() processor.Selector {
+	return &replaySelector{neuronID: s.neuronID, s: s.s, runner: s.runner}
+}
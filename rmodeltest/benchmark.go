@@ -0,0 +1,44 @@
+package rmodeltest
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// BenchmarkProcessor runs a brain of the single neuron of the processor b.N times, each run with the memory,
+// so the processor is measured with the overhead of a real run: the context of the run, the logger of the brain,
+// and the signals from the entry link to the End neuron. The keys of the memory are set again between the runs
+// with the timer stopped, the other memories, e.g. set by the processor, are kept. The options configure the brain, e.g. brainlocal.WithLogger or brainlocal.WithMetrics
+// to measure with those of the service. The benchmark fails on the first run error.
+func BenchmarkProcessor(b *testing.B, p processor.Processor, memory map[string]interface{}, withOpts ...brainlocal.Option) {
+	b.Helper()
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuronWithProcessor(p)
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+	brain := brainlocal.BuildBrain(bp, withOpts...)
+	defer brain.Shutdown()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for key, value := range memory {
+			if err := brain.SetMemory(key, value); err != nil {
+				b.Fatalf("set memory %s: %s", key, err)
+			}
+		}
+		b.StartTimer()
+
+		if err := brain.Run(); err != nil {
+			b.Fatalf("run: %s", err)
+		}
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			b.Fatalf("run %d error: %s", i, err)
+		}
+	}
+}
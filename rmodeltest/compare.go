@@ -0,0 +1,93 @@
+package rmodeltest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// Fields of a Difference, besides the memory keys prefixed by FieldMemoryPrefix
+const (
+	FieldReachedEnds = "reached_ends"
+	FieldError       = "error"
+	// FieldMemoryPrefix prefixes the output keys, e.g. `memory.answer`
+	FieldMemoryPrefix = "memory."
+)
+
+// Difference is a difference of the runs of one input by two brains.
+type Difference struct {
+	// Index of the input
+	Index int
+	// Input of the runs
+	Input map[string]interface{}
+	// Field which differs, FieldReachedEnds, FieldError, or an output key prefixed by FieldMemoryPrefix
+	Field string
+	// Left is the value of the field in the run of the first brain, nil if the memory is not set or the run does not fail
+	Left interface{}
+	// Right is the value of the field in the run of the second brain
+	Right interface{}
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("input %d: %s: %v != %v", d.Index, d.Field, d.Left, d.Right)
+}
+
+// CompareRuns runs each input by both brains, one run at a time, and returns the differences of their outputs,
+// in input order. The outputs are the memories of the output keys, see core.WithOutputKeys, the reached End neurons
+// and the message of the run error. Build both brains with the same seed, e.g. brainlocal.WithRandSeed,
+// so the processors drawing on processor.BrainContext.Rand run the same.
+// The reached End neurons are compared by ID, build the brains from clones of one blueprint to compare them.
+func CompareRuns(b1, b2 core.Brain, inputs []map[string]interface{}, withOpts ...core.BatchOption) []Difference {
+	left, _ := b1.RunBatch(context.Background(), inputs, 1, withOpts...)
+	right, _ := b2.RunBatch(context.Background(), inputs, 1, withOpts...)
+
+	diffs := make([]Difference, 0)
+	for i := range inputs {
+		diff := func(field string, l, r interface{}) {
+			if !reflect.DeepEqual(l, r) {
+				diffs = append(diffs, Difference{Index: i, Input: inputs[i], Field: field, Left: l, Right: r})
+			}
+		}
+		diff(FieldReachedEnds, sortedEnds(left[i].ReachedEnds), sortedEnds(right[i].ReachedEnds))
+		diff(FieldError, errMessage(left[i].Err), errMessage(right[i].Err))
+		for _, key := range outputKeys(left[i].Memory, right[i].Memory) {
+			diff(FieldMemoryPrefix+key, left[i].Memory[key], right[i].Memory[key])
+		}
+	}
+
+	return diffs
+}
+
+func sortedEnds(ends []string) []string {
+	sorted := append([]string{}, ends...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func errMessage(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+
+	return err.Error()
+}
+
+// outputKeys returns the keys of either memory, sorted
+func outputKeys(left, right map[string]any) []string {
+	keys := make([]string, 0, len(left))
+	for key := range left {
+		keys = append(keys, key)
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
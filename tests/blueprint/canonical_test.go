@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// buildJoin builds a blueprint of two branches joining, the same way each call
+func buildJoin() core.Blueprint {
+	bp := rModel.NewBlueprint()
+	process := func(bc processor.BrainContext) error {
+		return bc.SetMemory(bc.GetCurrentNeuronID(), true)
+	}
+	route := bp.AddNeuron(process, core.WithSelector(processor.NewNoOpSelector("branches")))
+	left := bp.AddNeuron(process)
+	right := bp.AddNeuron(process)
+	join := bp.AddNeuron(process)
+	_, _ = bp.AddEntryLinkTo(route)
+	toLeft, _ := bp.AddLink(route, left)
+	toRight, _ := bp.AddLink(route, right)
+	_ = route.AddCastGroup("branches", toLeft, toRight)
+	fromLeft, _ := bp.AddLink(left, join)
+	fromRight, _ := bp.AddLink(right, join)
+	_ = join.AddTriggerGroup(fromLeft, fromRight)
+	_ = join.AddNamedTriggerGroup("left-only", fromLeft)
+	_, _ = bp.AddEndLinkFrom(join)
+
+	return bp
+}
+
+// describe the topology of the blueprint with its IDs
+func describe(bp core.Blueprint) []string {
+	ret := make([]string, 0)
+	for _, n := range bp.ListNeurons() {
+		ret = append(ret, fmt.Sprintf("neuron %s cast %v trigger %v", n.GetID(), n.ListCastGroups(), n.ListTriggerGroups()))
+	}
+	for _, l := range bp.ListLinks() {
+		ret = append(ret, fmt.Sprintf("link %s %s->%s", l.GetID(), l.GetSrcNeuronID(), l.GetDestNeuronID()))
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func TestCanonicalize(t *testing.T) {
+	a, b := buildJoin(), buildJoin()
+	if reflect.DeepEqual(describe(a), describe(b)) {
+		t.Fatalf("expect generated IDs to differ before canonicalization")
+	}
+	a.Canonicalize()
+	b.Canonicalize()
+	if !reflect.DeepEqual(describe(a), describe(b)) {
+		t.Fatalf("expect identical IDs, got\n%v\n%v", describe(a), describe(b))
+	}
+	// idempotent
+	before := describe(a)
+	a.Canonicalize()
+	if !reflect.DeepEqual(describe(a), before) {
+		t.Errorf("expect canonical IDs unchanged, got %v", describe(a))
+	}
+	if err := a.Validate(); err != nil {
+		t.Fatalf("expect canonical blueprint valid, got %s", err)
+	}
+	if !a.HasNeuron("n1") || !a.HasLink("l1") {
+		t.Errorf("expect canonical IDs, got %v", describe(a))
+	}
+
+	brain := brainlocal.BuildBrain(a)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	for _, id := range []string{"n1", "n2", "n3", "n4"} {
+		if brain.GetMemory(id) != true {
+			t.Errorf("expect neuron %s processed", id)
+		}
+	}
+}
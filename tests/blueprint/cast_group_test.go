@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestListCastGroupsResolved(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	branch := bp.AddNeuron(emptyFn)
+	n1 := bp.AddNeuron(emptyFn)
+	n2 := bp.AddNeuron(emptyFn)
+	n3 := bp.AddNeuron(emptyFn)
+	l1, _ := bp.AddLink(branch, n1)
+	l2, _ := bp.AddLink(branch, n2)
+	l3, _ := bp.AddLink(branch, n3)
+	_ = branch.AddCastGroup("both", l1, l2)
+
+	groups, err := bp.ListCastGroupsResolved(branch.GetID())
+	if err != nil {
+		t.Fatalf("list cast groups error: %s", err)
+	}
+	expect := map[string][]string{"both": {l1.GetID(), l2.GetID()}, processor.DefaultCastGroupName: {l3.GetID()}}
+	if len(groups) != len(expect) {
+		t.Fatalf("expect groups %v, got %v", expect, groups)
+	}
+	for name, ids := range expect {
+		sort.Strings(ids)
+		got := make([]string, 0, len(groups[name]))
+		for _, l := range groups[name] {
+			got = append(got, l.GetID())
+		}
+		if !reflect.DeepEqual(got, ids) {
+			t.Errorf("group %s: expect links sorted by ID %v, got %v", name, ids, got)
+		}
+	}
+
+	if _, err := bp.ListCastGroupsResolved("missing"); err == nil {
+		t.Errorf("expect error for a missing neuron")
+	}
+}
+
+func TestGroupMembership(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	branch := bp.AddNeuron(emptyFn)
+	n1 := bp.AddNeuron(emptyFn)
+	n2 := bp.AddNeuron(emptyFn)
+	join := bp.AddNeuron(emptyFn)
+	l1, _ := bp.AddLink(branch, n1)
+	l2, _ := bp.AddLink(branch, n2)
+	l3, _ := bp.AddLink(branch, join)
+	_ = branch.AddCastGroup("one", l1, l3)
+	_ = branch.AddCastGroup("both", l1, l2)
+	j1, _ := bp.AddLink(n1, join)
+	j2, _ := bp.AddLink(n2, join)
+	_ = join.AddNamedTriggerGroup("left", j1, l3)
+	_ = join.AddNamedTriggerGroup("right", j1, j2)
+
+	cases := []struct {
+		linkID string
+		group  string
+		ok     bool
+	}{
+		{l1.GetID(), "both", true},
+		{l2.GetID(), "both", true},
+		{l3.GetID(), "one", true},
+		{j1.GetID(), "", false},
+	}
+	for _, c := range cases {
+		if group, ok := branch.CastGroupOf(c.linkID); group != c.group || ok != c.ok {
+			t.Errorf("link %s: expect cast group %q %v, got %q %v", c.linkID, c.group, c.ok, group, ok)
+		}
+	}
+
+	if keys := join.TriggerGroupsContaining(j1.GetID()); !reflect.DeepEqual(keys, []string{"left", "right"}) {
+		t.Errorf("expect trigger groups [left right], got %v", keys)
+	}
+	if keys := join.TriggerGroupsContaining(j2.GetID()); !reflect.DeepEqual(keys, []string{"right"}) {
+		t.Errorf("expect trigger groups [right], got %v", keys)
+	}
+	if keys := join.TriggerGroupsContaining("missing"); len(keys) != 0 {
+		t.Errorf("expect no trigger group, got %v", keys)
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/core"
+)
+
+type order struct {
+	ID    string
+	Items []string
+}
+
+type account struct {
+	Owner   string `json:"owner"`
+	Balance int    `json:"balance"`
+}
+
+type unregistered struct{ N int }
+
+func TestCodec(t *testing.T) {
+	rModel.RegisterCodec(order{}, rModel.NewGobCodec(order{}))
+	rModel.RegisterCodec(&account{}, rModel.NewJSONCodec(&account{}))
+
+	values := []interface{}{
+		nil, "text", true, 42, int64(-7), uint8(3), 1.5, []byte("raw"),
+		order{ID: "o-1", Items: []string{"a", "b"}},
+		&account{Owner: "ann", Balance: 10},
+	}
+	for _, v := range values {
+		ev, err := rModel.EncodeValue(v)
+		if err != nil {
+			t.Fatalf("encode %#v error: %s", v, err)
+		}
+		got, err := rModel.DecodeValue(ev)
+		if err != nil {
+			t.Fatalf("decode %#v error: %s", v, err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("expect %#v decoded, got %#v", v, got)
+		}
+	}
+}
+
+func TestCodecNotRegistered(t *testing.T) {
+	_, err := rModel.EncodeValue(unregistered{N: 1})
+	if !errors.Is(err, core.ErrNoCodec) || !strings.Contains(err.Error(), "unregistered") {
+		t.Errorf("expect ErrNoCodec naming the type, got %v", err)
+	}
+	_, err = rModel.DecodeValue(rModel.EncodedValue{Type: "example.com/app.Missing", Data: []byte("{}")})
+	if !errors.Is(err, core.ErrNoCodec) || !strings.Contains(err.Error(), "example.com/app.Missing") {
+		t.Errorf("expect ErrNoCodec naming the type, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+)
+
+func TestDefineGroupAliasAllOrNothing(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	check := bp.AddNeuron(emptyFn)
+	verify := bp.AddNeuron(emptyFn)
+	next := bp.AddNeuron(emptyFn)
+
+	retry := bp.AddNeuron(emptyFn)
+
+	toNext, _ := bp.AddLink(check, next)
+	verifyNext, _ := bp.AddLink(verify, next)
+	verifyRetry, _ := bp.AddLink(verify, retry)
+	_ = check.AddCastGroup("ok", toNext)
+	_ = verify.AddCastGroup("passed", verifyNext)
+	// the alias conflicts with a cast group of verify
+	_ = verify.AddCastGroup("success", verifyRetry)
+
+	err := bp.DefineGroupAlias("success", map[string]string{
+		check.GetID():  "ok",
+		verify.GetID(): "passed",
+	})
+	if err == nil {
+		t.Fatalf("expect alias conflict error")
+	}
+	if aliases := check.ListCastGroupAliases(); len(aliases) != 0 {
+		t.Errorf("no alias should be defined when any neuron fails, got %v", aliases)
+	}
+
+	if err = bp.DefineGroupAlias("", map[string]string{check.GetID(): "ok"}); err == nil {
+		t.Errorf("expect empty alias error")
+	}
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestLinkLabels(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	noop := func(bc processor.BrainContext) error { return nil }
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop)
+	l, _ := bp.AddLink(a, b,
+		core.WithLinkLabels(map[string]string{"edge_type": "fallback"}),
+		core.WithLinkLabels(map[string]string{"owner": "team-a"}))
+
+	if labels := l.GetLabels(); labels["edge_type"] != "fallback" || labels["owner"] != "team-a" {
+		t.Errorf("expect labels merged, got %v", labels)
+	}
+
+	// labels are deep copied into the clone
+	clone := bp.Clone()
+	l.SetLabels(map[string]string{"edge_type": "primary"})
+	var cloned core.Link
+	for _, cl := range clone.ListLinks() {
+		if cl.GetID() == l.GetID() {
+			cloned = cl
+		}
+	}
+	if cloned == nil {
+		t.Fatalf("expect link %s in the clone", l.GetID())
+	}
+	if labels := cloned.GetLabels(); labels["edge_type"] != "fallback" || labels["owner"] != "team-a" {
+		t.Errorf("expect labels of the clone unchanged, got %v", labels)
+	}
+}
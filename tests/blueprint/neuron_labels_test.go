@@ -0,0 +1,20 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+)
+
+func TestNeuronSetLabelsCopy(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(emptyFn)
+	labels := map[string]string{"team": "search"}
+	n.SetLabels(labels)
+
+	labels["team"] = "ads"
+	labels["tier"] = "1"
+	if got := n.GetLabels(); len(got) != 1 || got["team"] != "search" {
+		t.Errorf("expect labels unchanged by the caller, got %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/core"
+)
+
+// indexOf returns the positions of the neurons in the order
+func indexOf(order []core.Neuron) map[string]int {
+	index := make(map[string]int, len(order))
+	for i, n := range order {
+		index[n.GetID()] = i
+	}
+
+	return index
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(emptyFn)
+	b := bp.AddNeuron(emptyFn)
+	c := bp.AddNeuron(emptyFn)
+	d := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(a, c)
+	_, _ = bp.AddLink(b, d)
+	_, _ = bp.AddLink(c, d)
+	_, _ = bp.AddEndLinkFrom(d)
+
+	order, err := bp.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("topological order error: %s", err)
+	}
+	if len(order) != 5 {
+		t.Fatalf("expect 5 neurons with the End neuron, got %d", len(order))
+	}
+	index := indexOf(order)
+	for _, l := range bp.ListLinks() {
+		if l.IsEntryLink() {
+			continue
+		}
+		if index[l.GetSrcNeuronID()] >= index[l.GetDestNeuronID()] {
+			t.Errorf("expect %s before %s, got order %v", l.GetSrcNeuronID(), l.GetDestNeuronID(), index)
+		}
+	}
+
+	// stable for the same topology
+	for i := 0; i < 10; i++ {
+		again, _ := bp.Clone().TopologicalOrder()
+		for k := range order {
+			if again[k].GetID() != order[k].GetID() {
+				t.Fatalf("expect the same order, got %v and %v", indexOf(order), indexOf(again))
+			}
+		}
+	}
+}
+
+func TestTopologicalOrderLoop(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(emptyFn)
+	b := bp.AddNeuron(emptyFn)
+	c := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(b, c)
+	_, _ = bp.AddLink(c, b)
+	_, _ = bp.AddEndLinkFrom(c)
+
+	if _, err := bp.TopologicalOrder(); !errors.Is(err, core.ErrCycle) {
+		t.Fatalf("expect ErrCycle, got %v", err)
+	}
+
+	c.SetMaxRevisits(3)
+	order, err := bp.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("topological order error: %s", err)
+	}
+	// the loop is entered at b
+	index := indexOf(order)
+	if !(index[a.GetID()] < index[b.GetID()] && index[b.GetID()] < index[c.GetID()] && index[c.GetID()] < index[core.EndNeuronID]) {
+		t.Errorf("expect a, b, c, End in order, got %v", index)
+	}
+}
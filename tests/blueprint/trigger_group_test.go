@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/utils"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestAddTriggerGroupSubsetAndSuperset(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(emptyFn)
+
+	in := make(map[string]core.Link)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		src := bp.AddNeuron(emptyFn)
+		l, err := bp.AddLink(src, join)
+		if err != nil {
+			t.Fatalf("add link error: %s", err)
+		}
+		in[name] = l
+	}
+
+	// pre-existing groups: {a,b}, {c}, {d,e}
+	if err := join.AddTriggerGroup(in["a"], in["b"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	if err := join.AddTriggerGroup(in["d"], in["e"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a", "b"}, {"c"}, {"d", "e"}})
+
+	// {a,b,c} contains {a,b} and {c}, both removed; {d,e} untouched
+	if err := join.AddTriggerGroup(in["a"], in["b"], in["c"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a", "b", "c"}, {"d", "e"}})
+
+	// {b,c} is included in {a,b,c}, nothing changes
+	if err := join.AddTriggerGroup(in["b"], in["c"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a", "b", "c"}, {"d", "e"}})
+
+	// {d,e} again is a duplicate, nothing changes
+	if err := join.AddTriggerGroup(in["e"], in["d"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a", "b", "c"}, {"d", "e"}})
+
+	// {a,b,c,d,e} contains every existing group
+	if err := join.AddTriggerGroup(in["a"], in["b"], in["c"], in["d"], in["e"]); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a", "b", "c", "d", "e"}})
+}
+
+func TestAddNamedTriggerGroupNameReuse(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(emptyFn)
+	la, _ := bp.AddLink(bp.AddNeuron(emptyFn), join)
+	lb, _ := bp.AddLink(bp.AddNeuron(emptyFn), join)
+	in := map[string]core.Link{"a": la, "b": lb}
+
+	if err := join.AddNamedTriggerGroup("x", la); err != nil {
+		t.Fatalf("add named trigger group error: %s", err)
+	}
+	// the same links under the same name is a no-op
+	if err := join.AddNamedTriggerGroup("x", la); err != nil {
+		t.Errorf("add the same named trigger group again error: %s", err)
+	}
+	// reusing the name for other links would leave link a in no trigger group
+	if err := join.AddNamedTriggerGroup("x", lb); err == nil {
+		t.Errorf("expect error when reusing the name with other links")
+	}
+	assertTriggerGroups(t, join, in, [][]string{{"a"}, {"b"}})
+	if group := join.ListTriggerGroups()["x"]; len(group) != 1 || group[0] != la.GetID() {
+		t.Errorf("expect trigger group x of link a, got %v", group)
+	}
+}
+
+func assertTriggerGroups(t *testing.T, n core.Neuron, in map[string]core.Link, expect [][]string) {
+	t.Helper()
+
+	groups := n.ListTriggerGroups()
+	if len(groups) != len(expect) {
+		t.Fatalf("expect %d trigger groups, got %d: %v", len(expect), len(groups), groups)
+	}
+	for _, names := range expect {
+		ids := make([]string, 0, len(names))
+		for _, name := range names {
+			ids = append(ids, in[name].GetID())
+		}
+		found := false
+		for _, group := range groups {
+			if utils.SlicesContainEqual(group, ids) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("trigger group %v not found in %v", names, groups)
+		}
+	}
+}
+
+func emptyFn(bc processor.BrainContext) error {
+	return nil
+}
+
+func TestTriggerGroupsLogExpression(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(emptyFn)
+	in := make([]string, 0, 3)
+	links := make([]core.Link, 0, 3)
+	for i := 0; i < 3; i++ {
+		l, _ := bp.AddLink(bp.AddNeuron(emptyFn), join)
+		links = append(links, l)
+		in = append(in, l.GetID())
+	}
+	_ = join.AddTriggerGroup(links[0], links[1])
+	_ = join.AddNamedTriggerGroup("fromC", links[2])
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+	logger.Info().Object("neuron", join.(zerolog.LogObjectMarshaler)).Msg("")
+	var out struct {
+		Neuron struct {
+			Trigger string `json:"trigger"`
+		} `json:"neuron"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal log error: %v", err)
+	}
+
+	ab := []string{in[0], in[1]}
+	sort.Strings(ab)
+	expect := fmt.Sprintf("(%s & %s) | fromC:%s", ab[0], ab[1], in[2])
+	if out.Neuron.Trigger != expect {
+		t.Errorf("expect trigger %q, got %q", expect, out.Neuron.Trigger)
+	}
+}
+
+func TestEffectiveTriggerExpression(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(emptyFn)
+	links := make([]core.Link, 0, 4)
+	for i := 0; i < 4; i++ {
+		l, _ := bp.AddLink(bp.AddNeuron(emptyFn), join)
+		links = append(links, l)
+	}
+	a, b, c, d := links[0].GetID(), links[1].GetID(), links[2].GetID(), links[3].GetID()
+	if expect := strings.Join(sortedIDs(a, b, c, d), " | "); join.EffectiveTriggerExpression() != expect {
+		t.Errorf("expect trigger %q, got %q", expect, join.EffectiveTriggerExpression())
+	}
+
+	// {a, b} collapses {a} and {b}, {a} is included in {a, b}, {a, b, c} collapses {a, b} and {c}
+	_ = join.AddTriggerGroup(links[0], links[1])
+	_ = join.AddTriggerGroup(links[0])
+	_ = join.AddTriggerGroup(links[0], links[1], links[2])
+
+	abc := sortedIDs(a, b, c)
+	groups := [][]string{abc, {d}}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.Join(groups[i], " ") < strings.Join(groups[j], " ")
+	})
+	if got := join.EffectiveTrigger(); !reflect.DeepEqual(got, groups) {
+		t.Errorf("expect effective trigger %v, got %v", groups, got)
+	}
+	exprs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if len(group) > 1 {
+			exprs = append(exprs, "("+strings.Join(group, " & ")+")")
+		} else {
+			exprs = append(exprs, group[0])
+		}
+	}
+	if expect := strings.Join(exprs, " | "); join.EffectiveTriggerExpression() != expect {
+		t.Errorf("expect trigger %q, got %q", expect, join.EffectiveTriggerExpression())
+	}
+}
+
+func sortedIDs(ids ...string) []string {
+	sort.Strings(ids)
+	return ids
+}
+
+func TestAddTriggerGroupFromNeurons(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(emptyFn)
+	b := bp.AddNeuron(emptyFn)
+	c := bp.AddNeuron(emptyFn)
+	join := bp.AddNeuron(emptyFn)
+	a1, _ := bp.AddLink(a, join)
+	a2, _ := bp.AddLink(a, join)
+	b1, _ := bp.AddLink(b, join)
+	_, _ = bp.AddLink(c, join)
+
+	if err := join.AddTriggerGroupFromNeurons(bp, a.GetID(), b.GetID()); err != nil {
+		t.Fatalf("add trigger group error: %s", err)
+	}
+	expect := []string{a1.GetID(), a2.GetID(), b1.GetID()}
+	sort.Strings(expect)
+	found := false
+	for _, group := range join.ListTriggerGroups() {
+		sorted := append([]string(nil), group...)
+		sort.Strings(sorted)
+		found = found || reflect.DeepEqual(sorted, expect)
+	}
+	if !found {
+		t.Errorf("expect a trigger group of %v, got %v", expect, join.ListTriggerGroups())
+	}
+
+	// a source without link to the neuron
+	before := join.ListTriggerGroups()
+	other := bp.AddNeuron(emptyFn)
+	if err := join.AddTriggerGroupFromNeurons(bp, a.GetID(), other.GetID()); err == nil ||
+		!strings.Contains(err.Error(), other.GetID()) {
+		t.Errorf("expect error naming neuron %s, got %v", other.GetID(), err)
+	}
+	if !reflect.DeepEqual(join.ListTriggerGroups(), before) {
+		t.Errorf("expect trigger groups unchanged on error, got %v", join.ListTriggerGroups())
+	}
+}
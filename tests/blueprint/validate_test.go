@@ -0,0 +1,294 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestValidateEndReachable(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(emptyFn)
+	n2 := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(n1)
+	_, _ = bp.AddEndLinkFrom(n2)
+
+	if err := bp.Validate(); !errors.Is(err, core.ErrNoReachableEnd) {
+		t.Fatalf("expect ErrNoReachableEnd, got %v", err)
+	}
+
+	_, _ = bp.AddLink(n1, n2)
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+}
+
+func TestEndNeuronHasNoOutLink(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(emptyFn)
+	end := bp.AddEndNeuron("done")
+	if end.GetID() != core.EndNeuronIDOf("done") || !core.IsEndNeuronID(end.GetID()) {
+		t.Fatalf("unexpected end neuron id: %s", end.GetID())
+	}
+
+	if _, err := bp.AddLink(end, n1); err == nil {
+		t.Fatalf("expect error when adding out-link to end neuron")
+	}
+}
+
+func TestDefineGroupAliasValidate(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(emptyFn)
+	n2 := bp.AddNeuron(emptyFn)
+	l, _ := bp.AddLink(n1, n2)
+	_ = n1.AddCastGroup("ok", l)
+
+	if err := bp.DefineGroupAlias("success", map[string]string{n1.GetID(): "missing"}); err == nil {
+		t.Errorf("expect error for missing cast group")
+	}
+	if err := bp.DefineGroupAlias("success", map[string]string{"missing": "ok"}); err == nil {
+		t.Errorf("expect error for missing neuron")
+	}
+	if err := bp.DefineGroupAlias("success", map[string]string{n1.GetID(): "ok"}); err != nil {
+		t.Fatalf("define group alias error: %s", err)
+	}
+	if aliases := n1.ListCastGroupAliases(); aliases["success"] != "ok" {
+		t.Errorf("unexpected aliases: %v", aliases)
+	}
+}
+
+func TestValidateUnsatisfiableTriggerGroup(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	branch := bp.AddNeuron(emptyFn)
+	other := bp.AddNeuron(emptyFn)
+	join := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(branch)
+	_, _ = bp.AddEntryLinkTo(other)
+	fromBranch, _ := bp.AddLink(branch, join)
+	fromOther, _ := bp.AddLink(other, join)
+	_ = join.AddTriggerGroup(fromBranch, fromOther)
+
+	// the default selector bound explicitly only casts the default group, but the link is moved to group "named"
+	_ = branch.AddCastGroup("named", fromBranch)
+	branch.BindCastGroupSelector(&processor.DefaultSelector{})
+	if err := bp.Validate(); !errors.Is(err, core.ErrUnsatisfiableTriggerGroup) {
+		t.Fatalf("expect ErrUnsatisfiableTriggerGroup, got %v", err)
+	}
+
+	// a selector without possible groups may select any group
+	branch.BindCastGroupSelectFunc(func(bcr processor.BrainContextReader) string {
+		return "named"
+	})
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+}
+
+func TestValidateEmptyCastGroup(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	branch := bp.AddNeuron(emptyFn)
+	next := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(branch)
+	toNext, _ := bp.AddLink(branch, next)
+	_ = branch.AddCastGroup("next", toNext)
+	branch.BindCastGroupSelectFunc(func(bcr processor.BrainContextReader) string {
+		return "next"
+	})
+	// the default group is empty since its link moved to group "next"
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+
+	// an empty group is a warning, not an error
+	_ = branch.AddCastGroup("stop")
+	report := bp.ValidateReport()
+	if !report.OK() || len(report.Warnings) != 1 || report.Warnings[0].Kind != core.WarningEmptyCastGroup ||
+		report.Warnings[0].NeuronID != branch.GetID() {
+		t.Fatalf("expect an empty cast group warning only, got %v %v", report.Errors, report.Warnings)
+	}
+
+	// an intentionally empty group terminates the branch
+	branch.AllowEmptyCastGroup("stop")
+	if report := bp.ValidateReport(); !report.OK() || len(report.Warnings) != 0 {
+		t.Fatalf("expect no error nor warning, got %v %v", report.Errors, report.Warnings)
+	}
+}
+
+func TestValidateSelfLink(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(emptyFn)
+	if _, err := bp.AddLink(n1, n1); !errors.Is(err, core.ErrSelfLink) {
+		t.Fatalf("expect ErrSelfLink, got %v", err)
+	}
+
+	n2 := bp.AddNeuron(emptyFn, core.WithMaxRevisits(3))
+	_, _ = bp.AddEntryLinkTo(n2)
+	_, _ = bp.AddEndLinkFrom(n2)
+	if _, err := bp.AddLink(n2, n2); err != nil {
+		t.Fatalf("expect self-link allowed with max revisits, got %v", err)
+	}
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+
+	n2.SetMaxRevisits(0)
+	if err := bp.Validate(); !errors.Is(err, core.ErrSelfLink) {
+		t.Fatalf("expect ErrSelfLink, got %v", err)
+	}
+}
+
+func TestValidateNilProcessor(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuronWithProcessor(nil)
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+	if err := bp.Validate(); !errors.Is(err, core.ErrNilProcessor) {
+		t.Fatalf("expect ErrNilProcessor, got %v", err)
+	}
+
+	bp = rModel.NewBlueprint()
+	n = bp.AddNeuron(nil)
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+	if err := bp.Validate(); !errors.Is(err, core.ErrNilProcessor) {
+		t.Fatalf("expect ErrNilProcessor for a nil process func, got %v", err)
+	}
+}
+
+func TestValidateEmpty(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	if err := bp.Validate(); !errors.Is(err, core.ErrNoNeurons) {
+		t.Fatalf("expect ErrNoNeurons, got %v", err)
+	}
+
+	// End neurons only
+	bp.AddEndNeuron("")
+	if err := bp.Validate(); !errors.Is(err, core.ErrNoNeurons) {
+		t.Fatalf("expect ErrNoNeurons with End neurons only, got %v", err)
+	}
+}
+
+func TestValidateEntryNeuronEndReachable(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(emptyFn)
+	n2 := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(n1)
+	_, _ = bp.AddEndLinkFrom(n1)
+	_, _ = bp.AddEntryLinkTo(n2)
+
+	// n2 has no path to End, though n1 has
+	if err := bp.Validate(); !errors.Is(err, core.ErrNoReachableEnd) {
+		t.Fatalf("expect ErrNoReachableEnd, got %v", err)
+	}
+
+	_, _ = bp.AddLink(n2, n1)
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	branch := bp.AddNeuron(emptyFn)
+	next := bp.AddNeuron(emptyFn)
+	broken1 := bp.AddNeuron(nil)
+	broken2 := bp.AddNeuron(nil)
+	_, _ = bp.AddEntryLinkTo(branch)
+	_, _ = bp.AddEntryLinkTo(broken1)
+	_, _ = bp.AddEntryLinkTo(broken2)
+	toNext, _ := bp.AddLink(branch, next)
+	_ = branch.AddCastGroup("next", toNext)
+	_ = branch.AddCastGroup("stop")
+	branch.AllowEmptyCastGroup("stop")
+	branch.BindCastGroupSelector(processor.NewNoOpSelector("next"))
+	orphan := bp.AddNeuron(emptyFn)
+
+	report := bp.ValidateReport()
+	if report.OK() || len(report.Errors) != 2 {
+		t.Fatalf("expect 2 errors, got %v", report.Errors)
+	}
+	for _, err := range report.Errors {
+		if !errors.Is(err, core.ErrNilProcessor) {
+			t.Errorf("expect ErrNilProcessor, got %v", err)
+		}
+	}
+	if err := bp.Validate(); err == nil || err.Error() != report.Err().Error() {
+		t.Errorf("expect Validate to return the first error %v, got %v", report.Err(), err)
+	}
+
+	kinds := make(map[string]core.WarningKind)
+	for _, w := range report.Warnings {
+		kinds[w.NeuronID] = w.Kind
+	}
+	if len(report.Warnings) != 2 || kinds[orphan.GetID()] != core.WarningUnreachableNeuron ||
+		kinds[branch.GetID()] != core.WarningUnselectedCastGroup {
+		t.Fatalf("unexpected warnings: %v", report.Warnings)
+	}
+
+	// warnings do not fail the validation
+	bp = rModel.NewBlueprint()
+	n := bp.AddNeuron(emptyFn)
+	_, _ = bp.AddEntryLinkTo(n)
+	bp.AddNeuron(emptyFn)
+	report = bp.ValidateReport()
+	if !report.OK() || len(report.Warnings) != 1 || bp.Validate() != nil {
+		t.Fatalf("expect a warning only, got %v %v", report.Errors, report.Warnings)
+	}
+}
+
+func TestValidateConflictingNeuronConfig(t *testing.T) {
+	cases := []struct {
+		name      string
+		build     func(bp core.Blueprint, n core.Neuron)
+		expectErr error
+	}{
+		{"run once", func(bp core.Blueprint, n core.Neuron) {
+			n.SetRunOnce(true)
+		}, nil},
+		{"max revisits", func(bp core.Blueprint, n core.Neuron) {
+			n.SetMaxRevisits(2)
+			_, _ = bp.AddLink(n, n)
+		}, nil},
+		{"run once and max revisits", func(bp core.Blueprint, n core.Neuron) {
+			n.SetRunOnce(true)
+			n.SetMaxRevisits(2)
+		}, core.ErrConflictingNeuronConfig},
+		{"run once and self-link", func(bp core.Blueprint, n core.Neuron) {
+			n.SetMaxRevisits(2)
+			_, _ = bp.AddLink(n, n)
+			n.SetRunOnce(true)
+		}, core.ErrConflictingNeuronConfig},
+		{"run once in a loop", func(bp core.Blueprint, n core.Neuron) {
+			n.SetRunOnce(true)
+			back := bp.AddNeuron(emptyFn, core.WithMaxRevisits(2))
+			_, _ = bp.AddLink(n, back)
+			_, _ = bp.AddLink(back, n)
+		}, core.ErrConflictingNeuronConfig},
+		{"run once and refire guard", func(bp core.Blueprint, n core.Neuron) {
+			n.SetRunOnce(true)
+			n.SetRefireGuard(func(bcr processor.BrainContextReader) bool { return true })
+		}, core.ErrConflictingNeuronConfig},
+	}
+	for _, c := range cases {
+		bp := rModel.NewBlueprint()
+		n := bp.AddNeuron(emptyFn)
+		_, _ = bp.AddEntryLinkTo(n)
+		_, _ = bp.AddEndLinkFrom(n)
+		c.build(bp, n)
+
+		err := bp.Validate()
+		if c.expectErr == nil && err != nil {
+			t.Errorf("%s: validate error: %s", c.name, err)
+		}
+		if c.expectErr != nil && !errors.Is(err, c.expectErr) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expectErr, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), n.GetID()) {
+			t.Errorf("%s: expect the error to name neuron %s, got %v", c.name, n.GetID(), err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlite"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestMemoryAudit(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("name", "Clay")
+	})
+	_, _ = bp.AddEntryLinkTo(n1)
+
+	var mu sync.Mutex
+	events := make([]core.MemoryAuditEvent, 0)
+	brain := brainlite.BuildBrain(bp, brainlite.WithMemoryAudit(func(event core.MemoryAuditEvent) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}, core.WithAuditValue()))
+
+	_ = brain.Entry()
+	brain.Wait()
+	_ = brain.GetMemory("name")
+	brain.DeleteMemory("name")
+	brain.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	expect := []struct {
+		neuronID string
+		op       core.MemoryOperation
+		value    any
+	}{
+		{n1.GetID(), core.MemoryOperationWrite, "Clay"},
+		{"", core.MemoryOperationRead, "Clay"},
+		{"", core.MemoryOperationDelete, nil},
+	}
+	if len(events) != len(expect) {
+		t.Fatalf("expect %d audit events, got %d: %+v", len(expect), len(events), events)
+	}
+	for i, e := range expect {
+		got := events[i]
+		if got.Seq != uint64(i+1) || got.NeuronID != e.neuronID || got.Operation != e.op || got.Key != "name" || got.Value != e.value {
+			t.Errorf("audit event %d: expect %+v, got %+v", i, e, got)
+		}
+	}
+}
+
+func TestMemoryAuditOrder(t *testing.T) {
+	bp := rModel.NewBlueprint()
+
+	var mu sync.Mutex
+	events := make([]core.MemoryAuditEvent, 0)
+	brain := brainlite.BuildBrain(bp, brainlite.WithMemoryAudit(func(event core.MemoryAuditEvent) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	},
+		core.WithAuditValue(),
+		core.WithAuditKeyFilter(func(key any) bool { return key == "last" }),
+	))
+	defer brain.Shutdown()
+
+	// init memory before the parallel writes
+	_ = brain.SetMemory("unaudited", true)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = brain.SetMemory("last", i)
+		}(i)
+	}
+	wg.Wait()
+	// the read is audited too, after the writes
+	value := brain.GetMemory("last")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 21 {
+		t.Fatalf("expect 21 audit events, got %d", len(events))
+	}
+	// the last audited write is the value in memory
+	if last := events[len(events)-2].Value; value != last {
+		t.Errorf("expect memory of the last audited write %v, got %v", last, value)
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestAbortRun(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	started := make(chan struct{})
+	abort := bp.AddNeuron(func(bc processor.BrainContext) error {
+		<-started
+		if bc.GetMemory("abort") == true {
+			return processor.AbortRun("fatal condition")
+		}
+		return nil
+	})
+	afterAbort := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("afterAbort", true)
+	})
+	siblingErr := make(chan error, 1)
+	sibling := bp.AddNeuron(func(bc processor.BrainContext) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		err := bc.SetMemory("sibling", true)
+		if bc.GetMemory("abort") == true {
+			siblingErr <- err
+		}
+		return err
+	})
+	afterSibling := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("afterSibling", true)
+	})
+	_, _ = bp.AddEntryLinkTo(abort)
+	_, _ = bp.AddEntryLinkTo(sibling)
+	_, _ = bp.AddLink(abort, afterAbort)
+	_, _ = bp.AddLink(sibling, afterSibling)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	start := time.Now()
+	_ = brain.EntryWithMemory("abort", true)
+	brain.Wait()
+	// the run returns without waiting for the in-flight sibling
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("expect the aborted run to return promptly, took %v", elapsed)
+	}
+	if err := brain.GetRunError(); !errors.Is(err, processor.ErrAbortRun) {
+		t.Errorf("expect run error %v, got %v", processor.ErrAbortRun, err)
+	}
+
+	// the in-flight sibling is cancelled, it can not change the memory or cast
+	if err := <-siblingErr; !errors.Is(err, core.ErrRunCancelled) {
+		t.Errorf("expect memory change of the cancelled sibling to fail with %v, got %v", core.ErrRunCancelled, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, key := range []string{"afterAbort", "sibling", "afterSibling"} {
+		if brain.ExistMemory(key) {
+			t.Errorf("expect no memory %s after the run is aborted", key)
+		}
+	}
+	if state := brain.GetState(); state != core.BrainStateSleeping {
+		t.Errorf("expect brain sleeping, got %s", state)
+	}
+
+	// the brain runs again after an abort
+	started = make(chan struct{})
+	_ = brain.EntryWithMemory("abort", false)
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Errorf("expect no run error, got %v", err)
+	}
+	for _, key := range []string{"afterAbort", "sibling", "afterSibling"} {
+		if !brain.ExistMemory(key) {
+			t.Errorf("expect memory %s after the run", key)
+		}
+	}
+}
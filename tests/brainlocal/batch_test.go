@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func newDoubleBrain() *brainlocal.BrainLocal {
+	bp := rModel.NewBlueprint()
+	double := bp.AddNeuron(func(bc processor.BrainContext) error {
+		n := bc.GetMemory("n").(int)
+		if n < 0 {
+			return fmt.Errorf("negative input %d", n)
+		}
+		return bc.SetMemory("out", n*2)
+	})
+	_, _ = bp.AddEntryLinkTo(double)
+	_, _ = bp.AddEndLinkFrom(double)
+
+	return brainlocal.BuildBrain(bp)
+}
+
+func TestRunBatch(t *testing.T) {
+	brain := newDoubleBrain()
+
+	inputs := make([]map[string]any, 0)
+	for i := 0; i < 20; i++ {
+		n := i
+		if i == 7 {
+			n = -1
+		}
+		inputs = append(inputs, map[string]any{"n": n})
+	}
+
+	results, err := brain.RunBatch(context.Background(), inputs, 4, core.WithOutputKeys("out"))
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	if len(results) != len(inputs) {
+		t.Fatalf("expect %d results, got %d", len(inputs), len(results))
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("result %d: unexpected index %d", i, r.Index)
+		}
+		if i == 7 {
+			if r.Err == nil {
+				t.Errorf("result %d: expect run error", i)
+			}
+			continue
+		}
+		if r.Err != nil || r.Memory["out"] != i*2 || len(r.ReachedEnds) != 1 {
+			t.Errorf("result %d: unexpected %+v", i, r)
+		}
+	}
+
+	_, err = brain.RunBatch(context.Background(), inputs, 4, core.WithFailFast())
+	if err == nil {
+		t.Errorf("expect error with fail fast")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = brain.RunBatch(ctx, inputs, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect context canceled, got %v", err)
+	}
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("result %d: expect context canceled, got %v", i, r.Err)
+		}
+	}
+}
+
+// countingProcessor is not safe for parallel runs, every run should have its own clone
+type countingProcessor struct {
+	count int
+}
+
+func (p *countingProcessor) Process(bc processor.BrainContext) error {
+	p.count++
+	return bc.SetMemory("out", bc.GetMemory("n"))
+}
+
+func (p *countingProcessor) Clone() processor.Processor {
+	return &countingProcessor{}
+}
+
+func TestRunBatchClonesProcessors(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	counting := &countingProcessor{}
+	n := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	_, _ = bp.AddEntryLinkTo(n)
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	// edit the topology after build, RunBatch runs the edited topology
+	count := bp.AddNeuronWithProcessor(counting)
+	toCount, _ := bp.AddLink(n, count)
+	if err := brain.AddNeuron(count); err != nil {
+		t.Fatalf("add neuron error: %s", err)
+	}
+	if err := brain.AddLink(toCount); err != nil {
+		t.Fatalf("add link error: %s", err)
+	}
+
+	inputs := make([]map[string]any, 0)
+	for i := 0; i < 20; i++ {
+		inputs = append(inputs, map[string]any{"n": i})
+	}
+	results, err := brain.RunBatch(context.Background(), inputs, 4, core.WithOutputKeys("out"))
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Memory["out"] != i {
+			t.Errorf("result %d: unexpected %+v", i, r)
+		}
+	}
+	if counting.count != 0 {
+		t.Errorf("workers should run clones of the processor, the original ran %d times", counting.count)
+	}
+}
+
+func TestRunBatchStats(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	noop := func(bc processor.BrainContext) error { return nil }
+	fast := bp.AddNeuron(noop)
+	slow := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+	join := bp.AddNeuron(noop)
+	_, _ = bp.AddEntryLinkTo(fast)
+	_, _ = bp.AddEntryLinkTo(slow)
+	fastJoin, _ := bp.AddLink(fast, join)
+	slowJoin, _ := bp.AddLink(slow, join)
+	_ = join.AddTriggerGroup(fastJoin, slowJoin)
+	_, _ = bp.AddEndLinkFrom(join)
+	brain := brainlocal.BuildBrain(bp)
+
+	results, err := brain.RunBatch(context.Background(), []map[string]any{{}, {}}, 2, core.WithStatsSlowestN(1))
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	for i, r := range results {
+		stats := r.Stats()
+		if stats.Executed != 3 || stats.Failed != 0 {
+			t.Errorf("result %d: expect 3 executed and 0 failed, got %+v", i, stats)
+		}
+		if stats.Duration < 30*time.Millisecond {
+			t.Errorf("result %d: expect duration of at least the slow neuron, got %v", i, stats.Duration)
+		}
+		if len(stats.Slowest) != 1 || stats.Slowest[0].NeuronID != slow.GetID() {
+			t.Errorf("result %d: expect the slowest neuron %s, got %+v", i, slow.GetID(), stats.Slowest)
+		}
+	}
+
+	results, err = newDoubleBrain().RunBatch(context.Background(), []map[string]any{{"n": -1}}, 1)
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	if stats := results[0].Stats(); stats.Executed != 1 || stats.Failed != 1 || len(stats.Slowest) != 1 {
+		t.Errorf("expect 1 executed and 1 failed, got %+v", stats)
+	}
+}
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+	"github.com/Rovanta/rmodel/rmodeltest"
+)
+
+func TestBenchmarkProcessor(t *testing.T) {
+	var calls int64
+	p := processor.NewFuncProcessor(func(bc processor.BrainContext) error {
+		atomic.AddInt64(&calls, 1)
+		if bc.Context() == nil {
+			t.Errorf("expect the context of the run")
+		}
+		return bc.SetMemory("sum", bc.GetMemory("a").(int)+bc.GetMemory("b").(int))
+	})
+
+	// drive the harness with a fixed b.N, testing.Benchmark would size it to a second of runs
+	b := &testing.B{N: 5}
+	done := make(chan struct{})
+	go func() {
+		// Fatalf of the harness exits the goroutine
+		defer close(done)
+		rmodeltest.BenchmarkProcessor(b, p, map[string]interface{}{"a": 1, "b": 2})
+	}()
+	<-done
+	if b.Failed() {
+		t.Fatalf("expect the benchmark succeeded")
+	}
+	if got := atomic.LoadInt64(&calls); got != int64(b.N) {
+		t.Errorf("expect the processor called %d times, got %d", b.N, got)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestCastPayload(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]map[string]interface{})
+	receive := func(name string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = bc.GetPayloads()
+			return nil
+		}
+	}
+	bp := rModel.NewBlueprint()
+	report := bp.AddNeuron(func(bc processor.BrainContext) error {
+		if err := bc.SetCastPayload("brief", "summary"); err != nil {
+			return err
+		}
+		if err := bc.SetCastPayload("full", []string{"detail 1", "detail 2"}); err != nil {
+			return err
+		}
+		if err := bc.SetCastPayload("unknown", 1); err == nil {
+			t.Errorf("expect error of the unknown cast group")
+		}
+		return nil
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return bcr.GetMemory("mode").(string)
+	}))
+	// audit is in every group, silent has no payload
+	brief := bp.AddNeuron(receive("brief"))
+	full := bp.AddNeuron(receive("full"))
+	audit := bp.AddNeuron(receive("audit"))
+	_, _ = bp.AddEntryLinkTo(report)
+	toBrief, _ := bp.AddLink(report, brief)
+	toFull, _ := bp.AddLink(report, full)
+	toAudit, _ := bp.AddLink(report, audit)
+	_ = report.AddCastGroup("brief", toBrief, toAudit)
+	_ = report.AddCastGroup("full", toFull, toAudit)
+	_ = report.AddCastGroup("silent", toAudit)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	cases := []struct {
+		mode   string
+		expect map[string]map[string]interface{}
+	}{
+		{"brief", map[string]map[string]interface{}{
+			"brief": {toBrief.GetID(): "summary"},
+			"audit": {toAudit.GetID(): "summary"},
+		}},
+		{"full", map[string]map[string]interface{}{
+			"full":  {toFull.GetID(): []string{"detail 1", "detail 2"}},
+			"audit": {toAudit.GetID(): []string{"detail 1", "detail 2"}},
+		}},
+		{"silent", map[string]map[string]interface{}{
+			"audit": {toAudit.GetID(): nil},
+		}},
+	}
+	for _, c := range cases {
+		received = make(map[string]map[string]interface{})
+		_ = brain.Reset()
+		_ = brain.EntryWithMemory("mode", c.mode)
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			t.Fatalf("mode %s: run error: %v", c.mode, err)
+		}
+		if !reflect.DeepEqual(received, c.expect) {
+			t.Errorf("mode %s: expect payloads %v, got %v", c.mode, c.expect, received)
+		}
+	}
+}
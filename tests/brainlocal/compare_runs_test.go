@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+	"github.com/Rovanta/rmodel/rmodeltest"
+)
+
+func TestCompareRuns(t *testing.T) {
+	// the second version doubles the large inputs only, and draws a random bonus from the run source
+	build := func(factor func(n int) int) core.Brain {
+		bp := rModel.NewBlueprint()
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			in := bc.GetMemory("n").(int)
+			return bc.SetMemory("out", in*factor(in), "bonus", bc.Rand().Intn(1000))
+		})
+		_, _ = bp.AddEntryLinkTo(n)
+		return brainlocal.BuildBrain(bp, brainlocal.WithRandSeed(7))
+	}
+	b1 := build(func(n int) int { return 2 })
+	defer b1.Shutdown()
+	b2 := build(func(n int) int {
+		if n > 10 {
+			return 2
+		}
+		return 3
+	})
+	defer b2.Shutdown()
+
+	inputs := []map[string]interface{}{{"n": 1}, {"n": 20}, {"n": 5}}
+	diffs := rmodeltest.CompareRuns(b1, b2, inputs, core.WithOutputKeys("out", "bonus"))
+	if len(diffs) != 2 {
+		t.Fatalf("expect 2 differences, got %v", diffs)
+	}
+	for k, index := range []int{0, 2} {
+		d := diffs[k]
+		n := inputs[index]["n"].(int)
+		if d.Index != index || d.Field != "memory.out" || d.Left != 2*n || d.Right != 3*n {
+			t.Errorf("expect input %d out %d != %d, got %v", index, 2*n, 3*n, d)
+		}
+	}
+
+	if diffs := rmodeltest.CompareRuns(b1, b1, inputs, core.WithOutputKeys("out", "bonus")); len(diffs) != 0 {
+		t.Errorf("expect no difference of a brain with itself, got %v", diffs)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// sagaBlueprint registers a compensation in each of the neurons a, b and c in order, c fails with failErr if not nil
+func sagaBlueprint(calls *[]string, compensationErrs map[string]error, failErr error) core.Blueprint {
+	bp := rModel.NewBlueprint()
+	step := func(name string, err error) core.Neuron {
+		return bp.AddNeuron(func(bc processor.BrainContext) error {
+			bc.RegisterCompensation(func(ctx context.Context) error {
+				*calls = append(*calls, name)
+				return compensationErrs[name]
+			})
+			return err
+		})
+	}
+	a, b, c := step("a", nil), step("b", nil), step("c", failErr)
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(b, c)
+	_, _ = bp.AddEndLinkFrom(c)
+
+	return bp
+}
+
+func TestCompensation(t *testing.T) {
+	failErr := errors.New("charge failed")
+	cases := []struct {
+		name             string
+		failErr          error
+		compensationErrs map[string]error
+		opts             []brainlocal.Option
+		expectCalls      []string
+		expectFailures   int
+	}{
+		{"run succeeds", nil, nil, nil, nil, 0},
+		{"run fails", failErr, nil, nil, []string{"c", "b", "a"}, 0},
+		{"compensation fails", failErr, map[string]error{"b": errors.New("refund failed")}, nil, []string{"c", "b", "a"}, 1},
+		{"never compensate", failErr, nil, []brainlocal.Option{brainlocal.WithCompensationPolicy(core.CompensateNever)}, nil, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls []string
+			brain := brainlocal.BuildBrain(sagaBlueprint(&calls, c.compensationErrs, c.failErr), c.opts...)
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+
+			if !reflect.DeepEqual(calls, c.expectCalls) {
+				t.Errorf("expect compensations %v, got %v", c.expectCalls, calls)
+			}
+			err := brain.GetRunError()
+			if c.failErr != nil && !errors.Is(err, c.failErr) {
+				t.Errorf("expect the run error %v, got %v", c.failErr, err)
+			}
+			var compensationErr *core.CompensationError
+			if errors.As(err, &compensationErr) != (c.expectFailures != 0) ||
+				compensationErr != nil && len(compensationErr.Failures) != c.expectFailures {
+				t.Errorf("expect %d compensation failures, got %v", c.expectFailures, err)
+			}
+		})
+	}
+}
+
+func TestCompensationOncePerRun(t *testing.T) {
+	var calls []string
+	brain := brainlocal.BuildBrain(sagaBlueprint(&calls, nil, errors.New("failed")))
+	defer brain.Shutdown()
+	for i := 0; i < 2; i++ {
+		_ = brain.Entry()
+		brain.Wait()
+	}
+	// the compensations of the first run are not called again by the second
+	if expect := []string{"c", "b", "a", "c", "b", "a"}; !reflect.DeepEqual(calls, expect) {
+		t.Errorf("expect compensations %v, got %v", expect, calls)
+	}
+}
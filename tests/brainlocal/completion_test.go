@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestCompletionProcessor(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("a", 1)
+	})
+	b := bp.AddNeuron(func(bc processor.BrainContext) error {
+		if bc.GetMemory("fail") == true {
+			return errProcess
+		}
+		return bc.SetMemory("b", 2)
+	})
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddEndLinkFrom(b)
+	_, _ = bp.AddLink(b, bp.AddEndNeuron("alt"))
+
+	var calls int32
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	brain.SetCompletionProcessor(processor.NewFuncProcessor(func(bc processor.BrainContext) error {
+		atomic.AddInt32(&calls, 1)
+		if bc.GetCurrentNeuronID() != core.CompletionNeuronID {
+			t.Errorf("expect current neuron %s, got %s", core.CompletionNeuronID, bc.GetCurrentNeuronID())
+		}
+		if bc.GetMemory("abort") == true {
+			return errProcess
+		}
+		return bc.SetMemory("total", bc.GetMemory("a").(int)+bc.GetMemory("b").(int))
+	}))
+
+	// run once, though two End neurons are linked
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("expect no run error, got %v", err)
+	}
+	if total := brain.GetMemory("total"); total != 3 {
+		t.Errorf("expect total 3 when Wait returns, got %v", total)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expect completion processor run once, got %d", n)
+	}
+
+	// a failed run does not complete
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory("fail", true)
+	brain.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expect completion processor not run for a failed run, got %d runs", n)
+	}
+	if brain.ExistMemory("total") {
+		t.Errorf("expect no total for a failed run")
+	}
+
+	// the error of the completion processor is the run error
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory("abort", true)
+	brain.Wait()
+	if err := brain.GetRunError(); !errors.Is(err, errProcess) {
+		t.Errorf("expect run error %v, got %v", errProcess, err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expect completion processor run twice, got %d", n)
+	}
+}
+
+func TestCompletionProcessorSkippedOnCancel(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	// ends the run, the run context is cancelled as it reaches End
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return processor.SelectEnd
+	}))
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	var calls int32
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithCancelOnSelectEnd())
+	defer brain.Shutdown()
+	brain.SetCompletionProcessor(processor.NewFuncProcessor(func(bc processor.BrainContext) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}))
+
+	_ = brain.Entry()
+	brain.Wait()
+	if ends := brain.GetReachedEnds(); len(ends) != 1 {
+		t.Errorf("expect the End neuron reached, got %v", ends)
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("expect completion processor not run, got %d", n)
+	}
+	if err := brain.GetRunError(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect run error context.Canceled, got %v", err)
+	}
+}
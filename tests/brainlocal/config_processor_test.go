@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestConfigProcessorSeedsRuns(t *testing.T) {
+	var mu sync.Mutex
+	resolved := 0
+	bp := rModel.NewBlueprint()
+	// the config neuron processes after the reader, its config is in memory since the start of the run
+	read := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("out", bc.GetMemory("region"))
+	})
+	config := bp.AddNeuronWithProcessor(processor.NewConfigProcessor(func() (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resolved++
+		return map[string]interface{}{"region": "eu"}, nil
+	}))
+	_, _ = bp.AddEntryLinkTo(read)
+	_, _ = bp.AddLink(read, config)
+	_, _ = bp.AddEndLinkFrom(config)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	if resolved != 1 {
+		t.Fatalf("expect the config resolved at build, got %d", resolved)
+	}
+	for i := 0; i < 2; i++ {
+		_ = brain.SetMemory("out", nil)
+		if err := brain.Entry(); err != nil {
+			t.Fatalf("entry error: %v", err)
+		}
+		brain.Wait()
+		if brain.GetMemory("out") != "eu" {
+			t.Errorf("run %d: expect the config seeded before the reader, got %v", i, brain.GetMemory("out"))
+		}
+	}
+
+	results, err := brain.RunBatch(context.Background(), []map[string]any{{}, {}}, 2, core.WithOutputKeys("out"))
+	if err != nil {
+		t.Fatalf("run batch error: %v", err)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Memory["out"] != "eu" {
+			t.Errorf("result %d: unexpected %+v", i, r)
+		}
+	}
+	if resolved != 1 {
+		t.Errorf("expect the config resolved once, got %d", resolved)
+	}
+}
+
+func TestConfigProcessorFailsBuild(t *testing.T) {
+	missing := errors.New("CONFIG_PATH not set")
+	bp := rModel.NewBlueprint()
+	config := bp.AddNeuronWithProcessor(processor.NewConfigProcessor(func() (map[string]interface{}, error) {
+		return nil, missing
+	}))
+	_, _ = bp.AddEntryLinkTo(config)
+	_, _ = bp.AddEndLinkFrom(config)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	if err := brain.Entry(); !errors.Is(err, missing) {
+		t.Errorf("expect entry error %v, got %v", missing, err)
+	}
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestCriticalPath(t *testing.T) {
+	sleep := func(d time.Duration) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			time.Sleep(d)
+			return nil
+		}
+	}
+	// fast and slow join at merge, the slowest process (slow) is on the path, the second slowest (side) is not
+	bp := rModel.NewBlueprint()
+	fast := bp.AddNeuron(sleep(5 * time.Millisecond))
+	slow := bp.AddNeuron(sleep(60 * time.Millisecond))
+	side := bp.AddNeuron(sleep(30 * time.Millisecond))
+	merge := bp.AddNeuron(sleep(time.Millisecond))
+	last := bp.AddNeuron(sleep(20 * time.Millisecond))
+	_, _ = bp.AddEntryLinkTo(fast)
+	_, _ = bp.AddEntryLinkTo(slow)
+	_, _ = bp.AddEntryLinkTo(side)
+	fromFast, _ := bp.AddLink(fast, merge)
+	fromSlow, _ := bp.AddLink(slow, merge)
+	_ = merge.AddTriggerGroup(fromFast, fromSlow)
+	_, _ = bp.AddLink(merge, last)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+
+	expect := []string{slow.GetID(), merge.GetID(), last.GetID()}
+	if path := brain.GetRunTrace().CriticalPath(); !reflect.DeepEqual(path, expect) {
+		t.Errorf("expect critical path %v, got %v", expect, path)
+	}
+	if path := (core.Trace{}).CriticalPath(); len(path) != 0 {
+		t.Errorf("expect no critical path of an empty trace, got %v", path)
+	}
+}
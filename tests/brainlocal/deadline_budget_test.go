@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestDeadlineBudget(t *testing.T) {
+	var mu sync.Mutex
+	timeouts := make(map[string]time.Duration)
+	process := func(sleep time.Duration) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			deadline, ok := bc.Context().Deadline()
+			if !ok {
+				return errors.New("no deadline")
+			}
+			mu.Lock()
+			timeouts[bc.GetCurrentNeuronID()] = time.Until(deadline)
+			mu.Unlock()
+			time.Sleep(sleep)
+			return nil
+		}
+	}
+	bp := rModel.NewBlueprint()
+	first := bp.AddNeuron(process(100 * time.Millisecond))
+	second := bp.AddNeuron(process(0))
+	_, _ = bp.AddEntryLinkTo(first)
+	_, _ = bp.AddLink(first, second)
+	_, _ = bp.AddEndLinkFrom(second)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithDeadlineBudget(400*time.Millisecond, core.BudgetEqualSplit))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	// the first gets half of the budget, the second the rest after the first
+	if d := timeouts[first.GetID()]; d > 200*time.Millisecond || d < 150*time.Millisecond {
+		t.Errorf("expect the first neuron to get about half of the budget, got %s", d)
+	}
+	if d := timeouts[second.GetID()]; d > 300*time.Millisecond || d < 200*time.Millisecond {
+		t.Errorf("expect the second neuron to get the budget left, got %s", d)
+	}
+
+	// the budget is spent before the second neuron
+	spent := brainlocal.BuildBrain(bp, brainlocal.WithDeadlineBudget(50*time.Millisecond, core.BudgetEqualSplit))
+	defer spent.Shutdown()
+	_ = spent.Entry()
+	spent.Wait()
+	if err := spent.GetRunError(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect the run failed by the spent budget, got %v", err)
+	}
+}
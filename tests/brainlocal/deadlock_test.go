@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestDeadlockDetection(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	pass := func(bc processor.BrainContext) error {
+		return nil
+	}
+	// route only selects x, but join waits for both x and y
+	route := bp.AddNeuron(pass, core.WithSelector(processor.NewFuncSelector(func(bc processor.BrainContextReader) string {
+		return "toX"
+	})))
+	x := bp.AddNeuron(pass)
+	y := bp.AddNeuron(pass)
+	join := bp.AddNeuron(pass)
+
+	_, _ = bp.AddEntryLinkTo(route)
+	routeX, _ := bp.AddLink(route, x)
+	routeY, _ := bp.AddLink(route, y)
+	xJoin, _ := bp.AddLink(x, join)
+	yJoin, _ := bp.AddLink(y, join)
+	_, _ = bp.AddEndLinkFrom(join)
+	_ = route.AddCastGroup("toX", routeX)
+	_ = route.AddCastGroup("toY", routeY)
+	_ = join.AddTriggerGroup(xJoin, yJoin)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithDeadlockDetection())
+	defer brain.Shutdown()
+
+	_ = brain.Entry()
+	brain.Wait()
+
+	err := brain.GetRunError()
+	if !errors.Is(err, core.ErrDeadlock) {
+		t.Fatalf("expect deadlock error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), join.GetID()) || !strings.Contains(err.Error(), yJoin.GetID()) {
+		t.Errorf("expect stuck neuron %s waiting for link %s, got %s", join.GetID(), yJoin.GetID(), err)
+	}
+	if len(brain.GetReachedEnds()) != 0 {
+		t.Errorf("expect no End reached, got %v", brain.GetReachedEnds())
+	}
+
+	// a brain reaching End has no deadlock
+	_ = brain.Reset()
+	_ = brain.TrigLinks(xJoin, yJoin)
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Errorf("expect no run error, got %s", err)
+	}
+	if len(brain.GetReachedEnds()) != 1 {
+		t.Errorf("expect End reached, got %v", brain.GetReachedEnds())
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// loggingSelector casts the default group, and logs the neurons selecting, shared by its clones
+type loggingSelector struct {
+	mu      *sync.Mutex
+	neurons *[]string
+}
+
+func (s *loggingSelector) Select(bcr processor.BrainContextReader) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.neurons = append(*s.neurons, bcr.GetCurrentNeuronID())
+	return processor.DefaultCastGroupName
+}
+
+func (s *loggingSelector) Clone() processor.Selector {
+	return &loggingSelector{mu: s.mu, neurons: s.neurons}
+}
+
+func TestDefaultSelector(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	explicit := 0
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		explicit++
+		return processor.DefaultCastGroupName
+	}))
+	c := bp.AddNeuron(noop)
+	// the DefaultSelector bound explicitly is kept too
+	d := bp.AddNeuron(noop, core.WithSelector(&processor.DefaultSelector{}))
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(b, c)
+	_, _ = bp.AddLink(c, d)
+	_, _ = bp.AddEndLinkFrom(d)
+
+	logged := make([]string, 0)
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithDefaultSelector(&loggingSelector{mu: &sync.Mutex{}, neurons: &logged}))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil || len(brain.GetReachedEnds()) != 1 {
+		t.Fatalf("expect the run to reach the end, got error %v and ends %v", err, brain.GetReachedEnds())
+	}
+
+	// the explicit selectors of b and d take precedence
+	expect := []string{a.GetID(), c.GetID()}
+	sort.Strings(expect)
+	sort.Strings(logged)
+	if !reflect.DeepEqual(logged, expect) || explicit != 1 {
+		t.Errorf("expect the default selector used by %v and the explicit one once, got %v and %d", expect, logged, explicit)
+	}
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestDefer(t *testing.T) {
+	cases := []struct {
+		name    string
+		process func() error
+	}{
+		{"run succeeds", func() error { return nil }},
+		{"run fails", func() error { return errors.New("query failed") }},
+		{"process panics", func() error { panic("query panics") }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls []string
+			bp := rModel.NewBlueprint()
+			open := func(name string, last bool) func(bc processor.BrainContext) error {
+				return func(bc processor.BrainContext) error {
+					bc.Defer(func() {
+						calls = append(calls, name)
+					})
+					if name == "b" {
+						// a panic of a deferred func does not stop the others
+						bc.Defer(func() { panic("close panics") })
+					}
+					if last {
+						return c.process()
+					}
+					return nil
+				}
+			}
+			a, b, d := bp.AddNeuron(open("a", false)), bp.AddNeuron(open("b", false)), bp.AddNeuron(open("c", true))
+			_, _ = bp.AddEntryLinkTo(a)
+			_, _ = bp.AddLink(a, b)
+			_, _ = bp.AddLink(b, d)
+			_, _ = bp.AddEndLinkFrom(d)
+
+			brain := brainlocal.BuildBrain(bp)
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+
+			if expect := []string{"c", "b", "a"}; !reflect.DeepEqual(calls, expect) {
+				t.Errorf("expect deferred funcs %v, got %v", expect, calls)
+			}
+		})
+	}
+}
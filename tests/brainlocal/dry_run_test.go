@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestDryRun(t *testing.T) {
+	var calls int32
+	sideEffect := func(bc processor.BrainContext) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+	bp := rModel.NewBlueprint()
+	router := bp.AddNeuron(sideEffect, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		atomic.AddInt32(&calls, 1)
+		return "a"
+	}))
+	a := bp.AddNeuron(sideEffect)
+	b := bp.AddNeuron(sideEffect)
+	join := bp.AddNeuron(sideEffect)
+	_, _ = bp.AddEntryLinkTo(router)
+	toA, _ := bp.AddLink(router, a)
+	toB, _ := bp.AddLink(router, b)
+	_ = router.AddCastGroup("a", toA)
+	_ = router.AddCastGroup("b", toB)
+	fromA, _ := bp.AddLink(a, join)
+	fromB, _ := bp.AddLink(b, join)
+	_ = join.AddTriggerGroup(fromA, fromB)
+	_, _ = bp.AddEndLinkFrom(join)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	// the join waits for b forever
+	result := brain.DryRun(core.Scenario{Selections: map[string]string{router.GetID(): "a"}})
+	if !reflect.DeepEqual(result.Executed, []string{router.GetID(), a.GetID()}) {
+		t.Errorf("expect router and a executed, got %v", result.Executed)
+	}
+	if result.EndReached() || !errors.Is(result.Err, core.ErrDeadlock) {
+		t.Errorf("expect End not reached with ErrDeadlock, got %v %v", result.ReachedEnds, result.Err)
+	}
+
+	result = brain.DryRun(core.Scenario{AllBranches: true})
+	if len(result.Executed) != 4 || result.Executed[3] != join.GetID() {
+		t.Errorf("expect all neurons executed, got %v", result.Executed)
+	}
+	if !result.EndReached() {
+		t.Errorf("expect End reached, got %v %v", result.ReachedEnds, result.Err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("expect no processor or selector invoked, got %d calls", n)
+	}
+	if brain.GetState() == core.BrainStateRunning || len(brain.GetRunTrace()) != 0 {
+		t.Errorf("expect the brain untouched by dry runs")
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestEmptyBrain(t *testing.T) {
+	brain := brainlocal.BuildBrain(rModel.NewBlueprint())
+	defer brain.Shutdown()
+	if err := brain.Entry(); !errors.Is(err, core.ErrNoNeurons) {
+		t.Fatalf("expect ErrNoNeurons, got %v", err)
+	}
+	// nothing is running
+	brain.Wait()
+}
+
+func TestSingleNeuronBrain(t *testing.T) {
+	for _, withEnd := range []bool{true, false} {
+		processed := 0
+		bp := rModel.NewBlueprint()
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			processed++
+			return bc.SetMemory("out", bc.GetMemory("in").(int)+1)
+		})
+		_, _ = bp.AddEntryLinkTo(n)
+		if withEnd {
+			_, _ = bp.AddEndLinkFrom(n)
+		}
+		if err := bp.Validate(); err != nil {
+			t.Fatalf("end %v: validate error: %s", withEnd, err)
+		}
+
+		brain := brainlocal.BuildBrain(bp)
+		if err := brain.EntryWithMemory("in", 1); err != nil {
+			t.Fatalf("end %v: entry error: %s", withEnd, err)
+		}
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil || processed != 1 || brain.GetMemory("out") != 2 {
+			t.Errorf("end %v: expect one process with output 2, got error %v, %d processes, output %v",
+				withEnd, err, processed, brain.GetMemory("out"))
+		}
+		if withEnd && len(brain.GetReachedEnds()) != 1 {
+			t.Errorf("expect the End neuron reached, got %v", brain.GetReachedEnds())
+		}
+		brain.Shutdown()
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestNamedEnds(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	check := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	success := bp.AddEndNeuron("success")
+	failure := bp.AddEndNeuron("failure")
+
+	_, _ = bp.AddEntryLinkTo(check)
+	successLink, _ := bp.AddLink(check, success)
+	failureLink, _ := bp.AddLink(check, failure)
+	_ = check.AddCastGroup("success", successLink)
+	_ = check.AddCastGroup("failure", failureLink)
+	check.BindCastGroupSelectFunc(func(bcr processor.BrainContextReader) string {
+		if bcr.GetMemory("ok").(bool) {
+			return "success"
+		}
+		return "failure"
+	})
+
+	if !successLink.IsEndLink() || !failureLink.IsEndLink() {
+		t.Fatalf("links to named end neurons should be end links")
+	}
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	_ = brain.EntryWithMemory("ok", true)
+	brain.Wait()
+	if ends := brain.GetReachedEnds(); len(ends) != 1 || ends[0] != success.GetID() {
+		t.Errorf("expect reached end %s, got %v", success.GetID(), ends)
+	}
+
+	_ = brain.EntryWithMemory("ok", false)
+	brain.Wait()
+	if ends := brain.GetReachedEnds(); len(ends) != 1 || ends[0] != failure.GetID() {
+		t.Errorf("expect reached end %s, got %v", failure.GetID(), ends)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+var (
+	errTimeout  = errors.New("timeout")
+	errNotFound = errors.New("not found")
+)
+
+func TestErrorAwareSelector(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	call := bp.AddNeuron(func(bc processor.BrainContext) error {
+		if err, ok := bc.GetMemory("err").(error); ok {
+			return err
+		}
+		return nil
+	})
+	mark := func(key string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			return bc.SetMemory(key, true)
+		}
+	}
+	done := bp.AddNeuron(mark("done"))
+	retry := bp.AddNeuron(mark("retry"))
+	fallback := bp.AddNeuron(mark("fallback"))
+	_, _ = bp.AddEntryLinkTo(call)
+	toDone, _ := bp.AddLink(call, done)
+	toRetry, _ := bp.AddLink(call, retry)
+	toFallback, _ := bp.AddLink(call, fallback)
+	_, _ = bp.AddEndLinkFrom(done)
+	_, _ = bp.AddEndLinkFrom(retry)
+	_, _ = bp.AddEndLinkFrom(fallback)
+	_ = call.AddCastGroup("done", toDone)
+	_ = call.AddCastGroup("retry", toRetry)
+	_ = call.AddCastGroup("fallback", toFallback)
+	call.BindCastGroupSelector(processor.NewErrorAwareFuncSelector(
+		func(bcr processor.BrainContextReader) string {
+			return "done"
+		},
+		func(bcr processor.BrainContextReader, err error) string {
+			switch {
+			case errors.Is(err, errTimeout):
+				return "retry"
+			case errors.Is(err, errNotFound):
+				return "fallback"
+			}
+			return ""
+		},
+	))
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	cases := []struct {
+		name    string
+		err     error
+		route   string
+		wantErr bool
+	}{
+		{name: "success", route: "done"},
+		{name: "timeout", err: errTimeout, route: "retry"},
+		{name: "not found", err: errNotFound, route: "fallback"},
+		{name: "unrouted", err: errors.New("fatal"), wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_ = brain.Reset()
+			if c.err != nil {
+				_ = brain.EntryWithMemory("err", c.err)
+			} else {
+				_ = brain.Entry()
+			}
+			brain.Wait()
+			if err := brain.GetRunError(); (err != nil) != c.wantErr {
+				t.Errorf("expect run error %v, got %v", c.wantErr, err)
+			}
+			for _, key := range []string{"done", "retry", "fallback"} {
+				if want := key == c.route; brain.ExistMemory(key) != want {
+					t.Errorf("expect memory %s exist %v", key, want)
+				}
+			}
+		})
+	}
+}
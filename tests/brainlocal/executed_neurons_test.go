@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestGetExecutedNeurons(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	b := bp.AddNeuron(func(bc processor.BrainContext) error {
+		close(started)
+		<-release
+		return nil
+	})
+	c := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(b, c)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+
+	// poll while the brain is running
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = brain.GetExecutedNeurons()
+		}
+	}()
+	<-started
+	if got := brain.GetExecutedNeurons(); !reflect.DeepEqual(got, []string{a.GetID()}) {
+		t.Errorf("expect executed neurons [%s] mid-run, got %v", a.GetID(), got)
+	}
+	close(release)
+	brain.Wait()
+	<-done
+
+	expect := []string{a.GetID(), b.GetID(), c.GetID()}
+	if got := brain.GetExecutedNeurons(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect executed neurons %v, got %v", expect, got)
+	}
+}
+
+func TestGetExecutedNeuronsMinimalRetention(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop)
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithResultRetention(core.ResultRetentionMinimal))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if expect, got := []string{a.GetID(), b.GetID()}, brain.GetExecutedNeurons(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect executed neurons %v without the trace, got %v", expect, got)
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestExecutionOrder(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	names := make(map[string]string)
+	add := func(name string) core.Neuron {
+		n := bp.AddNeuron(noop)
+		names[n.GetID()] = name
+		return n
+	}
+	// S fans out to A, B and C, which join at J, T runs alone
+	s, t1 := add("S"), add("T")
+	a, b, c, j := add("A"), add("B"), add("C"), add("J")
+	toS, _ := bp.AddEntryLinkTo(s)
+	toT, _ := bp.AddEntryLinkTo(t1)
+	fanOut := make([]core.Link, 0, 3)
+	joinIn := make([]core.Link, 0, 3)
+	for _, n := range []core.Neuron{a, b, c} {
+		l, _ := bp.AddLink(s, n)
+		fanOut = append(fanOut, l)
+		in, _ := bp.AddLink(n, j)
+		joinIn = append(joinIn, in)
+	}
+	_ = j.AddTriggerGroup(joinIn...)
+
+	// the golden sequence: entries then the fan-out by link ID, the join last
+	entries := []core.Link{toS, toT}
+	byID := func(links []core.Link) []string {
+		sort.Slice(links, func(i, k int) bool { return links[i].GetID() < links[k].GetID() })
+		seq := make([]string, 0, len(links))
+		for _, l := range links {
+			seq = append(seq, names[l.GetDestNeuronID()])
+		}
+		return seq
+	}
+	expect := append(byID(entries), byID(fanOut)...)
+	expect = append(expect, "J")
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(1))
+	defer brain.Shutdown()
+	for i := 0; i < 20; i++ {
+		_ = brain.Reset()
+		_ = brain.Entry()
+		brain.Wait()
+		got := make([]string, 0, len(expect))
+		for _, e := range brain.GetRunTrace() {
+			got = append(got, names[e.NeuronID])
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Fatalf("run %d: expect execution order %v, got %v", i, expect, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestFailureReport(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	charge := bp.AddNeuron(func(bc processor.BrainContext) error {
+		switch bc.GetMemory("mode") {
+		case "panic":
+			var m map[string]int
+			m["boom"]++
+		case "error":
+			return errors.New("card declined")
+		}
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(charge)
+	_, _ = bp.AddEndLinkFrom(charge)
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	inputs := []map[string]any{
+		{"mode": "panic", "user": "u1", "token": "secret"},
+		{"mode": "error", "user": "u2", "token": "secret"},
+		{"mode": "ok", "user": "u3", "token": "secret"},
+	}
+	results, err := brain.RunBatch(context.Background(), inputs, 2, core.WithOutputKeys("user", "token"),
+		core.WithFailureRedaction(func(key string) bool { return key == "token" }))
+	if err != nil {
+		t.Fatalf("run batch error: %v", err)
+	}
+
+	var panicErr *core.PanicError
+	if !errors.As(results[0].Err, &panicErr) {
+		t.Errorf("expect the panic recovered as the run error, got %v", results[0].Err)
+	}
+	failures := results[0].Failures()
+	if len(failures) != 1 || !failures[0].Panic || failures[0].NeuronID != charge.GetID() ||
+		failures[0].Kind != "func" || !strings.Contains(failures[0].Stack, "failure_report_test.go") {
+		t.Errorf("expect a panic report of the neuron with the stack, got %+v", failures)
+	}
+	failures = results[1].Failures()
+	if len(failures) != 1 || failures[0].Panic || failures[0].Message != "card declined" || failures[0].Stack != "" {
+		t.Errorf("expect an error report, got %+v", failures)
+	}
+	if len(results[2].Failures()) != 0 {
+		t.Errorf("expect no failure of the succeeded run, got %+v", results[2].Failures())
+	}
+
+	// the report is serializable, without the redacted memory
+	data, err := json.Marshal(results[1].Failures()[0])
+	if err != nil {
+		t.Fatalf("marshal report error: %v", err)
+	}
+	var report core.FailureReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("unmarshal report error: %v", err)
+	}
+	if report.Memory["user"] != "u2" || strings.Contains(string(data), "secret") {
+		t.Errorf("expect memory user without token, got %s", data)
+	}
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestResultFinalMemory(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		bc.DeleteMemory("scratch")
+		return bc.SetMemory("out", bc.GetMemory("in").(int)*2, 7, "seven")
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+
+	for _, retention := range []core.ResultRetention{core.ResultRetentionFull, core.ResultRetentionMinimal} {
+		brain := brainlocal.BuildBrain(bp, brainlocal.WithResultRetention(retention))
+		inputs := []map[string]any{{"in": 1, "scratch": true}, {"in": 2}}
+		results, err := brain.RunBatch(context.Background(), inputs, 1)
+		brain.Shutdown()
+		if err != nil {
+			t.Fatalf("run batch error: %s", err)
+		}
+		for i, r := range results {
+			expect := map[string]interface{}{"in": i + 1, "out": 2 * (i + 1), "7": "seven"}
+			memory := r.FinalMemory()
+			if !reflect.DeepEqual(memory, expect) {
+				t.Errorf("retention %d result %d: expect final memory %v, got %v", retention, i, expect, memory)
+			}
+			// a defensive copy
+			memory["out"] = 0
+			if r.FinalMemory()["out"] != 2*(i+1) {
+				t.Errorf("expect the final memory not changed by its copy")
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestCastGroupAlias(t *testing.T) {
+	var mu sync.Mutex
+	ran := make(map[string]bool)
+	mark := func(name string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			mu.Lock()
+			ran[name] = true
+			mu.Unlock()
+			return nil
+		}
+	}
+	alwaysSuccess := processor.NewFuncSelector(func(bcr processor.BrainContextReader) string {
+		return "success"
+	})
+
+	bp := rModel.NewBlueprint()
+	check := bp.AddNeuron(mark("check"), core.WithSelector(alwaysSuccess))
+	verify := bp.AddNeuron(mark("verify"), core.WithSelector(alwaysSuccess))
+	retry := bp.AddNeuron(mark("retry"))
+	passed := bp.AddNeuron(mark("passed"))
+
+	_, _ = bp.AddEntryLinkTo(check)
+	toVerify, _ := bp.AddLink(check, verify)
+	toRetry, _ := bp.AddLink(check, retry)
+	toPassed, _ := bp.AddLink(verify, passed)
+	_ = check.AddCastGroup("ok", toVerify)
+	_ = check.AddCastGroup("retry", toRetry)
+	_ = verify.AddCastGroup("passed", toPassed)
+
+	if err := bp.DefineGroupAlias("success", map[string]string{
+		check.GetID():  "ok",
+		verify.GetID(): "passed",
+	}); err != nil {
+		t.Fatalf("define group alias error: %s", err)
+	}
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !ran["verify"] || !ran["passed"] {
+		t.Errorf("alias should route to the underlying groups, ran: %v", ran)
+	}
+	if ran["retry"] {
+		t.Errorf("retry group should not be cast")
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+type runRecord struct {
+	initialMemory map[any]any
+	result        core.RunResult
+	err           error
+	ends          int
+}
+
+func TestRunHooks(t *testing.T) {
+	var mu sync.Mutex
+	runs := make(map[string]*runRecord)
+	order := make([]string, 0)
+	hooks := core.Hooks{
+		OnRunStart: func(runID string, initialMemory map[any]any) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[runID] = &runRecord{initialMemory: initialMemory}
+			order = append(order, runID)
+		},
+		OnRunEnd: func(runID string, result core.RunResult, err error, duration time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[runID].result, runs[runID].err = result, err
+			runs[runID].ends++
+		},
+	}
+
+	bp := rModel.NewBlueprint()
+	work := bp.AddNeuron(func(bc processor.BrainContext) error {
+		switch bc.GetMemory("mode") {
+		case "abort":
+			return processor.AbortRun("stop")
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(work)
+	_, _ = bp.AddEndLinkFrom(work)
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithHooks(hooks))
+
+	_ = brain.SetMemory("discarded", true)
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory("mode", "ok")
+	brain.Wait()
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory("mode", "abort")
+	brain.Wait()
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory("mode", "slow")
+	time.Sleep(20 * time.Millisecond)
+	// the run is still processing, shutdown ends it
+	brain.Shutdown()
+	time.Sleep(150 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 3 {
+		t.Fatalf("expect 3 runs started, got %v", order)
+	}
+	ok, aborted, shutdown := runs[order[0]], runs[order[1]], runs[order[2]]
+	for _, r := range []*runRecord{ok, aborted, shutdown} {
+		if r.ends != 1 {
+			t.Errorf("expect OnRunEnd once for every run, got %d", r.ends)
+		}
+	}
+	if !reflect.DeepEqual(ok.initialMemory, map[any]any{"mode": "ok"}) {
+		t.Errorf("expect the initial memory of the entry, got %v", ok.initialMemory)
+	}
+	if ok.err != nil || len(ok.result.ReachedEnds) != 1 || len(ok.result.Trace) != 1 {
+		t.Errorf("expect the run reaching the end with 1 process, got %+v, err %v", ok.result, ok.err)
+	}
+	if !errors.Is(aborted.err, processor.ErrAbortRun) {
+		t.Errorf("expect the aborted run with error %v, got %v", processor.ErrAbortRun, aborted.err)
+	}
+	if len(shutdown.result.ReachedEnds) != 0 {
+		t.Errorf("expect no end reached by the run shut down, got %v", shutdown.result.ReachedEnds)
+	}
+}
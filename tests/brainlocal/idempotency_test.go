@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	keys := make([]string, 0)
+	bp := rModel.NewBlueprint()
+	// loop runs twice, each process has its own key
+	loop := bp.AddNeuron(func(bc processor.BrainContext) error {
+		mu.Lock()
+		keys = append(keys, bc.IdempotencyKey())
+		mu.Unlock()
+		n, _ := bc.GetMemory("n").(int)
+		return bc.SetMemory("n", n+1)
+	}, core.WithMaxRevisits(1), core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		if bcr.GetMemory("n").(int) < 2 {
+			return processor.DefaultCastGroupName
+		}
+		return processor.SelectEnd
+	}))
+	_, _ = bp.AddEntryLinkTo(loop)
+	_, _ = bp.AddLink(loop, loop)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.EntryWithMemory(processor.IdempotencyMemoryKey, "req-1")
+	brain.Wait()
+	expect := []string{fmt.Sprintf("req-1/%s/1", loop.GetID()), fmt.Sprintf("req-1/%s/2", loop.GetID())}
+	if len(keys) != 2 || keys[0] != expect[0] || keys[1] != expect[1] {
+		t.Errorf("expect keys %v, got %v", expect, keys)
+	}
+
+	// a rerun of the same request after reset gets the same keys
+	keys = keys[:0]
+	_ = brain.Reset()
+	_ = brain.EntryWithMemory(processor.IdempotencyMemoryKey, "req-1")
+	brain.Wait()
+	if len(keys) != 2 || keys[0] != expect[0] || keys[1] != expect[1] {
+		t.Errorf("expect keys %v again, got %v", expect, keys)
+	}
+
+	// so does a rerun without reset, the processes are counted per run
+	keys = keys[:0]
+	_ = brain.SetMemory("n", 0)
+	_ = brain.EntryWithMemory(processor.IdempotencyMemoryKey, "req-1")
+	brain.Wait()
+	if len(keys) != 2 || keys[0] != expect[0] || keys[1] != expect[1] {
+		t.Errorf("expect keys %v in the rerun, got %v", expect, keys)
+	}
+}
+
+func TestOnceMarkersNotInMemory(t *testing.T) {
+	calls := 0
+	bp := rModel.NewBlueprint()
+	charge := bp.AddNeuronWithProcessor(processor.Once(processor.NewFuncProcessor(func(bc processor.BrainContext) error {
+		calls++
+		return nil
+	}), func(bcr processor.BrainContextReader) string {
+		return bcr.GetMemory("orderID").(string)
+	}))
+	_, _ = bp.AddEntryLinkTo(charge)
+	_, _ = bp.AddEndLinkFrom(charge)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	// the user key looking like a marker is not taken for one
+	results, err := brain.RunBatch(context.Background(), []map[string]interface{}{
+		{"orderID": "o-1", "once.o-1": true},
+	}, 1)
+	if err != nil || results[0].Err != nil {
+		t.Fatalf("run error: %v %v", err, results[0].Err)
+	}
+	if calls != 1 {
+		t.Fatalf("expect the processor called once, got %d", calls)
+	}
+	for key := range results[0].FinalMemory() {
+		if strings.HasPrefix(key, processor.OnceMemoryKeyPrefix) {
+			t.Errorf("expect the marker %s not in the final memory", key)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestInputDefaults(t *testing.T) {
+	cases := []struct {
+		name   string
+		entry  []interface{}
+		expect []interface{}
+	}{
+		{"defaults fill the unset keys", nil, []interface{}{10, "en", true}},
+		{"upstream values are kept", []interface{}{"limit", 3}, []interface{}{3, "en", true}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []interface{}
+			var downstream bool
+			bp := rModel.NewBlueprint()
+			n := bp.AddNeuron(func(bc processor.BrainContext) error {
+				got = []interface{}{bc.GetMemory("limit"), bc.GetMemory("lang"), bc.ExistMemory("lang")}
+				return nil
+			}, core.WithInputDefaults(map[string]interface{}{"limit": 10, "lang": "en"}))
+			next := bp.AddNeuron(func(bc processor.BrainContext) error {
+				downstream = bc.ExistMemory("lang")
+				return nil
+			})
+			_, _ = bp.AddEntryLinkTo(n)
+			_, _ = bp.AddLink(n, next)
+
+			brain := brainlocal.BuildBrain(bp)
+			defer brain.Shutdown()
+			_ = brain.EntryWithMemory(c.entry...)
+			brain.Wait()
+			if !reflect.DeepEqual(got, c.expect) {
+				t.Errorf("expect inputs %v, got %v", c.expect, got)
+			}
+			// the defaults are not written to the memory
+			if downstream || brain.ExistMemory("lang") {
+				t.Errorf("expect the default not in the memory")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestLinkSignalCount(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	noop := func(bc processor.BrainContext) error { return nil }
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop)
+	c := bp.AddNeuron(noop)
+	join := bp.AddNeuron(noop)
+
+	entry, _ := bp.AddEntryLinkTo(a)
+	ab, _ := bp.AddLink(a, b)
+	ac, _ := bp.AddLink(a, c)
+	bJoin, _ := bp.AddLink(b, join)
+	cJoin, _ := bp.AddLink(c, join)
+	end, _ := bp.AddEndLinkFrom(join)
+	links := []core.Link{entry, ab, ac, bJoin, cJoin, end}
+	_ = join.AddTriggerGroup(bJoin, cJoin)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(4))
+	defer brain.Shutdown()
+
+	if cnt := brain.LinkSignalCount(entry.GetID()); cnt != 0 {
+		t.Errorf("expect no signal before the run, got %d", cnt)
+	}
+	// each link delivers exactly one signal per run, the counts restart with every run
+	for i := 0; i < 20; i++ {
+		_ = brain.Entry()
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			t.Fatalf("run %d: expect no run error, got %v", i, err)
+		}
+		for _, l := range links {
+			if cnt := brain.LinkSignalCount(l.GetID()); cnt != 1 {
+				t.Errorf("run %d: expect 1 signal of link %s, got %d", i, l.GetID(), cnt)
+			}
+		}
+	}
+
+	if cnt := brain.LinkSignalCount("not-exist"); cnt != 0 {
+		t.Errorf("expect no signal of a missing link, got %d", cnt)
+	}
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestLocalStore(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	var seenUpstream, seenRerun bool
+	sum := bp.AddNeuron(func(bc processor.BrainContext) error {
+		seenRerun = seenRerun || bc.Local().Exist("partial")
+		for _, v := range bc.GetMemory("in").([]int) {
+			partial, _ := bc.Local().Get("partial").(int)
+			bc.Local().Set("partial", partial+v)
+		}
+		return bc.SetMemory("sum", bc.Local().Get("partial"))
+	})
+	next := bp.AddNeuron(func(bc processor.BrainContext) error {
+		seenUpstream = bc.Local().Exist("partial")
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(sum)
+	_, _ = bp.AddLink(sum, next)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	inputs := []map[string]any{{"in": []int{1, 2, 3}}, {"in": []int{4}}}
+	results, err := brain.RunBatch(context.Background(), inputs, 1)
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	for i, expect := range []int{6, 4} {
+		memory := results[i].FinalMemory()
+		if !reflect.DeepEqual(memory, map[string]interface{}{"in": inputs[i]["in"], "sum": expect}) {
+			t.Errorf("result %d: expect the locals out of the memory, got %v", i, memory)
+		}
+	}
+	if seenUpstream || seenRerun {
+		t.Errorf("expect the locals discarded after the process, seen downstream %v, seen by the next run %v", seenUpstream, seenRerun)
+	}
+}
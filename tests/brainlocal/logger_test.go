@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of the brain
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), s)
+}
+
+func TestLogSampling(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	out := &syncBuffer{}
+	logger := zerolog.New(out).Level(zerolog.DebugLevel)
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithLogger(logger), brainlocal.WithLogSampling(3))
+	defer brain.Shutdown()
+
+	// runs 1 and 4 are sampled
+	expect := []int{1, 1, 1, 2, 2, 2}
+	for i, cnt := range expect {
+		_ = brain.Entry()
+		brain.Wait()
+		if got := out.count("start activate neuron"); got != cnt {
+			t.Errorf("run %d: expect %d detailed logs, got %d", i+1, cnt, got)
+		}
+	}
+}
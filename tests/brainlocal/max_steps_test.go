@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestMaxSteps(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	count := func(bc processor.BrainContext) error {
+		n, _ := bc.GetMemory("count").(int)
+		return bc.SetMemory("count", n+1)
+	}
+	ping := bp.AddNeuron(count)
+	pong := bp.AddNeuron(count)
+	_, _ = bp.AddEntryLinkTo(ping)
+	_, _ = bp.AddLink(ping, pong)
+	_, _ = bp.AddLink(pong, ping)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMaxSteps(5))
+	defer brain.Shutdown()
+
+	_ = brain.Entry()
+	brain.Wait()
+	err := brain.GetRunError()
+	if !errors.Is(err, core.ErrStepLimitExceeded) {
+		t.Fatalf("expect run error %v, got %v", core.ErrStepLimitExceeded, err)
+	}
+	// the 5th execution is the ping, the 6th pong is not executed
+	for _, want := range []string{"5 neurons executed", "last executed neuron " + ping.GetID(), "neuron " + pong.GetID() + " not executed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expect run error %q to contain %q", err, want)
+		}
+	}
+	if got := brain.GetMemory("count"); got != 5 {
+		t.Errorf("expect 5 executions, got %v", got)
+	}
+
+	// the steps are counted per run
+	_ = brain.Entry()
+	brain.Wait()
+	if err = brain.GetRunError(); !errors.Is(err, core.ErrStepLimitExceeded) {
+		t.Errorf("expect run error %v, got %v", core.ErrStepLimitExceeded, err)
+	}
+	if got := brain.GetMemory("count"); got != 10 {
+		t.Errorf("expect 5 more executions, got %v", got)
+	}
+}
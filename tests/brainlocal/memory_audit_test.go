@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+)
+
+func TestMemoryAudit(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(fn1)
+	n2 := bp.AddNeuron(fn2)
+	_, _ = bp.AddLink(n1, n2)
+	_, _ = bp.AddEntryLinkTo(n1)
+
+	var mu sync.Mutex
+	events := make([]core.MemoryAuditEvent, 0)
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMemoryAudit(func(event core.MemoryAuditEvent) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}, core.WithAuditValue()))
+
+	_ = brain.Entry()
+	brain.Wait()
+	_ = brain.GetMemory("name")
+	brain.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	expect := []struct {
+		neuronID string
+		op       core.MemoryOperation
+		value    any
+	}{
+		{n1.GetID(), core.MemoryOperationWrite, "Clay"},
+		{n2.GetID(), core.MemoryOperationRead, "Clay"},
+		{n2.GetID(), core.MemoryOperationWrite, "Clay Zhang"},
+		{"", core.MemoryOperationRead, "Clay Zhang"},
+	}
+	if len(events) != len(expect) {
+		t.Fatalf("expect %d audit events, got %d: %+v", len(expect), len(events), events)
+	}
+	for i, e := range expect {
+		got := events[i]
+		if got.Seq != uint64(i+1) || got.NeuronID != e.neuronID || got.Operation != e.op || got.Key != "name" || got.Value != e.value {
+			t.Errorf("audit event %d: expect %+v, got %+v", i, e, got)
+		}
+	}
+}
+
+func TestMemoryAuditKeyFilterAndSampling(t *testing.T) {
+	bp := rModel.NewBlueprint()
+
+	cnt := 0
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMemoryAudit(func(event core.MemoryAuditEvent) {
+		cnt++
+		if event.Key != "audited" {
+			t.Errorf("unexpected audited key: %v", event.Key)
+		}
+		if event.Value != nil {
+			t.Errorf("value should not be audited by default")
+		}
+	},
+		core.WithAuditKeyFilter(func(key any) bool { return key == "audited" }),
+		core.WithAuditSampling(2),
+	))
+
+	for i := 0; i < 4; i++ {
+		_ = brain.SetMemory("audited", i, "ignored", i)
+	}
+	if cnt != 2 {
+		t.Errorf("expect 2 sampled audit events, got %d", cnt)
+	}
+}
+
+func TestMemoryAuditOrder(t *testing.T) {
+	bp := rModel.NewBlueprint()
+
+	var brain *brainlocal.BrainLocal
+	events := make([]core.MemoryAuditEvent, 0)
+	brain = brainlocal.BuildBrain(bp, brainlocal.WithMemoryAudit(func(event core.MemoryAuditEvent) {
+		// events are delivered one at a time without any lock held, so the sink can access memory
+		_ = brain.GetMemory("unaudited")
+		events = append(events, event)
+	},
+		core.WithAuditValue(),
+		core.WithAuditKeyFilter(func(key any) bool { return key == "last" }),
+	))
+
+	// init memory before the parallel writes
+	_ = brain.SetMemory("unaudited", true)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = brain.SetMemory("last", i)
+		}(i)
+	}
+	wg.Wait()
+
+	if len(events) != 50 {
+		t.Fatalf("expect 50 audit events, got %d", len(events))
+	}
+	for i, e := range events {
+		if e.Seq != uint64(i+1) {
+			t.Fatalf("audit event %d: expect seq %d, got %d", i, i+1, e.Seq)
+		}
+	}
+	// the last audited write is the value in memory
+	if last := events[len(events)-1].Value; brain.GetMemory("last") != last {
+		t.Errorf("expect memory of the last audited write %v, got %v", last, brain.GetMemory("last"))
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestMemoryInterceptor(t *testing.T) {
+	var mu sync.Mutex
+	intercepted := make([]string, 0)
+	var query, lang interface{}
+	bp := rModel.NewBlueprint()
+	first := bp.AddNeuron(func(bc processor.BrainContext) error {
+		query, lang = bc.GetMemory("query"), bc.GetMemory("lang")
+		return nil
+	}, core.WithInputDefaults(map[string]interface{}{"lang": "en"}))
+	second := bp.AddNeuron(emptyProcess)
+	_, _ = bp.AddEntryLinkTo(first)
+	_, _ = bp.AddLink(first, second)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMemoryInterceptor(func(neuronID string, bc processor.BrainContext) {
+		mu.Lock()
+		intercepted = append(intercepted, neuronID)
+		mu.Unlock()
+		if q, ok := bc.GetMemory("query").(string); ok {
+			_ = bc.SetMemory("query", strings.TrimSpace(q))
+		}
+		// the interceptor reads the input defaults, and replaces them by setting the key
+		if bc.GetMemory("lang") == "en" {
+			_ = bc.SetMemory("lang", "en-US")
+		}
+	}))
+	defer brain.Shutdown()
+	_ = brain.EntryWithMemory("query", "  weather in Boston ")
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if query != "weather in Boston" || lang != "en-US" {
+		t.Errorf("expect the memory changed by the interceptor before the process, got %q %q", query, lang)
+	}
+	if len(intercepted) != 2 || intercepted[0] != first.GetID() || intercepted[1] != second.GetID() {
+		t.Errorf("expect every process intercepted in order, got %v", intercepted)
+	}
+}
+
+func TestMemoryInterceptorPanic(t *testing.T) {
+	processed := false
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		processed = true
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMemoryInterceptor(func(neuronID string, bc processor.BrainContext) {
+		panic("broken interceptor")
+	}))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	var panicErr *core.PanicError
+	if !errors.As(brain.GetRunError(), &panicErr) || processed {
+		t.Errorf("expect the process failed by the panic without running, got %v, processed %v", brain.GetRunError(), processed)
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestMemoryMergeResolver(t *testing.T) {
+	sum := func(values []interface{}) interface{} {
+		total := 0
+		for _, v := range values {
+			total += v.(int)
+		}
+		return total
+	}
+	cases := []struct {
+		name string
+		// values written by the branches, nil if the branch does not write
+		writes []interface{}
+		opts   []core.NeuronOption
+		expect interface{}
+		calls  int
+	}{
+		{"all branches write", []interface{}{1, 2, 4}, nil, 7, 1},
+		{"two of three branches write", []interface{}{1, nil, 4}, nil, 5, 1},
+		{"single writer skipped", []interface{}{nil, 2, nil}, nil, 2, 0},
+		{"single writer merged", []interface{}{nil, 2, nil}, []core.NeuronOption{core.WithMergeSingleWriter()}, 2, 1},
+		{"no writer", []interface{}{nil, nil, nil}, nil, 100, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			var got interface{}
+			bp := rModel.NewBlueprint()
+			// written before the fork, so by none of the branches
+			fork := bp.AddNeuron(func(bc processor.BrainContext) error {
+				return bc.SetMemory("total", 100)
+			})
+			opts := append([]core.NeuronOption{core.WithMemoryMergeResolver("total", func(values []interface{}) interface{} {
+				calls++
+				return sum(values)
+			})}, c.opts...)
+			join := bp.AddNeuron(func(bc processor.BrainContext) error {
+				got = bc.GetMemory("total")
+				return nil
+			}, opts...)
+			_, _ = bp.AddEntryLinkTo(fork)
+			joinIn := make([]core.Link, 0, len(c.writes))
+			for _, w := range c.writes {
+				w := w
+				// the write is one neuron deep into the branch
+				head := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+				tail := bp.AddNeuron(func(bc processor.BrainContext) error {
+					if w == nil {
+						return nil
+					}
+					return bc.SetMemory("total", w)
+				})
+				_, _ = bp.AddLink(fork, head)
+				_, _ = bp.AddLink(head, tail)
+				in, _ := bp.AddLink(tail, join)
+				joinIn = append(joinIn, in)
+			}
+			_ = join.AddTriggerGroup(joinIn...)
+
+			brain := brainlocal.BuildBrain(bp)
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+			if err := brain.GetRunError(); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.expect) || calls != c.calls {
+				t.Errorf("expect total %v merged %d times, got %v merged %d times", c.expect, c.calls, got, calls)
+			}
+		})
+	}
+}
+
+func TestMemoryMergeResolverOrder(t *testing.T) {
+	var got interface{}
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		got = bc.GetMemory("log")
+		return nil
+	}, core.WithMemoryMergeResolver("log", func(values []interface{}) interface{} {
+		return values
+	}))
+	entries := make([]core.Link, 0, 3)
+	joinIn := make([]core.Link, 0, 3)
+	for _, v := range []string{"a", "b", "c"} {
+		v := v
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			return bc.SetMemory("log", v)
+		})
+		entry, _ := bp.AddEntryLinkTo(n)
+		entries = append(entries, entry)
+		in, _ := bp.AddLink(n, join)
+		joinIn = append(joinIn, in)
+	}
+	_ = join.AddTriggerGroup(joinIn...)
+
+	// the branches process one by one in the order of the entry link IDs
+	sort.Slice(entries, func(i, k int) bool { return entries[i].GetID() < entries[k].GetID() })
+	expect := make([]interface{}, 0, 3)
+	for _, l := range entries {
+		for i, n := range joinIn {
+			if n.GetSrcNeuronID() == l.GetDestNeuronID() {
+				expect = append(expect, []string{"a", "b", "c"}[i])
+			}
+		}
+	}
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(1))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect the values in order of write %v, got %v", expect, got)
+	}
+}
+
+func TestMemoryMergeResolverBatch(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("out", bc.GetMemory("total"))
+	}, core.WithMemoryMergeResolver("total", func(values []interface{}) interface{} {
+		total := 0
+		for _, v := range values {
+			total += v.(int)
+		}
+		return total
+	}))
+	joinIn := make([]core.Link, 0, 2)
+	for i := 1; i <= 2; i++ {
+		i := i
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			return bc.SetMemory("total", i*bc.GetMemory("n").(int))
+		})
+		_, _ = bp.AddEntryLinkTo(n)
+		in, _ := bp.AddLink(n, join)
+		joinIn = append(joinIn, in)
+	}
+	_ = join.AddTriggerGroup(joinIn...)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	inputs := []map[string]any{{"n": 1}, {"n": 10}}
+	results, err := brain.RunBatch(context.Background(), inputs, 2, core.WithOutputKeys("out"))
+	if err != nil {
+		t.Fatalf("run batch error: %s", err)
+	}
+	for i, r := range results {
+		if expect := 3 * inputs[i]["n"].(int); r.Err != nil || r.Memory["out"] != expect {
+			t.Errorf("result %d: expect out %d, got %+v", i, expect, r)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+type counterMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (m *counterMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+"{"+labels[core.MetricLabelNeuron]+","+labels[core.MetricLabelGroup]+"}"]++
+}
+
+func TestSelectorChoicesMetric(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	router := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return bcr.GetMemory("route").(string)
+	}))
+	// the default selector is counted too
+	left := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	right := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(router)
+	toLeft, _ := bp.AddLink(router, left)
+	toRight, _ := bp.AddLink(router, right)
+	_ = router.AddCastGroup("left", toLeft)
+	_ = router.AddCastGroup("right", toRight)
+	_, _ = bp.AddEndLinkFrom(left)
+	_, _ = bp.AddEndLinkFrom(right)
+
+	metrics := &counterMetrics{counters: make(map[string]int)}
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMetrics(metrics))
+	defer brain.Shutdown()
+	for _, route := range []string{"left", "right", "left"} {
+		_ = brain.Reset()
+		_ = brain.EntryWithMemory("route", route)
+		brain.Wait()
+	}
+
+	expect := map[string]int{
+		core.MetricSelectorChoicesTotal + "{" + router.GetID() + ",left}":                                  2,
+		core.MetricSelectorChoicesTotal + "{" + router.GetID() + ",right}":                                 1,
+		core.MetricSelectorChoicesTotal + "{" + left.GetID() + "," + processor.DefaultCastGroupName + "}":  2,
+		core.MetricSelectorChoicesTotal + "{" + right.GetID() + "," + processor.DefaultCastGroupName + "}": 1,
+	}
+	if !reflect.DeepEqual(metrics.counters, expect) {
+		t.Errorf("expect counters %v, got %v", expect, metrics.counters)
+	}
+}
+
+// labelsMetrics records the labels of the counters
+type labelsMetrics struct {
+	mu     sync.Mutex
+	labels []map[string]string
+}
+
+func (m *labelsMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels = append(m.labels, labels)
+}
+
+func TestMetricTags(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	}, core.WithNeuronLabels(map[string]string{"requestID": "r-123"}))
+	if err := n.SetMetricTags(map[string]string{"tier": "gold"}); err != nil {
+		t.Fatalf("set metric tags error: %s", err)
+	}
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	metrics := &labelsMetrics{}
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMetrics(metrics))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+
+	// the general labels are not metric dimensions
+	expect := []map[string]string{{
+		core.MetricLabelNeuron: n.GetID(),
+		core.MetricLabelGroup:  processor.DefaultCastGroupName,
+		"tier":                 "gold",
+	}}
+	if !reflect.DeepEqual(metrics.labels, expect) {
+		t.Errorf("expect labels %v, got %v", expect, metrics.labels)
+	}
+
+	tooMany := map[string]string{}
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		tooMany[key] = key
+	}
+	for _, tags := range []map[string]string{tooMany, {core.MetricLabelNeuron: "other"}} {
+		if err := n.SetMetricTags(tags); !errors.Is(err, core.ErrInvalidMetricTags) {
+			t.Errorf("expect ErrInvalidMetricTags for %v, got %v", tags, err)
+		}
+	}
+	if tags := n.GetMetricTags(); !reflect.DeepEqual(tags, map[string]string{"tier": "gold"}) {
+		t.Errorf("expect the tags unchanged by invalid ones, got %v", tags)
+	}
+}
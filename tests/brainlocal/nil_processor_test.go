@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+)
+
+func TestNilProcessor(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuronWithProcessor(nil)
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); !errors.Is(err, core.ErrNilProcessor) {
+		t.Errorf("expect ErrNilProcessor, got %v", err)
+	}
+
+	// an empty processor is substituted when allowed
+	allowed := brainlocal.BuildBrain(bp, brainlocal.WithNilProcessorAllowed())
+	defer allowed.Shutdown()
+	_ = allowed.Entry()
+	allowed.Wait()
+	if err := allowed.GetRunError(); err != nil || len(allowed.GetReachedEnds()) != 1 {
+		t.Errorf("expect the run to reach the end, got error %v and ends %v", err, allowed.GetReachedEnds())
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestSingleCastGroupSkipsSelector(t *testing.T) {
+	mark := func(key string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error { return bc.SetMemory(key, true) }
+	}
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(mark("a"))
+	b := bp.AddNeuron(mark("b"))
+	c := bp.AddNeuron(mark("c"), core.WithSelector(processor.NewNoOpSelector("last")))
+	d := bp.AddNeuron(mark("d"))
+	_, _ = bp.AddEntryLinkTo(a)
+	// the only cast group of a is not the default group
+	ab, _ := bp.AddLink(a, b)
+	_ = a.AddCastGroup("only", ab)
+	_, _ = bp.AddLink(b, c)
+	cd, _ := bp.AddLink(c, d)
+	_ = c.AddCastGroup("last", cd)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if !brain.ExistMemory(key) {
+			t.Errorf("expect neuron %s processed", key)
+		}
+	}
+}
+
+func TestSingleCastGroupIsPossible(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop)
+	_, _ = bp.AddEntryLinkTo(a)
+	ab, _ := bp.AddLink(a, b)
+	_ = a.AddCastGroup("only", ab)
+	_, _ = bp.AddEndLinkFrom(b)
+
+	// the validation and the routing agree with the run, the only cast group is cast
+	report := bp.ValidateReport()
+	if len(report.Errors) != 0 || len(report.Warnings) != 0 {
+		t.Errorf("expect no error nor warning, got %+v", report)
+	}
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	for _, r := range brain.RoutingReport() {
+		if r.NeuronID == a.GetID() && (len(r.PossibleGroups) != 1 || r.PossibleGroups[0] != "only") {
+			t.Errorf("expect possible groups [only], got %v", r.PossibleGroups)
+		}
+	}
+	_ = brain.Entry()
+	brain.Wait()
+	if len(brain.GetReachedEnds()) != 1 {
+		t.Errorf("expect the End reached, got %v", brain.GetReachedEnds())
+	}
+}
+
+func TestBoundDefaultSelectorKeepsDefaultGroup(t *testing.T) {
+	mark := func(key string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error { return bc.SetMemory(key, true) }
+	}
+	bp := rModel.NewBlueprint()
+	// the DefaultSelector bound explicitly selects the default group, which is empty
+	a := bp.AddNeuron(mark("a"), core.WithSelector(&processor.DefaultSelector{}))
+	b := bp.AddNeuron(mark("b"))
+	_, _ = bp.AddEntryLinkTo(a)
+	ab, _ := bp.AddLink(a, b)
+	_ = a.AddCastGroup("only", ab)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if !brain.ExistMemory("a") {
+		t.Fatalf("expect neuron a processed")
+	}
+	if brain.ExistMemory("b") {
+		t.Errorf("expect the only cast group of a not cast")
+	}
+}
+
+// benchSingleGroupChain runs a chain of neurons, each with a single cast group selected by the selector
+func benchSingleGroupChain(b *testing.B, selector func() processor.Selector) {
+	bp := rModel.NewBlueprint()
+	noop := func(bc processor.BrainContext) error { return nil }
+	var prev core.Neuron
+	for i := 0; i < 20; i++ {
+		var opts []core.NeuronOption
+		if s := selector(); s != nil {
+			opts = append(opts, core.WithSelector(s))
+		}
+		n := bp.AddNeuron(noop, opts...)
+		if prev == nil {
+			_, _ = bp.AddEntryLinkTo(n)
+		} else {
+			_, _ = bp.AddLink(prev, n)
+		}
+		prev = n
+	}
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = brain.Entry()
+		brain.Wait()
+	}
+}
+
+func BenchmarkSingleCastGroup(b *testing.B) {
+	b.Run("skipped", func(b *testing.B) {
+		benchSingleGroupChain(b, func() processor.Selector { return nil })
+	})
+	b.Run("selector", func(b *testing.B) {
+		benchSingleGroupChain(b, func() processor.Selector {
+			return processor.NewFuncSelector(func(ctx processor.BrainContextReader) string {
+				return processor.DefaultCastGroupName
+			})
+		})
+	})
+}
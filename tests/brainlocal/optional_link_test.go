@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestOptionalLink(t *testing.T) {
+	var mu sync.Mutex
+	var received map[string]interface{}
+	fired := 0
+	bp := rModel.NewBlueprint()
+	hint := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetCastPayload(bc.GetMemory("mode").(string), "hint")
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return bcr.GetMemory("mode").(string)
+	}))
+	main := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetCastPayload(processor.DefaultCastGroupName, "main")
+	})
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		mu.Lock()
+		defer mu.Unlock()
+		fired++
+		received = bc.GetPayloads()
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(hint)
+	toMain, _ := bp.AddLink(hint, main)
+	hintToJoin, _ := bp.AddLink(hint, join)
+	hintToJoin.SetOptional(true)
+	mainToJoin, _ := bp.AddLink(main, join)
+	_ = hint.AddCastGroup("with", toMain, hintToJoin)
+	_ = hint.AddCastGroup("without", toMain)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	cases := []struct {
+		mode   string
+		expect map[string]interface{}
+	}{
+		{"with", map[string]interface{}{
+			mainToJoin.GetID(): "main",
+			hintToJoin.GetID(): "hint",
+		}},
+		{"without", map[string]interface{}{
+			mainToJoin.GetID(): "main",
+		}},
+	}
+	for _, c := range cases {
+		fired, received = 0, nil
+		_ = brain.Reset()
+		_ = brain.EntryWithMemory("mode", c.mode)
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			t.Fatalf("mode %s: run error: %v", c.mode, err)
+		}
+		if fired != 1 {
+			t.Errorf("mode %s: expect join fired once, got %d", c.mode, fired)
+		}
+		if !reflect.DeepEqual(received, c.expect) {
+			t.Errorf("mode %s: expect payloads %v, got %v", c.mode, c.expect, received)
+		}
+	}
+}
+
+func TestOptionalLinkOnly(t *testing.T) {
+	fired := false
+	bp := rModel.NewBlueprint()
+	src := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	dest := bp.AddNeuron(func(bc processor.BrainContext) error {
+		fired = true
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(src)
+	l, _ := bp.AddLink(src, dest)
+	l.SetOptional(true)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if fired {
+		t.Errorf("expect the neuron with only optional in-links not fired")
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+type spanRecorder struct {
+	spans []core.Span
+}
+
+func (r *spanRecorder) ExportSpans(ctx context.Context, spans []core.Span) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestTraceExportOTLP(t *testing.T) {
+	sleep := func(d time.Duration) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			time.Sleep(d)
+			return nil
+		}
+	}
+	// fast and slow join at merge, merge is the child of slow finished last, linked to fast
+	bp := rModel.NewBlueprint()
+	fast := bp.AddNeuron(sleep(5 * time.Millisecond))
+	slow := bp.AddNeuron(sleep(40 * time.Millisecond))
+	merge := bp.AddNeuron(sleep(time.Millisecond))
+	_, _ = bp.AddEntryLinkTo(fast)
+	_, _ = bp.AddEntryLinkTo(slow)
+	fromFast, _ := bp.AddLink(fast, merge)
+	fromSlow, _ := bp.AddLink(slow, merge)
+	_ = merge.AddTriggerGroup(fromFast, fromSlow)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+
+	recorder := &spanRecorder{}
+	if err := brain.GetRunTrace().ExportOTLP(context.Background(), recorder); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if len(recorder.spans) != 4 {
+		t.Fatalf("expect 4 spans, got %d", len(recorder.spans))
+	}
+	root := recorder.spans[0]
+	if root.Name != core.OTLPRunSpanName || root.ParentSpanID != [8]byte{} {
+		t.Errorf("expect root span first, got %s", root.Name)
+	}
+	byNeuron := make(map[string]core.Span)
+	for _, s := range recorder.spans[1:] {
+		if s.TraceID != root.TraceID {
+			t.Errorf("expect span %s in the trace of the run", s.Name)
+		}
+		if s.Start.Before(root.Start) || s.End.After(root.End) {
+			t.Errorf("expect span %s within the root span", s.Name)
+		}
+		byNeuron[s.Attributes[core.OTLPAttrNeuronID]] = s
+	}
+	for _, id := range []string{fast.GetID(), slow.GetID()} {
+		if byNeuron[id].ParentSpanID != root.SpanID {
+			t.Errorf("expect span of %s child of the root span", id)
+		}
+	}
+	m := byNeuron[merge.GetID()]
+	if m.ParentSpanID != byNeuron[slow.GetID()].SpanID {
+		t.Errorf("expect span of merge child of the span of slow")
+	}
+	if !reflect.DeepEqual(m.Links, [][8]byte{byNeuron[fast.GetID()].SpanID}) {
+		t.Errorf("expect span of merge linked to the span of fast, got %v", m.Links)
+	}
+}
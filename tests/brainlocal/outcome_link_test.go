@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestOnlyOnOutcome(t *testing.T) {
+	var mu sync.Mutex
+	fired := make(map[string]int)
+	record := func(name string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			fired[name]++
+			return nil
+		}
+	}
+	bp := rModel.NewBlueprint()
+	call := bp.AddNeuron(func(bc processor.BrainContext) error {
+		if bc.GetMemory("fail").(bool) {
+			return errors.New("service unavailable")
+		}
+		return nil
+	})
+	next := bp.AddNeuron(record("next"))
+	fallback := bp.AddNeuron(record("fallback"))
+	always := bp.AddNeuron(record("always"))
+	_, _ = bp.AddEntryLinkTo(call)
+	toNext, _ := bp.AddLink(call, next)
+	toNext.OnlyOnOutcome(processor.OutcomeSuccess)
+	toFallback, _ := bp.AddLink(call, fallback)
+	toFallback.OnlyOnOutcome(processor.OutcomeFailure)
+	// not gated, cast on success only, like any link of the selected group
+	_, _ = bp.AddLink(call, always)
+	_, _ = bp.AddEndLinkFrom(next)
+	_, _ = bp.AddEndLinkFrom(fallback)
+	_, _ = bp.AddEndLinkFrom(always)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	cases := []struct {
+		fail   bool
+		expect map[string]int
+	}{
+		{false, map[string]int{"next": 1, "always": 1}},
+		{true, map[string]int{"fallback": 1}},
+	}
+	for _, c := range cases {
+		fired = make(map[string]int)
+		_ = brain.Reset()
+		_ = brain.EntryWithMemory("fail", c.fail)
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			t.Errorf("fail %v: expect the outcome routed without run error, got %v", c.fail, err)
+		}
+		mu.Lock()
+		if len(fired) != len(c.expect) || fired["next"] != c.expect["next"] ||
+			fired["fallback"] != c.expect["fallback"] || fired["always"] != c.expect["always"] {
+			t.Errorf("fail %v: expect fired %v, got %v", c.fail, c.expect, fired)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestOnlyOnOutcomeWithSelector(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	fallback := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	retry := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	call := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return errors.New("rate limited")
+	}, core.WithSelector(processor.NewErrorAwareFuncSelector(
+		func(bcr processor.BrainContextReader) string { return processor.DefaultCastGroupName },
+		func(bcr processor.BrainContextReader, err error) string { return "retry" },
+	)))
+	_, _ = bp.AddEntryLinkTo(call)
+	toFallback, _ := bp.AddLink(call, fallback)
+	toFallback.OnlyOnOutcome(processor.OutcomeFailure)
+	toRetry, _ := bp.AddLink(call, retry)
+	_ = call.AddCastGroup("retry", toRetry)
+	_, _ = bp.AddEndLinkFrom(fallback)
+	_, _ = bp.AddEndLinkFrom(retry)
+
+	// the group selected on the error takes precedence over the gated links of other groups
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if brain.GetRunError() != nil || len(brain.GetRunTrace()) != 2 || brain.GetRunTrace()[1].NeuronID != retry.GetID() {
+		t.Errorf("expect the retry branch only, got error %v and trace %v", brain.GetRunError(), brain.GetExecutedNeurons())
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestQuotaSelector(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	quota := processor.NewQuotaSelector(map[string]int{"a": 3, "b": 7})
+	bp := rModel.NewBlueprint()
+	split := bp.AddNeuron(noop, core.WithSelector(quota))
+	a := bp.AddNeuron(func(bc processor.BrainContext) error { return bc.SetMemory("branch", "a") })
+	b := bp.AddNeuron(func(bc processor.BrainContext) error { return bc.SetMemory("branch", "b") })
+	_, _ = bp.AddEntryLinkTo(split)
+	toA, _ := bp.AddLink(split, a)
+	toB, _ := bp.AddLink(split, b)
+	_ = split.AddCastGroup("a", toA)
+	_ = split.AddCastGroup("b", toB)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	inputs := make([]map[string]any, 12)
+	for i := range inputs {
+		inputs[i] = map[string]any{"i": i}
+	}
+	results, err := brain.RunBatch(context.Background(), inputs, 4, core.WithOutputKeys("branch"))
+	if err != nil {
+		t.Fatalf("run batch error: %v", err)
+	}
+	counts := make(map[any]int)
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result %d: run error %v", r.Index, r.Err)
+		}
+		counts[r.Memory["branch"]]++
+	}
+	// the 2 runs beyond the quotas select the default cast group, which is empty
+	if counts["a"] != 3 || counts["b"] != 7 || counts[nil] != 2 {
+		t.Errorf("expect 3 runs to a, 7 to b and 2 to neither, got %v", counts)
+	}
+	if remaining := quota.Remaining(); remaining["a"] != 0 || remaining["b"] != 0 {
+		t.Errorf("expect the quotas used up, got %v", remaining)
+	}
+
+	// the schedule interleaves the groups
+	s := processor.NewQuotaSelector(map[string]int{"a": 1, "b": 2})
+	got := []string{s.Select(nil), s.Select(nil), s.Select(nil), s.Select(nil)}
+	expect := []string{"b", "a", "b", processor.DefaultCastGroupName}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Fatalf("expect schedule %v, got %v", expect, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func newRandBlueprint() core.Blueprint {
+	bp := rModel.NewBlueprint()
+	draw := bp.AddNeuron(func(bc processor.BrainContext) error {
+		nums := make([]int, 0, 5)
+		for i := 0; i < 5; i++ {
+			nums = append(nums, bc.Rand().Intn(1000))
+		}
+		return bc.SetMemory("nums", nums)
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		if bcr.Rand().Intn(2) == 0 {
+			return "heads"
+		}
+		return "tails"
+	}))
+	heads := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("branch", "heads")
+	})
+	tails := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("branch", "tails")
+	})
+	_, _ = bp.AddEntryLinkTo(draw)
+	toHeads, _ := bp.AddLink(draw, heads)
+	toTails, _ := bp.AddLink(draw, tails)
+	_ = draw.AddCastGroup("heads", toHeads)
+	_ = draw.AddCastGroup("tails", toTails)
+
+	return bp
+}
+
+func runRand(t *testing.T, brain *brainlocal.BrainLocal) ([]int, string) {
+	_ = brain.Reset()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	nums, _ := brain.GetMemory("nums").([]int)
+	branch, _ := brain.GetMemory("branch").(string)
+
+	return nums, branch
+}
+
+func TestRandSeed(t *testing.T) {
+	bp := newRandBlueprint()
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithRandSeed(42))
+	defer brain.Shutdown()
+
+	nums, branch := runRand(t, brain)
+	if len(nums) != 5 || branch == "" {
+		t.Fatalf("unexpected run result: %v %q", nums, branch)
+	}
+
+	// every run is seeded again, and so is every brain with the same seed
+	other := brainlocal.BuildBrain(bp, brainlocal.WithRandSeed(42))
+	defer other.Shutdown()
+	for _, b := range []*brainlocal.BrainLocal{brain, other} {
+		n, br := runRand(t, b)
+		if !reflect.DeepEqual(n, nums) || br != branch {
+			t.Errorf("expect the same run with the same seed, got %v %q, want %v %q", n, br, nums, branch)
+		}
+	}
+
+	different := brainlocal.BuildBrain(bp, brainlocal.WithRandSeed(7))
+	defer different.Shutdown()
+	if n, _ := runRand(t, different); reflect.DeepEqual(n, nums) {
+		t.Errorf("expect different numbers with a different seed, got %v", n)
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRefireGuard(t *testing.T) {
+	// the loop fires 3 times at most by its max revisits, the 4th fails the run unless the guard ends the loop
+	cases := []struct {
+		name      string
+		guard     func(bcr processor.BrainContextReader) bool
+		attempts  int
+		expectErr error
+	}{
+		{name: "no guard", attempts: 3, expectErr: core.ErrRevisitLimitExceeded},
+		{name: "guard before max revisits", guard: func(bcr processor.BrainContextReader) bool {
+			return bcr.GetMemory("attempts").(int) < 3
+		}, attempts: 3},
+		{name: "guard ends the loop early", guard: func(bcr processor.BrainContextReader) bool {
+			return bcr.GetMemory("attempts").(int) < 2
+		}, attempts: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bp := rModel.NewBlueprint()
+			loop := bp.AddNeuron(func(bc processor.BrainContext) error {
+				attempts, _ := bc.GetMemory("attempts").(int)
+				return bc.SetMemory("attempts", attempts+1)
+			}, core.WithMaxRevisits(2), core.WithRefireGuard(c.guard), core.WithSelector(processor.NewNoOpSelector("again")))
+			_, _ = bp.AddEntryLinkTo(loop)
+			again, _ := bp.AddLink(loop, loop)
+			_ = loop.AddCastGroup("again", again)
+
+			brain := brainlocal.BuildBrain(bp)
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+			if err := brain.GetRunError(); !errors.Is(err, c.expectErr) || (c.expectErr == nil && err != nil) {
+				t.Errorf("expect run error %v, got %v", c.expectErr, err)
+			}
+			if attempts := brain.GetMemory("attempts"); attempts != c.attempts {
+				t.Errorf("expect %d attempts, got %v", c.attempts, attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var calls int32
+	bp := rModel.NewBlueprint()
+	// fetch calls an external service, its result decides the branch
+	fetch := bp.AddNeuron(func(bc processor.BrainContext) error {
+		n := atomic.AddInt32(&calls, 1)
+		bc.DeleteMemory("query")
+		return bc.SetMemory("score", int(n)*7)
+	})
+	high := bp.AddNeuron(func(bc processor.BrainContext) error {
+		atomic.AddInt32(&calls, 1)
+		return bc.SetMemory("branch", "high")
+	})
+	low := bp.AddNeuron(func(bc processor.BrainContext) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("low score")
+	})
+	_, _ = bp.AddEntryLinkTo(fetch)
+	toHigh, _ := bp.AddLink(fetch, high)
+	toLow, _ := bp.AddLink(fetch, low)
+	_, _ = bp.AddEndLinkFrom(high)
+	_, _ = bp.AddEndLinkFrom(low)
+	_ = fetch.AddCastGroup("high", toHigh)
+	_ = fetch.AddCastGroup("low", toLow)
+	fetch.BindCastGroupSelectFunc(func(bcr processor.BrainContextReader) string {
+		if bcr.GetMemory("score").(int) > 10 {
+			return "high"
+		}
+		return "low"
+	})
+
+	recorder := rModel.NewRecorder()
+	recorded, err := recorder.Record(bp)
+	if err != nil {
+		t.Fatalf("record error: %v", err)
+	}
+	brain := brainlocal.BuildBrain(recorded)
+	defer brain.Shutdown()
+	_ = brain.EntryWithMemory("query", "q")
+	brain.Wait()
+	if err = brain.GetRunError(); err == nil {
+		t.Fatalf("expect the recorded run to fail on the low branch")
+	}
+	runErr := err.Error()
+
+	buf := &bytes.Buffer{}
+	if err = recorder.Recording().Encode(buf); err != nil {
+		t.Fatalf("encode recording error: %v", err)
+	}
+	rec, err := rModel.DecodeRecording(buf)
+	if err != nil {
+		t.Fatalf("decode recording error: %v", err)
+	}
+
+	// the live service would select the high branch now, the replay follows the recording
+	atomic.StoreInt32(&calls, 100)
+	runner, err := rModel.NewReplayRunner(bp, rec)
+	if err != nil {
+		t.Fatalf("new replay runner error: %v", err)
+	}
+	replay := brainlocal.BuildBrain(runner.Blueprint())
+	defer replay.Shutdown()
+	err = runner.Run(replay, "query", "q")
+	if err == nil || err.Error() != runErr {
+		t.Errorf("expect the recorded run error %q, got %v", runErr, err)
+	}
+	if calls != 100 {
+		t.Errorf("expect no call to the processors, got %d calls", calls-100)
+	}
+	if got := replay.GetMemory("score"); got != 7 {
+		t.Errorf("expect recorded score 7, got %v", got)
+	}
+	if replay.ExistMemory("query") || replay.ExistMemory("branch") {
+		t.Errorf("expect memory query deleted and no memory branch")
+	}
+
+	// the topology changed since recording
+	bp.AddNeuron(emptyProcess)
+	if _, err = rModel.NewReplayRunner(bp, rec); !errors.Is(err, core.ErrRecordingMismatch) {
+		t.Errorf("expect %v, got %v", core.ErrRecordingMismatch, err)
+	}
+}
+
+func emptyProcess(bc processor.BrainContext) error {
+	return nil
+}
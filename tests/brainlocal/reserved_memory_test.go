@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestReservedMemoryKey(t *testing.T) {
+	var processErr error
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		processErr = bc.SetMemory(core.ReservedMemoryKeyPrefix+"state", 1)
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	err := brain.EntryWithMemory("input", 1, core.ReservedMemoryKeyPrefix+"runID", "seeded")
+	if !errors.Is(err, core.ErrReservedMemoryKey) {
+		t.Fatalf("expect ErrReservedMemoryKey, got %v", err)
+	}
+	if brain.ExistMemory("input") {
+		t.Errorf("no memory should be set when any key is reserved")
+	}
+
+	_ = brain.Entry()
+	brain.Wait()
+	if !errors.Is(processErr, core.ErrReservedMemoryKey) {
+		t.Errorf("expect ErrReservedMemoryKey in neuron, got %v", processErr)
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// run with -race to check the runs reusing one brain are isolated
+func TestResetReuseBrain(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	greet := bp.AddNeuron(func(bc processor.BrainContext) error {
+		name := bc.GetMemory("input").(string)
+		if name == "admin" {
+			_ = bc.SetMemory("privileged", true)
+		}
+		return bc.SetMemory("output", "hello "+name)
+	})
+	count := bp.AddNeuron(func(bc processor.BrainContext) error {
+		cnt, _ := bc.GetMemory("count").(int)
+		return bc.SetMemory("count", cnt+1)
+	})
+	_, _ = bp.AddEntryLinkTo(greet)
+	_, _ = bp.AddLink(greet, count)
+	_, _ = bp.AddEndLinkFrom(count)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	for _, input := range []string{"admin", "guest", "admin", "user"} {
+		if err := brain.Reset(); err != nil {
+			t.Fatalf("reset error: %s", err)
+		}
+		if brain.ExistMemory("output") {
+			t.Fatalf("memory of the last run leaked after reset")
+		}
+		_ = brain.EntryWithMemory("input", input)
+		brain.Wait()
+
+		if output := brain.GetMemory("output"); output != "hello "+input {
+			t.Errorf("input %s: unexpected output %v", input, output)
+		}
+		if privileged := brain.ExistMemory("privileged"); privileged != (input == "admin") {
+			t.Errorf("input %s: unexpected privileged %v", input, privileged)
+		}
+		if cnt := brain.GetMemory("count"); cnt != 1 {
+			t.Errorf("input %s: expect count 1, got %v", input, cnt)
+		}
+		if ends := brain.GetReachedEnds(); len(ends) != 1 {
+			t.Errorf("input %s: expect 1 reached end, got %v", input, ends)
+		}
+	}
+}
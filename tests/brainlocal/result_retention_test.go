@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestResultRetention(t *testing.T) {
+	newLoop := func() core.Blueprint {
+		bp := rModel.NewBlueprint()
+		loop := bp.AddNeuron(func(bc processor.BrainContext) error {
+			n, _ := bc.GetMemory("n").(int)
+			return bc.SetMemory("n", n+1)
+		}, core.WithMaxRevisits(49), core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+			if bcr.GetMemory("n").(int) < 50 {
+				return "again"
+			}
+			return processor.DefaultCastGroupName
+		}))
+		_, _ = bp.AddEntryLinkTo(loop)
+		again, _ := bp.AddLink(loop, loop)
+		_ = loop.AddCastGroup("again", again)
+		_, _ = bp.AddEndLinkFrom(loop)
+		return bp
+	}
+
+	cases := []struct {
+		policy core.ResultRetention
+		trace  int
+	}{
+		{core.ResultRetentionFull, 50},
+		{core.ResultRetentionMinimal, 0},
+	}
+	for _, c := range cases {
+		brain := brainlocal.BuildBrain(newLoop(), brainlocal.WithResultRetention(c.policy))
+		_ = brain.Entry()
+		brain.Wait()
+		if n := brain.GetMemory("n"); n != 50 {
+			t.Errorf("policy %d: expect memory n 50, got %v", c.policy, n)
+		}
+		if len(brain.GetReachedEnds()) != 1 || brain.GetRunError() != nil {
+			t.Errorf("policy %d: expect the run reaching the end, got ends %v, err %v",
+				c.policy, brain.GetReachedEnds(), brain.GetRunError())
+		}
+		if trace := brain.GetRunTrace(); len(trace) != c.trace {
+			t.Errorf("policy %d: expect %d processes in trace, got %d", c.policy, c.trace, len(trace))
+		}
+		brain.Shutdown()
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestMaxRevisits(t *testing.T) {
+	cases := []struct {
+		maxRevisits int
+		expectErr   error
+	}{
+		{3, nil},
+		{2, core.ErrRevisitLimitExceeded},
+	}
+	for _, c := range cases {
+		bp := rModel.NewBlueprint()
+		// retries until the 4th attempt
+		retry := bp.AddNeuron(func(bc processor.BrainContext) error {
+			attempts, _ := bc.GetMemory("attempts").(int)
+			return bc.SetMemory("attempts", attempts+1)
+		}, core.WithMaxRevisits(c.maxRevisits), core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+			if bcr.GetMemory("attempts").(int) < 4 {
+				return "again"
+			}
+			return "done"
+		}))
+		_, _ = bp.AddEntryLinkTo(retry)
+		again, err := bp.AddLink(retry, retry)
+		if err != nil {
+			t.Fatalf("add self-link: %v", err)
+		}
+		done, _ := bp.AddEndLinkFrom(retry)
+		_ = retry.AddCastGroup("again", again)
+		_ = retry.AddCastGroup("done", done)
+
+		brain := brainlocal.BuildBrain(bp)
+		_ = brain.Entry()
+		brain.Wait()
+		if err := brain.GetRunError(); !errors.Is(err, c.expectErr) {
+			t.Errorf("max revisits %d: expect run error %v, got %v", c.maxRevisits, c.expectErr, err)
+		}
+		if c.expectErr == nil && brain.GetMemory("attempts") != 4 {
+			t.Errorf("max revisits %d: expect 4 attempts, got %v", c.maxRevisits, brain.GetMemory("attempts"))
+		}
+		brain.Shutdown()
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRoutingReport(t *testing.T) {
+	fn := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	router := bp.AddNeuron(fn, core.WithSelector(processor.NewQuotaSelector(map[string]int{"a": 1, "b": 1})))
+	free := bp.AddNeuron(fn, core.WithSelectFn(func(bcr processor.BrainContextReader) string { return "x" }))
+	leaf := bp.AddNeuron(fn)
+	_, _ = bp.AddEntryLinkTo(router)
+	la, _ := bp.AddLink(router, free)
+	lb, _ := bp.AddLink(router, leaf)
+	_ = router.AddCastGroup("a", la)
+	_ = router.AddCastGroup("b", lb)
+	lx, _ := bp.AddLink(free, leaf)
+	_ = free.AddCastGroup("x", lx)
+	end, _ := bp.AddEndLinkFrom(leaf)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	report := brain.RoutingReport()
+
+	ids := []string{router.GetID(), free.GetID(), leaf.GetID()}
+	sort.Strings(ids)
+	if len(report) != 3 {
+		t.Fatalf("expect 3 neurons without the End neuron, got %+v", report)
+	}
+	for i, r := range report {
+		if r.NeuronID != ids[i] {
+			t.Errorf("expect neuron %s at %d, got %s", ids[i], i, r.NeuronID)
+		}
+	}
+	byID := make(map[string]core.NeuronRouting, len(report))
+	for _, r := range report {
+		byID[r.NeuronID] = r
+	}
+
+	r := byID[router.GetID()]
+	if r.SelectorKind != "quota" || !reflect.DeepEqual(r.PossibleGroups, []string{processor.DefaultCastGroupName, "a", "b"}) ||
+		!reflect.DeepEqual(r.CastGroups["a"], []string{la.GetID()}) || !reflect.DeepEqual(r.CastGroups["b"], []string{lb.GetID()}) {
+		t.Errorf("unexpected routing of the quota neuron %+v", r)
+	}
+	if r := byID[free.GetID()]; r.SelectorKind != "func" || r.PossibleGroups != nil {
+		t.Errorf("expect a func selector selecting any group, got %+v", r)
+	}
+	if r := byID[leaf.GetID()]; r.SelectorKind != "default" || !reflect.DeepEqual(r.CastGroups[processor.DefaultCastGroupName], []string{end.GetID()}) {
+		t.Errorf("expect the default selector casting the End link, got %+v", r)
+	}
+}
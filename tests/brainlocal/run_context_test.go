@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// slowInvoker is a processor.GRPCInvoker blocks until the context of the call is done
+type slowInvoker struct {
+	err chan error
+}
+
+func (s *slowInvoker) Invoke(ctx context.Context, method string, req, resp interface{}) error {
+	select {
+	case <-ctx.Done():
+		s.err <- ctx.Err()
+		return ctx.Err()
+	case <-time.After(5 * time.Second):
+		s.err <- nil
+		return nil
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	invoker := &slowInvoker{err: make(chan error, 1)}
+	bp := rModel.NewBlueprint()
+	call := bp.AddNeuronWithProcessor(processor.NewGRPCProcessor(invoker, "method", "req", "resp"))
+	abort := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(20 * time.Millisecond)
+		return processor.AbortRun("deadline exceeded")
+	})
+	_, _ = bp.AddEntryLinkTo(call)
+	_, _ = bp.AddEntryLinkTo(abort)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.EntryWithMemory("method", "/echo.Echo/Say", "req", "hi")
+	select {
+	case err := <-invoker.err:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expect the call cancelled with the run, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expect the call cancelled once the run is aborted")
+	}
+	brain.Wait()
+	if err := brain.GetRunError(); !errors.Is(err, processor.ErrAbortRun) {
+		t.Errorf("expect run error %v, got %v", processor.ErrAbortRun, err)
+	}
+
+	// the call is cancelled by shutdown
+	bp2 := rModel.NewBlueprint()
+	call2 := bp2.AddNeuronWithProcessor(processor.NewGRPCProcessor(invoker, "method", "req", "resp"))
+	_, _ = bp2.AddEntryLinkTo(call2)
+	brain2 := brainlocal.BuildBrain(bp2)
+	_ = brain2.EntryWithMemory("method", "/echo.Echo/Say", "req", "hi")
+	time.Sleep(20 * time.Millisecond)
+	brain2.Shutdown()
+	select {
+	case err := <-invoker.err:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expect the call cancelled by shutdown, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expect the call cancelled once the brain is shut down")
+	}
+}
+
+func TestCancelReason(t *testing.T) {
+	// build a brain of a neuron waiting for the run to be cancelled, and another neuron ending the run by end
+	build := func(end func(bc processor.BrainContext) error, selectEnd bool, opts ...brainlocal.Option) (core.Brain, chan core.CancelReason) {
+		reasons := make(chan core.CancelReason, 1)
+		bp := rModel.NewBlueprint()
+		wait := bp.AddNeuron(func(bc processor.BrainContext) error {
+			ctx := bc.Context()
+			<-ctx.Done()
+			reasons <- rModel.CancelReason(ctx)
+			return ctx.Err()
+		})
+		var endOpts []core.NeuronOption
+		if selectEnd {
+			endOpts = append(endOpts, core.WithSelectFn(func(bcr processor.BrainContextReader) string { return processor.SelectEnd }))
+		}
+		ender := bp.AddNeuron(end, endOpts...)
+		_, _ = bp.AddEntryLinkTo(wait)
+		_, _ = bp.AddEntryLinkTo(ender)
+		return brainlocal.BuildBrain(bp, opts...), reasons
+	}
+	pause := func(bc processor.BrainContext) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+	abort := func(bc processor.BrainContext) error {
+		time.Sleep(20 * time.Millisecond)
+		return processor.AbortRun("give up")
+	}
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	cases := []struct {
+		name   string
+		run    func(brain core.Brain)
+		build  func() (core.Brain, chan core.CancelReason)
+		expect core.CancelReason
+	}{
+		{"aborted", func(brain core.Brain) { _ = brain.Entry() },
+			func() (core.Brain, chan core.CancelReason) { return build(abort, false) }, core.CancelReasonRunAborted},
+		{"early exit", func(brain core.Brain) { _ = brain.Entry() },
+			func() (core.Brain, chan core.CancelReason) {
+				return build(pause, true, brainlocal.WithCancelOnSelectEnd())
+			}, core.CancelReasonEarlyExit},
+		{"shutdown", func(brain core.Brain) {
+			_ = brain.Entry()
+			time.Sleep(20 * time.Millisecond)
+			brain.Shutdown()
+		}, func() (core.Brain, chan core.CancelReason) {
+			return build(func(bc processor.BrainContext) error { <-blocked; return nil }, false)
+		}, core.CancelReasonShutdown},
+		{"deadline exceeded", func(brain core.Brain) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, _ = brain.RunBatch(ctx, []map[string]any{{}}, 1)
+		}, func() (core.Brain, chan core.CancelReason) {
+			return build(func(bc processor.BrainContext) error { <-blocked; return nil }, false)
+		}, core.CancelReasonDeadlineExceeded},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			brain, reasons := c.build()
+			defer brain.Shutdown()
+			c.run(brain)
+			select {
+			case reason := <-reasons:
+				if reason != c.expect {
+					t.Errorf("expect cancel reason %s, got %s", c.expect, reason)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expect the run cancelled")
+			}
+		})
+	}
+}
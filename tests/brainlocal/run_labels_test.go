@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRunLabels(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("tenant", rModel.RunLabels(bc.Context())["tenant"])
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	metrics := &labelsMetrics{}
+	out := &syncBuffer{}
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithMetrics(metrics),
+		brainlocal.WithLogger(zerolog.New(out).Level(zerolog.DebugLevel)))
+	defer brain.Shutdown()
+
+	labels := map[string]string{"tenant": "acme"}
+	if err := brain.Run(core.WithRunLabels(labels)); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	brain.Wait()
+	if tenant := brain.GetMemory("tenant"); tenant != "acme" {
+		t.Errorf("expect the labels in the context of the run, got tenant %v", tenant)
+	}
+	if got := brain.GetRunLabels(); !reflect.DeepEqual(got, labels) {
+		t.Errorf("expect run labels %v, got %v", labels, got)
+	}
+	expect := []map[string]string{{
+		core.MetricLabelNeuron: n.GetID(),
+		core.MetricLabelGroup:  processor.DefaultCastGroupName,
+		"tenant":               "acme",
+	}}
+	if !reflect.DeepEqual(metrics.labels, expect) {
+		t.Errorf("expect metric labels %v, got %v", expect, metrics.labels)
+	}
+	if out.count(`"tenant":"acme"`) == 0 {
+		t.Errorf("expect the labels in the logs of the run")
+	}
+
+	// the next run is not labeled
+	_ = brain.Reset()
+	_ = brain.Entry()
+	brain.Wait()
+	if got := brain.GetRunLabels(); len(got) != 0 {
+		t.Errorf("expect no label of the next run, got %v", got)
+	}
+
+	err := brain.Run(core.WithRunLabels(map[string]string{core.MetricLabelNeuron: "other"}))
+	if !errors.Is(err, core.ErrInvalidMetricTags) {
+		t.Errorf("expect ErrInvalidMetricTags for a label of the metrics, got %v", err)
+	}
+}
+
+func TestBatchLabels(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(emptyProcess)
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	labels := map[string]string{"tenant": "acme"}
+	results, err := brain.RunBatch(context.Background(), []map[string]any{{}, {}}, 2, core.WithBatchLabels(labels))
+	if err != nil {
+		t.Fatalf("batch error: %s", err)
+	}
+	for _, r := range results {
+		if !reflect.DeepEqual(r.Labels, labels) {
+			t.Errorf("expect labels %v in the result, got %v", labels, r.Labels)
+		}
+		recorder := &spanRecorder{}
+		if err := r.Trace.ExportOTLPWithLabels(context.Background(), recorder, r.Labels); err != nil {
+			t.Fatalf("export error: %s", err)
+		}
+		for _, s := range recorder.spans {
+			if s.Attributes[core.OTLPAttrLabelPrefix+"tenant"] != "acme" {
+				t.Errorf("expect the labels in the attributes of span %s, got %v", s.Name, s.Attributes)
+			}
+		}
+	}
+}
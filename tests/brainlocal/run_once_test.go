@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// runOnceDiamond builds A -> B -> D and A -> C -> E -> D, each in-link of D is a trigger group of its own.
+// C is slow, so E is activated after D ran, and both groups of D are satisfied one after the other. The runs of D are counted.
+func runOnceDiamond(runs *int32, withOpts ...core.NeuronOption) core.Blueprint {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(emptyProcess)
+	b := bp.AddNeuron(emptyProcess)
+	c := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	d := bp.AddNeuron(func(bc processor.BrainContext) error {
+		atomic.AddInt32(runs, 1)
+		return nil
+	}, withOpts...)
+	e := bp.AddNeuron(emptyProcess)
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddLink(a, b)
+	_, _ = bp.AddLink(a, c)
+	_, _ = bp.AddLink(b, d)
+	_, _ = bp.AddLink(c, e)
+	_, _ = bp.AddLink(e, d)
+
+	return bp
+}
+
+func TestRunOnce(t *testing.T) {
+	cases := []struct {
+		name       string
+		withOpts   []core.NeuronOption
+		expectRuns int32
+	}{
+		{"retriggered", nil, 2},
+		{"run once", []core.NeuronOption{core.WithRunOnce()}, 1},
+	}
+	for _, c := range cases {
+		var runs int32
+		brain := brainlocal.BuildBrain(runOnceDiamond(&runs, c.withOpts...))
+		// the neuron runs once again in the next run
+		for run := 1; run <= 2; run++ {
+			_ = brain.Entry()
+			brain.Wait()
+			if err := brain.GetRunError(); err != nil {
+				t.Errorf("%s: unexpected run error: %v", c.name, err)
+			}
+			if got := atomic.LoadInt32(&runs); got != c.expectRuns*int32(run) {
+				t.Errorf("%s: expect %d runs of D after run %d, got %d", c.name, c.expectRuns*int32(run), run, got)
+			}
+		}
+		brain.Shutdown()
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRunSummary(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	validate := bp.AddNeuron(func(bc processor.BrainContext) error {
+		if bc.GetMemory("input") == "bad" {
+			return errors.New("invalid input")
+		}
+		return nil
+	})
+	store := bp.AddNeuron(emptyProcess)
+	_, _ = bp.AddEntryLinkTo(validate)
+	_, _ = bp.AddLink(validate, store)
+	_, _ = bp.AddEndLinkFrom(store)
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	inputs := []map[string]any{{"input": "good"}, {"input": "bad"}}
+	results, err := brain.RunBatch(context.Background(), inputs, 1, core.WithBatchLabels(map[string]string{"tenant": "acme"}))
+	if err != nil {
+		t.Fatalf("batch error: %s", err)
+	}
+
+	good := results[0].Summary()
+	expect := core.RunSummary{
+		NeuronsExecuted: 2,
+		EndsReached:     []string{core.EndNeuronIDOf("")},
+		Errors:          []string{},
+		DurationMs:      good.DurationMs,
+		Path:            []string{validate.GetID(), store.GetID()},
+		Labels:          map[string]string{"tenant": "acme"},
+	}
+	if !reflect.DeepEqual(good, expect) {
+		t.Errorf("expect summary %+v, got %+v", expect, good)
+	}
+
+	bad := results[1].Summary()
+	if bad.NeuronsExecuted != 1 || len(bad.EndsReached) != 0 || len(bad.Errors) != 2 ||
+		bad.Errors[1] != validate.GetID()+": invalid input" {
+		t.Errorf("expect the run error and the failed process in the summary, got %+v", bad)
+	}
+
+	// the summary round-trips JSON
+	data, err := json.Marshal(bad)
+	if err != nil {
+		t.Fatalf("marshal error: %s", err)
+	}
+	var decoded core.RunSummary
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal error: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, bad) {
+		t.Errorf("expect %+v decoded, got %+v", bad, decoded)
+	}
+}
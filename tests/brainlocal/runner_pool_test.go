@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// poolBlueprint doubles memory n into out
+func poolBlueprint() core.Blueprint {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("out", 2*bc.GetMemory("n").(int))
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+
+	return bp
+}
+
+func TestRunnerPool(t *testing.T) {
+	bp := poolBlueprint()
+	template := brainlocal.BuildBrain(bp)
+	defer template.Shutdown()
+	pool := brainlocal.NewRunnerPool(template)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for k := 0; k < 20; k++ {
+				b := pool.Get()
+				if b.ExistMemory("out") {
+					t.Errorf("expect a reset brain, got out %v", b.GetMemory("out"))
+				}
+				_ = b.EntryWithMemory("n", i*100+k)
+				b.Wait()
+				if got := b.GetMemory("out"); got != 2*(i*100+k) {
+					t.Errorf("expect out %d, got %v", 2*(i*100+k), got)
+				}
+				pool.Put(b)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkRunnerPool(b *testing.B) {
+	bp := poolBlueprint()
+	template := brainlocal.BuildBrain(bp)
+	defer template.Shutdown()
+	run := func(brain *brainlocal.BrainLocal, n int) {
+		_ = brain.EntryWithMemory("n", n)
+		brain.Wait()
+	}
+
+	b.Run("build", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			brain := brainlocal.BuildBrain(bp)
+			run(brain, i)
+			brain.Shutdown()
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		pool := brainlocal.NewRunnerPool(template)
+		defer pool.Close()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			brain := pool.Get()
+			run(brain, i)
+			pool.Put(brain)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// newSelectEndBlueprint router ends the run, while slow is still processing
+func newSelectEndBlueprint() core.Blueprint {
+	bp := rModel.NewBlueprint()
+	mark := func(key string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			return bc.SetMemory(key, true)
+		}
+	}
+	router := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return processor.SelectEnd
+	}))
+	next := bp.AddNeuron(mark("next"))
+	slow := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(200 * time.Millisecond)
+		return bc.SetMemory("slow", true)
+	})
+	afterSlow := bp.AddNeuron(mark("afterSlow"))
+	_, _ = bp.AddEntryLinkTo(router)
+	_, _ = bp.AddEntryLinkTo(slow)
+	_, _ = bp.AddLink(router, next)
+	_, _ = bp.AddLink(slow, afterSlow)
+	_, _ = bp.AddEndLinkFrom(next)
+	_, _ = bp.AddEndLinkFrom(afterSlow)
+
+	return bp
+}
+
+func TestSelectEnd(t *testing.T) {
+	brain := brainlocal.BuildBrain(newSelectEndBlueprint())
+	defer brain.Shutdown()
+	brain.SetCompletionProcessor(processor.NewFuncProcessor(func(bc processor.BrainContext) error {
+		return bc.SetMemory("completed", true)
+	}))
+
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Errorf("expect no run error, got %v", err)
+	}
+	if ends := brain.GetReachedEnds(); len(ends) != 1 || ends[0] != core.EndNeuronIDOf("") {
+		t.Errorf("expect the default End neuron reached, got %v", ends)
+	}
+	// the in-flight branch finishes without casting
+	if !brain.ExistMemory("slow") {
+		t.Errorf("expect the in-flight neuron to finish")
+	}
+	for _, key := range []string{"next", "afterSlow"} {
+		if brain.ExistMemory(key) {
+			t.Errorf("expect no memory %s after the run is ended", key)
+		}
+	}
+	if !brain.ExistMemory("completed") {
+		t.Errorf("expect the completion processor to run")
+	}
+}
+
+func TestSelectEndCancel(t *testing.T) {
+	brain := brainlocal.BuildBrain(newSelectEndBlueprint(), brainlocal.WithCancelOnSelectEnd())
+	defer brain.Shutdown()
+
+	start := time.Now()
+	_ = brain.Entry()
+	brain.Wait()
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("expect the ended run to return promptly, took %v", elapsed)
+	}
+	if err := brain.GetRunError(); err != nil {
+		t.Errorf("expect no run error, got %v", err)
+	}
+	if ends := brain.GetReachedEnds(); len(ends) != 1 || ends[0] != core.EndNeuronIDOf("") {
+		t.Errorf("expect the default End neuron reached, got %v", ends)
+	}
+	// the in-flight branch is cancelled
+	time.Sleep(250 * time.Millisecond)
+	for _, key := range []string{"next", "slow", "afterSlow"} {
+		if brain.ExistMemory(key) {
+			t.Errorf("expect no memory %s after the run is ended", key)
+		}
+	}
+}
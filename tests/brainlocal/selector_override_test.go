@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestRunSelectorOverride(t *testing.T) {
+	mark := func(key string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error { return bc.SetMemory(key, true) }
+	}
+	bp := rModel.NewBlueprint()
+	route := bp.AddNeuron(mark("route"), core.WithSelector(processor.NewNoOpSelector("a")))
+	a := bp.AddNeuron(mark("a"))
+	b := bp.AddNeuron(mark("b"))
+	_, _ = bp.AddEntryLinkTo(route)
+	toA, _ := bp.AddLink(route, a)
+	toB, _ := bp.AddLink(route, b)
+	_ = route.AddCastGroup("a", toA)
+	_ = route.AddCastGroup("b", toB)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	cases := []struct {
+		name     string
+		withOpts []core.RunOption
+		expect   string
+	}{
+		{"default", nil, "a"},
+		{"override", []core.RunOption{core.WithSelectorOverride(route.GetID(), processor.NewNoOpSelector("b"))}, "b"},
+		// the override applies to its run only
+		{"next run", nil, "a"},
+	}
+	for _, c := range cases {
+		if err := brain.Reset(); err != nil {
+			t.Fatalf("reset error: %s", err)
+		}
+		if err := brain.Run(c.withOpts...); err != nil {
+			t.Fatalf("%s: run error: %s", c.name, err)
+		}
+		brain.Wait()
+		for _, key := range []string{"a", "b"} {
+			if brain.ExistMemory(key) != (key == c.expect) {
+				t.Errorf("%s: expect only group %s cast, neuron %s processed: %v", c.name, c.expect, key, brain.ExistMemory(key))
+			}
+		}
+	}
+
+	if err := brain.Run(core.WithSelectorOverride("missing", processor.NewNoOpSelector("b"))); err == nil {
+		t.Errorf("expect error overriding the selector of a missing neuron")
+	}
+}
+
+func TestRunOptionsWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		<-release
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(n)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	err := brain.Run(core.WithSelectorOverride(n.GetID(), processor.NewNoOpSelector("b")))
+	close(release)
+	brain.Wait()
+	if !errors.Is(err, core.ErrBrainRunning) {
+		t.Errorf("expect ErrBrainRunning, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestSession(t *testing.T) {
+	var mu sync.Mutex
+	fired := make([]string, 0)
+	record := func(name string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			fired = append(fired, name)
+			return nil
+		}
+	}
+	firedSoFar := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(fired, ",")
+	}
+	bp := rModel.NewBlueprint()
+	greet := bp.AddNeuron(record("greet"))
+	ask := bp.AddNeuron(record("ask"), core.WithRequiredMemory("name"))
+	answer := bp.AddNeuron(func(bc processor.BrainContext) error {
+		_ = record("answer")(bc)
+		return bc.SetMemory("reply", bc.GetMemory("name").(string)+" likes "+bc.GetMemory("topic").(string))
+	}, core.WithRequiredMemory("topic"))
+	_, _ = bp.AddEntryLinkTo(greet)
+	_, _ = bp.AddLink(greet, ask)
+	_, _ = bp.AddLink(ask, answer)
+	_, _ = bp.AddEndLinkFrom(answer)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	session := brain.NewSession()
+	if session.Done() {
+		t.Fatalf("expect session not done before feed")
+	}
+	steps := []struct {
+		key   string
+		value string
+		fired string
+		done  bool
+	}{
+		{"lang", "en", "greet", false},
+		{"name", "alice", "greet,ask", false},
+		{"topic", "go", "greet,ask,answer", true},
+	}
+	for _, s := range steps {
+		if err := session.Feed(s.key, s.value); err != nil {
+			t.Fatalf("feed %s: %v", s.key, err)
+		}
+		if got := firedSoFar(); got != s.fired {
+			t.Errorf("feed %s: expect fired %s, got %s", s.key, s.fired, got)
+		}
+		if session.Done() != s.done {
+			t.Errorf("feed %s: expect done %v", s.key, s.done)
+		}
+		if !s.done && !strings.Contains(brain.WhyNotFired(answer.GetID()), "required memory") {
+			t.Errorf("feed %s: expect answer waits for the required memory, got %s", s.key, brain.WhyNotFired(answer.GetID()))
+		}
+	}
+	if reply := brain.GetMemory("reply"); reply != "alice likes go" {
+		t.Errorf("expect reply alice likes go, got %v", reply)
+	}
+	if err := session.Feed("name", "bob"); !errors.Is(err, core.ErrSessionDone) {
+		t.Errorf("expect ErrSessionDone, got %v", err)
+	}
+}
+
+func TestSessionMemoryFedAhead(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("sum", bc.GetMemory("a").(int)+bc.GetMemory("b").(int))
+	}, core.WithRequiredMemory("a", "b"))
+	_, _ = bp.AddEntryLinkTo(n)
+	_, _ = bp.AddEndLinkFrom(n)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.SetMemory("a", 1)
+	session := brain.NewSession()
+	if err := session.Feed("b", 2); err != nil {
+		t.Fatalf("feed: %v", err)
+	}
+	if !session.Done() {
+		t.Fatalf("expect session done")
+	}
+	if sum := brain.GetMemory("sum"); sum != 3 {
+		t.Errorf("expect sum 3, got %v", sum)
+	}
+}
+
+func TestSessionWaitForNeuron(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	review := bp.AddNeuron(noop, core.WithSelector(processor.NewNoOpSelector("approve")))
+	approve := bp.AddNeuron(noop, core.WithRequiredMemory("approval"))
+	reject := bp.AddNeuron(noop)
+	_, _ = bp.AddEntryLinkTo(review)
+	toApprove, _ := bp.AddLink(review, approve)
+	toReject, _ := bp.AddLink(review, reject)
+	_ = review.AddCastGroup("approve", toApprove)
+	_ = review.AddCastGroup("reject", toReject)
+	_, _ = bp.AddEndLinkFrom(approve)
+	_, _ = bp.AddEndLinkFrom(reject)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	session := brain.NewSession()
+	if err := session.WaitForNeuron(context.Background(), "unknown"); err == nil {
+		t.Errorf("expect error of the unknown neuron")
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := session.WaitForNeuron(cancelled, approve.GetID()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect context canceled before feed, got %v", err)
+	}
+
+	approved := make(chan error, 1)
+	go func() {
+		approved <- session.WaitForNeuron(context.Background(), approve.GetID())
+	}()
+	if err := session.Feed("order", "o-1"); err != nil {
+		t.Fatalf("feed order: %v", err)
+	}
+	if err := session.WaitForNeuron(context.Background(), review.GetID()); err != nil {
+		t.Errorf("expect review executed, got %v", err)
+	}
+	select {
+	case err := <-approved:
+		t.Fatalf("expect waiting for the approval, got %v", err)
+	default:
+	}
+	if err := session.Feed("approval", true); err != nil {
+		t.Fatalf("feed approval: %v", err)
+	}
+	if err := <-approved; err != nil {
+		t.Errorf("expect approve executed, got %v", err)
+	}
+	if err := session.WaitForNeuron(context.Background(), reject.GetID()); !errors.Is(err, core.ErrNeuronNotExecuted) {
+		t.Errorf("expect ErrNeuronNotExecuted, got %v", err)
+	}
+}
+
+func TestSessionWaitForNeuronNotEntered(t *testing.T) {
+	missing := errors.New("CONFIG_PATH not set")
+	bp := rModel.NewBlueprint()
+	config := bp.AddNeuronWithProcessor(processor.NewConfigProcessor(func() (map[string]interface{}, error) {
+		return nil, missing
+	}))
+	_, _ = bp.AddEntryLinkTo(config)
+	_, _ = bp.AddEndLinkFrom(config)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	session := brain.NewSession()
+	waited := make(chan error, 1)
+	go func() {
+		waited <- session.WaitForNeuron(context.Background(), config.GetID())
+	}()
+	if err := session.Feed("order", "o-1"); !errors.Is(err, missing) {
+		t.Fatalf("expect feed error %v, got %v", missing, err)
+	}
+	select {
+	case err := <-waited:
+		if !errors.Is(err, core.ErrSessionNotEntered) {
+			t.Errorf("expect session not entered, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expect the wait to return once the entry fails")
+	}
+	if err := session.WaitForNeuron(context.Background(), config.GetID()); !errors.Is(err, core.ErrSessionNotEntered) {
+		t.Errorf("expect session not entered, got %v", err)
+	}
+	if !session.Done() {
+		t.Errorf("expect the session done")
+	}
+	if err := session.Feed("order", "o-2"); !errors.Is(err, core.ErrSessionDone) {
+		t.Errorf("expect session done, got %v", err)
+	}
+}
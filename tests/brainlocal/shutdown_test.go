@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+var errProcess = errors.New("process failed")
+
+func TestFailedNeuronEndsRun(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	fail := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return errProcess
+	})
+	next := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("next", true)
+	})
+	_, _ = bp.AddEntryLinkTo(fail)
+	_, _ = bp.AddLink(fail, next)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	// the run sleeps instead of waiting forever for the out-links of the failed neuron
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); !errors.Is(err, errProcess) {
+		t.Errorf("expect run error %v, got %v", errProcess, err)
+	}
+	if brain.ExistMemory("next") {
+		t.Errorf("out-links of the failed neuron should not be cast")
+	}
+}
+
+func TestShutdownWhileRunning(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	slow := bp.AddNeuron(func(bc processor.BrainContext) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	next := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(slow)
+	_, _ = bp.AddLink(slow, next)
+
+	brain := brainlocal.BuildBrain(bp)
+	_ = brain.Entry()
+	brain.Shutdown()
+	// the in-flight neuron publishes its events after shutdown, which must not panic
+	time.Sleep(100 * time.Millisecond)
+	brain.Shutdown()
+
+	// a brain never triggered can be shut down too
+	brainlocal.BuildBrain(bp).Shutdown()
+}
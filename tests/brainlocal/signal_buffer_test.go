@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestSignalBufferSize(t *testing.T) {
+	const fanOut = 50
+	bp := rModel.NewBlueprint()
+	root := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(root)
+	for i := 0; i < fanOut; i++ {
+		key := fmt.Sprintf("leaf%d", i)
+		leaf := bp.AddNeuron(func(bc processor.BrainContext) error {
+			return bc.SetMemory(key, true)
+		})
+		_, _ = bp.AddLink(root, leaf)
+	}
+
+	for _, size := range []int{1, 100} {
+		brain := brainlocal.BuildBrain(bp, brainlocal.WithSignalBufferSize(size))
+		_ = brain.Entry()
+		brain.Wait()
+		// signals are never dropped when the buffer is full
+		for i := 0; i < fanOut; i++ {
+			if key := fmt.Sprintf("leaf%d", i); !brain.ExistMemory(key) {
+				t.Errorf("buffer size %d: expect memory %s", size, key)
+			}
+		}
+		if depth := brain.SignalQueueDepth(); depth != 0 {
+			t.Errorf("buffer size %d: expect empty signal queue after the run, got %d", size, depth)
+		}
+		brain.Shutdown()
+	}
+}
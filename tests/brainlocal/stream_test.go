@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// closeCounter is a reader counting its closes
+type closeCounter struct {
+	io.Reader
+	closes int32
+}
+
+func (c *closeCounter) Close() error {
+	atomic.AddInt32(&c.closes, 1)
+	return nil
+}
+
+func TestMemoryStream(t *testing.T) {
+	blob := &closeCounter{Reader: strings.NewReader("large blob")}
+	var read string
+	var rereadErr error
+	bp := rModel.NewBlueprint()
+	produce := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetStream("blob", blob)
+	})
+	consume := bp.AddNeuron(func(bc processor.BrainContext) error {
+		r, err := bc.GetStream("blob")
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		read = string(data)
+		_, rereadErr = bc.GetStream("blob")
+		return err
+	})
+	_, _ = bp.AddEntryLinkTo(produce)
+	_, _ = bp.AddLink(produce, consume)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if read != "large blob" {
+		t.Errorf("expect the stream read downstream, got %q", read)
+	}
+	if !errors.Is(rereadErr, processor.ErrStreamConsumed) {
+		t.Errorf("expect ErrStreamConsumed reading the stream twice, got %v", rereadErr)
+	}
+	if closes := atomic.LoadInt32(&blob.closes); closes != 1 {
+		t.Errorf("expect the reader closed once, got %d", closes)
+	}
+}
+
+func TestMemoryStreamClosedOnRunOver(t *testing.T) {
+	for _, abort := range []bool{false, true} {
+		blob := &closeCounter{Reader: strings.NewReader("large blob")}
+		bp := rModel.NewBlueprint()
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			if err := bc.SetStream("blob", blob); err != nil {
+				return err
+			}
+			if abort {
+				return processor.AbortRun("stop")
+			}
+			return nil
+		})
+		_, _ = bp.AddEntryLinkTo(n)
+
+		brain := brainlocal.BuildBrain(bp)
+		_ = brain.Entry()
+		brain.Wait()
+		if closes := atomic.LoadInt32(&blob.closes); closes != 1 {
+			t.Errorf("abort %v: expect the unread stream closed once the run is over, got %d closes", abort, closes)
+		}
+		brain.Shutdown()
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestSubsystem(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	fetch := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("fetched", true)
+	})
+	enrich := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("enriched", true)
+	})
+	fail := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return errors.New("enrich failed")
+	})
+	_, _ = bp.AddEntryLinkTo(fetch)
+	_, _ = bp.AddLink(fetch, enrich)
+	_, _ = bp.AddLink(fetch, fail)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	if err := brain.DefineSubsystem("enrichment", enrich.GetID(), "missing"); err == nil {
+		t.Errorf("expect error defining a subsystem of a missing neuron")
+	}
+	if err := brain.DefineSubsystem("enrichment", enrich.GetID(), fail.GetID()); err != nil {
+		t.Fatalf("define subsystem error: %v", err)
+	}
+	if err := brain.DefineSubsystem("ingest", fetch.GetID(), fail.GetID()); err == nil {
+		t.Errorf("expect error adding a neuron to a second subsystem")
+	}
+	if err := brain.DefineSubsystem("ingest", fetch.GetID()); err != nil {
+		t.Fatalf("define subsystem error: %v", err)
+	}
+	expect := []string{enrich.GetID(), fail.GetID()}
+	sort.Strings(expect)
+	if got := brain.ListSubsystems(); !reflect.DeepEqual(got["enrichment"], expect) || len(got["ingest"]) != 1 {
+		t.Errorf("expect subsystems enrichment %v and ingest of 1 neuron, got %v", expect, got)
+	}
+
+	_ = brain.Entry()
+	brain.Wait()
+	stats, err := brain.GetSubsystemStats("enrichment")
+	if err != nil {
+		t.Fatalf("get subsystem stats error: %v", err)
+	}
+	if stats.Executed != 2 || stats.Failed != 1 {
+		t.Errorf("expect 2 executed and 1 failed in subsystem enrichment, got %+v", stats)
+	}
+
+	// a disabled subsystem is not activated
+	if err = brain.SetSubsystemEnabled("enrichment", false); err != nil {
+		t.Fatalf("disable subsystem error: %v", err)
+	}
+	_ = brain.Reset()
+	_ = brain.Entry()
+	brain.Wait()
+	if brain.ExistMemory("enriched") || !brain.ExistMemory("fetched") {
+		t.Errorf("expect only the neuron outside the disabled subsystem processed")
+	}
+	if err = brain.GetRunError(); err != nil {
+		t.Errorf("expect no run error, got %v", err)
+	}
+	if stats, _ = brain.GetSubsystemStats("enrichment"); stats.Executed != 0 {
+		t.Errorf("expect no execution in the disabled subsystem, got %+v", stats)
+	}
+
+	_ = brain.SetSubsystemEnabled("enrichment", true)
+	_ = brain.Reset()
+	_ = brain.Entry()
+	brain.Wait()
+	if !brain.ExistMemory("enriched") {
+		t.Errorf("expect the enabled subsystem processed")
+	}
+
+	if err = brain.SetSubsystemEnabled("missing", false); err == nil {
+		t.Errorf("expect error enabling a missing subsystem")
+	}
+	if _, err = brain.GetSubsystemStats("missing"); err == nil {
+		t.Errorf("expect error getting the stats of a missing subsystem")
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTakenSubgraph(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	route := bp.AddNeuron(emptyProcess, core.WithSelector(processor.NewNoOpSelector("b")))
+	a := bp.AddNeuron(emptyProcess)
+	b := bp.AddNeuron(emptyProcess)
+	_, _ = bp.AddEntryLinkTo(route)
+	toA, _ := bp.AddLink(route, a)
+	toB, _ := bp.AddLink(route, b)
+	_ = route.AddCastGroup("a", toA)
+	_ = route.AddCastGroup("b", toB)
+	_, _ = bp.AddEndLinkFrom(a)
+	_, _ = bp.AddEndLinkFrom(b)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(1))
+	defer brain.Shutdown()
+	if _, err := brain.TakenSubgraph(); err == nil {
+		t.Errorf("expect error before any run")
+	}
+	_ = brain.Entry()
+	brain.Wait()
+
+	sub, err := brain.TakenSubgraph()
+	if err != nil {
+		t.Fatalf("taken subgraph error: %s", err)
+	}
+	defer sub.Shutdown()
+	neurons := make([]string, 0)
+	for _, r := range sub.RoutingReport() {
+		neurons = append(neurons, r.NeuronID)
+	}
+	expect := []string{route.GetID(), b.GetID()}
+	sort.Strings(expect)
+	if !reflect.DeepEqual(neurons, expect) {
+		t.Fatalf("expect the neurons taken %v, got %v", expect, neurons)
+	}
+
+	// the subgraph runs on its own, the same path
+	_ = sub.Entry()
+	sub.Wait()
+	if err := sub.GetRunError(); err != nil {
+		t.Fatalf("subgraph run error: %s", err)
+	}
+	if executed := sub.GetExecutedNeurons(); !reflect.DeepEqual(executed, []string{route.GetID(), b.GetID()}) {
+		t.Errorf("expect the path taken executed again, got %v", executed)
+	}
+	if ends := sub.GetReachedEnds(); len(ends) != 1 {
+		t.Errorf("expect the End neuron reached, got %v", ends)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestEditTopology(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	greet := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("greeted", true)
+	})
+	_, _ = bp.AddEntryLinkTo(greet)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	// define the new neuron and link by the blueprint, then add them to the built brain
+	audit := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("audited", true)
+	})
+	toAudit, _ := bp.AddLink(greet, audit)
+	if err := brain.AddNeuron(audit); err != nil {
+		t.Fatalf("add neuron error: %s", err)
+	}
+	if err := brain.AddNeuron(audit); err == nil {
+		t.Errorf("add neuron twice should error")
+	}
+	if err := brain.AddLink(toAudit); err != nil {
+		t.Fatalf("add link error: %s", err)
+	}
+
+	_ = brain.Entry()
+	brain.Wait()
+	if !brain.ExistMemory("greeted") || !brain.ExistMemory("audited") {
+		t.Fatalf("added neuron should be activated")
+	}
+
+	if err := brain.RemoveNeuron(audit.GetID()); !errors.Is(err, core.ErrOrphanLinks) {
+		t.Errorf("expect ErrOrphanLinks, got %v", err)
+	}
+	if err := brain.RemoveLink(toAudit.GetID()); err != nil {
+		t.Fatalf("remove link error: %s", err)
+	}
+	if err := brain.RemoveNeuron(audit.GetID()); err != nil {
+		t.Fatalf("remove neuron error: %s", err)
+	}
+
+	_ = brain.Reset()
+	_ = brain.Entry()
+	brain.Wait()
+	if !brain.ExistMemory("greeted") || brain.ExistMemory("audited") {
+		t.Errorf("removed neuron should not be activated")
+	}
+
+	end, _ := bp.AddEndLinkFrom(greet)
+	if err := brain.AddLink(end); err != nil {
+		t.Fatalf("add end link error: %s", err)
+	}
+	// the End neuron is only reachable by the end link
+	if err := brain.RemoveLink(end.GetID()); !errors.Is(err, core.ErrNoReachableEnd) {
+		t.Errorf("expect ErrNoReachableEnd, got %v", err)
+	}
+	if err := brain.RemoveNeuron(core.EndNeuronID); !errors.Is(err, core.ErrEndNeuronRemoval) {
+		t.Errorf("expect ErrEndNeuronRemoval, got %v", err)
+	}
+}
+
+func TestRemoveJoinLinks(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	a := bp.AddNeuron(fn1)
+	b := bp.AddNeuron(fn1)
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("joined", true)
+	})
+	entryA, _ := bp.AddEntryLinkTo(a)
+	entryB, _ := bp.AddEntryLinkTo(b)
+	aJoin, _ := bp.AddLink(a, join)
+	bJoin, _ := bp.AddLink(b, join)
+	_ = join.AddTriggerGroup(aJoin, bJoin)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	// removing a part of the join would fire join on the other link alone
+	if err := brain.RemoveLink(aJoin.GetID()); !errors.Is(err, core.ErrPartialTriggerGroupRemoval) {
+		t.Fatalf("expect ErrPartialTriggerGroupRemoval, got %v", err)
+	}
+	if err := brain.RemoveLinks(aJoin.GetID(), bJoin.GetID()); err != nil {
+		t.Fatalf("remove join links error: %s", err)
+	}
+
+	_ = brain.TrigLinks(entryA, entryB)
+	brain.Wait()
+	if brain.ExistMemory("joined") {
+		t.Errorf("join should not be activated after its links are removed")
+	}
+}
+
+// run with -race to check runs never observe a half-edited topology
+func TestEditTopologyDuringRuns(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	n1 := bp.AddNeuron(fn1)
+	n2 := bp.AddNeuron(fn2)
+	_, _ = bp.AddEntryLinkTo(n1)
+	_, _ = bp.AddLink(n1, n2)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	side := bp.AddNeuron(func(bc processor.BrainContext) error {
+		_ = bc.GetMemory("name")
+		return nil
+	})
+	toSide, _ := bp.AddLink(n1, side)
+	if err := brain.AddNeuron(side); err != nil {
+		t.Fatalf("add neuron error: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			_ = brain.Entry()
+			brain.Wait()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			var err error
+			if i%2 == 0 {
+				err = brain.AddLink(toSide)
+			} else {
+				err = brain.RemoveLink(toSide.GetID())
+			}
+			if err != nil && !errors.Is(err, core.ErrBrainRunning) {
+				t.Errorf("edit error: %s", err)
+				return
+			}
+			if errors.Is(err, core.ErrBrainRunning) {
+				// retry the same edit
+				i--
+			}
+		}
+	}()
+	wg.Wait()
+}
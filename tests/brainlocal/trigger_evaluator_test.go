@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTriggerEvaluator(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	noop := func(bc processor.BrainContext) error { return nil }
+	a := bp.AddNeuron(noop)
+	b := bp.AddNeuron(noop)
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("triggeredBy", bc.GetTriggeredBy())
+	})
+	entryA, _ := bp.AddEntryLinkTo(a)
+	entryB, _ := bp.AddEntryLinkTo(b)
+	aJoin, _ := bp.AddLink(a, join)
+	bJoin, _ := bp.AddLink(b, join)
+	_, _ = bp.AddEndLinkFrom(join)
+
+	// join fires when a and b arrive within a second of each other
+	join.SetTriggerEvaluator(processor.NewFuncTriggerEvaluator(func(arrived map[string]processor.SignalInfo) bool {
+		sa, okA := arrived[aJoin.GetID()]
+		sb, okB := arrived[bJoin.GetID()]
+		if !okA || !okB {
+			return false
+		}
+		d := sa.ArrivedAt.Sub(sb.ArrivedAt)
+		return d < time.Second && d > -time.Second
+	}))
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithDeadlockDetection())
+	defer brain.Shutdown()
+
+	_ = brain.TrigLinks(entryA, entryB)
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("expect no run error, got %v", err)
+	}
+	if by := brain.GetMemory("triggeredBy"); by != processor.TriggerEvaluatorGroupKey {
+		t.Errorf("expect triggered by %s, got %v", processor.TriggerEvaluatorGroupKey, by)
+	}
+	if ends := brain.GetReachedEnds(); len(ends) != 1 {
+		t.Errorf("expect the End neuron reached, got %v", ends)
+	}
+
+	// the evaluator is not satisfied by a alone, the join is never activated
+	_ = brain.Reset()
+	_ = brain.TrigLinks(entryA)
+	brain.Wait()
+	err := brain.GetRunError()
+	if !errors.Is(err, core.ErrDeadlock) || !strings.Contains(err.Error(), join.GetID()) {
+		t.Errorf("expect deadlock of neuron %s, got %v", join.GetID(), err)
+	}
+	if brain.ExistMemory("triggeredBy") {
+		t.Errorf("expect join not activated")
+	}
+}
+
+func TestThresholdTriggerEvaluator(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	activations := 0
+	valid := make([]interface{}, 0)
+	var arrived, absent int
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		activations++
+		arrived, absent = len(bc.GetPayloads()), len(bc.GetAbsentInputs())
+		for _, payload := range bc.GetPayloads() {
+			if payload == "valid" {
+				valid = append(valid, payload)
+			}
+		}
+		return nil
+	}, core.WithSelectFn(func(bcr processor.BrainContextReader) string { return processor.SelectEnd }))
+	for _, result := range []string{"valid", "invalid", "valid", "invalid"} {
+		result := result
+		n := bp.AddNeuron(func(bc processor.BrainContext) error {
+			return bc.SetCastPayload(processor.DefaultCastGroupName, result)
+		})
+		_, _ = bp.AddEntryLinkTo(n)
+		_, _ = bp.AddLink(n, join)
+	}
+	// a quorum of 2 valid results
+	join.SetTriggerEvaluator(processor.NewThresholdTriggerEvaluator(2, func(linkID string, value interface{}) bool {
+		return value == "valid"
+	}))
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("expect no run error, got %v", err)
+	}
+	if activations != 1 || len(valid) != 2 {
+		t.Errorf("expect join activated once with 2 valid results, got %d activations with %v", activations, valid)
+	}
+	// every in-link either arrived or is absent
+	if arrived+absent != 4 {
+		t.Errorf("expect 4 in-links arrived or absent, got %d arrived and %d absent", arrived, absent)
+	}
+}
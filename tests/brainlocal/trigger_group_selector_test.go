@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTriggerGroupSelector(t *testing.T) {
+	bp := rModel.NewBlueprint()
+	pass := func(bc processor.BrainContext) error {
+		return nil
+	}
+	a := bp.AddNeuron(pass)
+	b := bp.AddNeuron(pass)
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("joinTriggeredBy", bc.GetTriggeredBy())
+	}, core.WithSelector(processor.NewTriggerGroupSelector(map[string]string{
+		"fromA": "toX",
+		"fromB": "toY",
+	})))
+	x := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("routed", "x")
+	})
+	y := bp.AddNeuron(func(bc processor.BrainContext) error {
+		return bc.SetMemory("routed", "y")
+	})
+
+	entryA, _ := bp.AddEntryLinkTo(a)
+	entryB, _ := bp.AddEntryLinkTo(b)
+	aJoin, _ := bp.AddLink(a, join)
+	bJoin, _ := bp.AddLink(b, join)
+	joinX, _ := bp.AddLink(join, x)
+	joinY, _ := bp.AddLink(join, y)
+	_ = join.AddNamedTriggerGroup("fromA", aJoin)
+	_ = join.AddNamedTriggerGroup("fromB", bJoin)
+	_ = join.AddCastGroup("toX", joinX)
+	_ = join.AddCastGroup("toY", joinY)
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("validate error: %s", err)
+	}
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+
+	cases := []struct {
+		entry  core.Link
+		group  string
+		routed string
+	}{
+		{entryA, "fromA", "x"},
+		{entryB, "fromB", "y"},
+	}
+	for _, c := range cases {
+		_ = brain.Reset()
+		_ = brain.TrigLinks(c.entry)
+		brain.Wait()
+		if got := brain.GetMemory("joinTriggeredBy"); got != c.group {
+			t.Errorf("expect triggered by %s, got %v", c.group, got)
+		}
+		if got := brain.GetMemory("routed"); got != c.routed {
+			t.Errorf("expect routed to %s, got %v", c.routed, got)
+		}
+	}
+}
+
+func TestTriggerGroupPriority(t *testing.T) {
+	cases := []struct {
+		name       string
+		priorities map[string]int
+		expect     string
+	}{
+		{"by key", nil, "fromA"},
+		{"by priority", map[string]int{"fromB": 1}, "fromB"},
+		{"negative priority", map[string]int{"fromA": -1}, "fromB"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var mu sync.Mutex
+			triggeredBy := make([]string, 0)
+			opts := make([]core.NeuronOption, 0)
+			for key, priority := range c.priorities {
+				opts = append(opts, core.WithTriggerGroupPriority(key, priority))
+			}
+			bp := rModel.NewBlueprint()
+			split := bp.AddNeuron(func(bc processor.BrainContext) error {
+				return nil
+			})
+			join := bp.AddNeuron(func(bc processor.BrainContext) error {
+				mu.Lock()
+				defer mu.Unlock()
+				triggeredBy = append(triggeredBy, bc.GetTriggeredBy())
+				return nil
+			}, opts...)
+			_, _ = bp.AddEntryLinkTo(split)
+			// both groups are satisfied by the same cast
+			l1, _ := bp.AddLink(split, join)
+			l2, _ := bp.AddLink(split, join)
+			_ = join.AddNamedTriggerGroup("fromA", l1)
+			_ = join.AddNamedTriggerGroup("fromB", l2)
+
+			brain := brainlocal.BuildBrain(bp)
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+			mu.Lock()
+			defer mu.Unlock()
+			if len(triggeredBy) == 0 || triggeredBy[0] != c.expect {
+				t.Errorf("expect first triggered by %s, got %v", c.expect, triggeredBy)
+			}
+		})
+	}
+}
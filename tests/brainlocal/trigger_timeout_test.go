@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTriggerTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var triggeredBy []string
+	var received map[string]interface{}
+	var absent []string
+	bp := rModel.NewBlueprint()
+	replica := func(name string) func(bc processor.BrainContext) error {
+		return func(bc processor.BrainContext) error {
+			return bc.SetCastPayload(processor.DefaultCastGroupName, name)
+		}
+	}
+	// replica b does not answer when it is down
+	a := bp.AddNeuron(replica("a"))
+	b := bp.AddNeuron(replica("b"), core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		if bcr.GetMemory("down") == true {
+			return "silent"
+		}
+		return processor.DefaultCastGroupName
+	}))
+	join := bp.AddNeuron(func(bc processor.BrainContext) error {
+		mu.Lock()
+		defer mu.Unlock()
+		triggeredBy = append(triggeredBy, bc.GetTriggeredBy())
+		received = bc.GetPayloads()
+		absent = bc.GetAbsentInputs()
+		return nil
+	}, core.WithTriggerTimeout(50*time.Millisecond, "partial"))
+	_, _ = bp.AddEntryLinkTo(a)
+	_, _ = bp.AddEntryLinkTo(b)
+	fromA, _ := bp.AddLink(a, join)
+	fromB, _ := bp.AddLink(b, join)
+	_ = join.AddNamedTriggerGroup("all", fromA, fromB)
+	_ = b.AddCastGroup(processor.DefaultCastGroupName, fromB)
+	b.AllowEmptyCastGroup("silent")
+	_ = b.AddCastGroup("silent")
+	_, _ = bp.AddEndLinkFrom(join)
+
+	brain := brainlocal.BuildBrain(bp)
+	defer brain.Shutdown()
+	cases := []struct {
+		down        bool
+		triggeredBy string
+		payloads    map[string]interface{}
+		absent      []string
+	}{
+		{false, "all", map[string]interface{}{fromA.GetID(): "a", fromB.GetID(): "b"}, []string{}},
+		{true, "partial", map[string]interface{}{fromA.GetID(): "a"}, []string{fromB.GetID()}},
+	}
+	for _, c := range cases {
+		triggeredBy, received, absent = nil, nil, nil
+		_ = brain.Reset()
+		_ = brain.EntryWithMemory("down", c.down)
+		brain.Wait()
+		if err := brain.GetRunError(); err != nil {
+			t.Fatalf("down %v: run error: %v", c.down, err)
+		}
+		if !reflect.DeepEqual(triggeredBy, []string{c.triggeredBy}) {
+			t.Errorf("down %v: expect join triggered by %s once, got %v", c.down, c.triggeredBy, triggeredBy)
+		}
+		if !reflect.DeepEqual(received, c.payloads) {
+			t.Errorf("down %v: expect payloads %v, got %v", c.down, c.payloads, received)
+		}
+		if !reflect.DeepEqual(absent, c.absent) {
+			t.Errorf("down %v: expect absent inputs %v, got %v", c.down, c.absent, absent)
+		}
+	}
+}
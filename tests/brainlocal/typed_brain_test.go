@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+)
+
+type doubleIn struct {
+	N     int    `rmodel:"n"`
+	Debug string `rmodel:"-"`
+}
+
+type doubleOut struct {
+	Out int `rmodel:"out"`
+}
+
+func TestTypedBrain(t *testing.T) {
+	brain := newDoubleBrain()
+	defer brain.Shutdown()
+
+	typed, err := rModel.NewTypedBrain[doubleIn, doubleOut](brain)
+	if err != nil {
+		t.Fatalf("new typed brain error: %v", err)
+	}
+	for _, n := range []int{1, 5} {
+		out, err := typed.Run(context.Background(), doubleIn{N: n})
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+		if out.Out != n*2 {
+			t.Errorf("expect out %d, got %d", n*2, out.Out)
+		}
+	}
+
+	// the run error is returned
+	if _, err = typed.Run(context.Background(), doubleIn{N: -1}); err == nil || !strings.Contains(err.Error(), "negative input") {
+		t.Errorf("expect run error, got %v", err)
+	}
+
+	// output memory of a mismatched type
+	mismatched, _ := rModel.NewTypedBrain[doubleIn, struct {
+		Out string `rmodel:"out"`
+	}](brain)
+	if _, err = mismatched.Run(context.Background(), doubleIn{N: 1}); err == nil || !strings.Contains(err.Error(), "can not be assigned") {
+		t.Errorf("expect type mismatch error, got %v", err)
+	}
+
+	// missing output memory
+	missing, _ := rModel.NewTypedBrain[doubleIn, struct {
+		Other int `rmodel:"other"`
+	}](brain)
+	if _, err = missing.Run(context.Background(), doubleIn{N: 1}); err == nil || !strings.Contains(err.Error(), "memory other") {
+		t.Errorf("expect missing memory error, got %v", err)
+	}
+
+	// unmapped fields and non-struct types
+	if _, err = rModel.NewTypedBrain[struct{ N int }, doubleOut](brain); err == nil || !strings.Contains(err.Error(), "field N") {
+		t.Errorf("expect unmapped field error, got %v", err)
+	}
+	if _, err = rModel.NewTypedBrain[int, doubleOut](brain); err == nil {
+		t.Errorf("expect error for a non-struct input")
+	}
+}
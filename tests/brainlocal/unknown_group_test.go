@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestUnknownGroupPolicy(t *testing.T) {
+	cases := []struct {
+		name        string
+		policy      core.UnknownGroupPolicy
+		withDefault bool
+		// index of the neurons executed, 0: route, 1: the neuron of group a, 2: the neuron of the default group
+		executed   []int
+		err        error
+		reachedEnd bool
+	}{
+		{name: "error", policy: core.UnknownGroupError, withDefault: true, executed: []int{0}, err: core.ErrUnknownCastGroup},
+		{name: "fallback", policy: core.UnknownGroupFallbackToDefault, withDefault: true, executed: []int{0, 2}, reachedEnd: true},
+		{name: "fallback without default", policy: core.UnknownGroupFallbackToDefault, executed: []int{0}, err: core.ErrUnknownCastGroup},
+		{name: "terminate", policy: core.UnknownGroupTerminate, withDefault: true, executed: []int{0}, reachedEnd: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bp := rModel.NewBlueprint()
+			route := bp.AddNeuron(emptyProcess, core.WithSelector(processor.NewFuncSelector(func(processor.BrainContextReader) string {
+				return "missing"
+			})))
+			a := bp.AddNeuron(emptyProcess)
+			d := bp.AddNeuron(emptyProcess)
+			_, _ = bp.AddEntryLinkTo(route)
+			toA, _ := bp.AddLink(route, a)
+			_ = route.AddCastGroup("a", toA)
+			toD, _ := bp.AddLink(route, d)
+			if !c.withDefault {
+				// the default group of the route has no link
+				_ = route.AddCastGroup("d", toD)
+			}
+			_, _ = bp.AddEndLinkFrom(a)
+			_, _ = bp.AddEndLinkFrom(d)
+
+			brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(1), brainlocal.WithUnknownGroupPolicy(c.policy))
+			defer brain.Shutdown()
+			_ = brain.Entry()
+			brain.Wait()
+
+			neurons := []string{route.GetID(), a.GetID(), d.GetID()}
+			expect := make([]string, 0, len(c.executed))
+			for _, i := range c.executed {
+				expect = append(expect, neurons[i])
+			}
+			if executed := brain.GetExecutedNeurons(); !reflect.DeepEqual(executed, expect) {
+				t.Errorf("expect executed %v, got %v", expect, executed)
+			}
+			if err := brain.GetRunError(); !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+				t.Errorf("expect run error %v, got %v", c.err, err)
+			}
+			ends := brain.GetReachedEnds()
+			if reached := len(ends) == 1 && ends[0] == core.EndNeuronIDOf(""); reached != c.reachedEnd {
+				t.Errorf("expect the End neuron reached: %t, got %v", c.reachedEnd, ends)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestWhyNotFired(t *testing.T) {
+	noop := func(bc processor.BrainContext) error { return nil }
+	bp := rModel.NewBlueprint()
+	// router selects left, join waits for both left and right
+	router := bp.AddNeuron(noop, core.WithSelectFn(func(bcr processor.BrainContextReader) string {
+		return "left"
+	}))
+	left := bp.AddNeuron(noop)
+	right := bp.AddNeuron(noop)
+	join := bp.AddNeuron(noop)
+	orphan := bp.AddNeuron(noop)
+	_, _ = bp.AddEntryLinkTo(router)
+	toLeft, _ := bp.AddLink(router, left)
+	toRight, _ := bp.AddLink(router, right)
+	_ = router.AddCastGroup("left", toLeft)
+	_ = router.AddCastGroup("right", toRight)
+	fromLeft, _ := bp.AddLink(left, join)
+	fromRight, _ := bp.AddLink(right, join)
+	_ = join.AddTriggerGroup(fromLeft, fromRight)
+	toOrphan, _ := bp.AddLink(right, orphan)
+
+	// the join can never fire, the run ends as a deadlock
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithDeadlockDetection())
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+
+	cases := []struct {
+		neuronID string
+		contains []string
+	}{
+		{left.GetID(), []string{"fired 1 times"}},
+		{right.GetID(), []string{
+			fmt.Sprintf("upstream neuron %s selected cast group left, which does not cast link %s", router.GetID(), toRight.GetID()),
+		}},
+		{join.GetID(), []string{
+			fmt.Sprintf("needs links [%s %s], arrived [%s]", fromLeft.GetID(), fromRight.GetID(), fromLeft.GetID()),
+			fmt.Sprintf("upstream neuron %s of link %s did not fire", right.GetID(), fromRight.GetID()),
+		}},
+		{orphan.GetID(), []string{
+			fmt.Sprintf("upstream neuron %s of link %s did not fire", right.GetID(), toOrphan.GetID()),
+		}},
+		{"unknown", []string{"neuron unknown not found"}},
+	}
+	for _, c := range cases {
+		why := brain.WhyNotFired(c.neuronID)
+		for _, s := range c.contains {
+			if !strings.Contains(why, s) {
+				t.Errorf("expect %q in the explanation, got %q", s, why)
+			}
+		}
+	}
+}
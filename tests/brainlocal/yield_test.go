@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rovanta/rmodel"
+	"github.com/Rovanta/rmodel/brainlocal"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestYield(t *testing.T) {
+	var mu sync.Mutex
+	events := make([]string, 0)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	recorded := func(event string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range events {
+			if e == event {
+				return true
+			}
+		}
+		return false
+	}
+
+	bp := rModel.NewBlueprint()
+	heavy := bp.AddNeuron(func(bc processor.BrainContext) error {
+		record("heavy start")
+		// the chunks of a long computation, the light neuron is queued meanwhile
+		for i := 0; i < 10000 && !recorded("light"); i++ {
+			bc.Yield()
+		}
+		record("heavy end")
+		return nil
+	})
+	light := bp.AddNeuron(func(bc processor.BrainContext) error {
+		record("light")
+		return nil
+	})
+	_, _ = bp.AddEntryLinkTo(heavy)
+	_, _ = bp.AddEntryLinkTo(light)
+	// the run ends with the heavy neuron
+	_, _ = bp.AddEndLinkFrom(heavy)
+	// the heavy neuron is entered first
+	bp.Canonicalize()
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(1))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+
+	expect := []string{"heavy start", "light", "heavy end"}
+	if !reflect.DeepEqual(events, expect) {
+		t.Errorf("expect the light neuron run on the yield of the heavy one %v, got %v", expect, events)
+	}
+}
+
+func TestYieldKeepsWorkerNum(t *testing.T) {
+	// processes running and not yielding, at most the number of workers
+	var running, maxRunning int64
+	enter := func() {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			max := atomic.LoadInt64(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt64(&maxRunning, max, n) {
+				return
+			}
+		}
+	}
+	leave := func() { atomic.AddInt64(&running, -1) }
+	yielding := func(bc processor.BrainContext) error {
+		enter()
+		defer leave()
+		for i := 0; i < 100; i++ {
+			leave()
+			bc.Yield()
+			enter()
+		}
+		return nil
+	}
+
+	bp := rModel.NewBlueprint()
+	join := bp.AddNeuron(func(bc processor.BrainContext) error { return nil })
+	for i := 0; i < 4; i++ {
+		n := bp.AddNeuron(yielding)
+		_, _ = bp.AddEntryLinkTo(n)
+		_, _ = bp.AddLink(n, join)
+	}
+	_, _ = bp.AddEndLinkFrom(join)
+
+	brain := brainlocal.BuildBrain(bp, brainlocal.WithNeuronWorkerNum(2))
+	defer brain.Shutdown()
+	_ = brain.Entry()
+	brain.Wait()
+	if err := brain.GetRunError(); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	if len(brain.GetReachedEnds()) != 1 {
+		t.Fatalf("expect the End reached, got %v", brain.GetReachedEnds())
+	}
+	if max := atomic.LoadInt64(&maxRunning); max > 2 {
+		t.Errorf("expect at most 2 processes running, got %d", max)
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestAssertProcessor(t *testing.T) {
+	ctx := newMemoryContext("status", "ok", "score", 0.8, "count", 3, "name", "b")
+
+	p := processor.NewAssertProcessor([]processor.Assertion{
+		{Key: "status", Op: processor.AssertEq, Expected: "ok"},
+		{Key: "status", Op: processor.AssertNe, Expected: "failed"},
+		{Key: "score", Op: processor.AssertGt, Expected: 0.5},
+		{Key: "count", Op: processor.AssertEq, Expected: 3.0},
+		{Key: "count", Op: processor.AssertLt, Expected: int64(10)},
+		{Key: "name", Op: processor.AssertGt, Expected: "a"},
+		{Key: "name", Op: processor.AssertExists},
+	})
+	if err := p.Clone().Process(ctx); err != nil {
+		t.Fatalf("expect all assertions pass, got %v", err)
+	}
+
+	cases := []struct {
+		assertion processor.Assertion
+		failed    bool
+		message   string
+	}{
+		{processor.Assertion{Key: "score", Op: processor.AssertGt, Expected: 0.9}, true, "score gt 0.9, got 0.8"},
+		{processor.Assertion{Key: "status", Op: processor.AssertEq, Expected: "failed"}, true, "status eq failed, got ok"},
+		{processor.Assertion{Key: "missing", Op: processor.AssertExists}, true, "missing exists, memory missing not found"},
+		{processor.Assertion{Key: "status", Op: processor.AssertLt, Expected: 1}, false, "can not compare string"},
+		{processor.Assertion{Key: "status", Op: "like", Expected: "ok"}, false, "unsupported operator"},
+	}
+	for _, c := range cases {
+		// the first failed assertion is reported
+		err := processor.NewAssertProcessor([]processor.Assertion{c.assertion, {Key: "missing", Op: processor.AssertExists}}).Process(ctx)
+		if err == nil {
+			t.Errorf("assertion %s: expect error", c.assertion)
+			continue
+		}
+		if errors.Is(err, processor.ErrAssertionFailed) != c.failed {
+			t.Errorf("assertion %s: expect failed %v, got %v", c.assertion, c.failed, err)
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("assertion %s: expect error containing %q, got %q", c.assertion, c.message, err.Error())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestBarrierProcessor(t *testing.T) {
+	p := processor.NewBarrierProcessor([]string{"legal", "finance", "legal"})
+	if err := p.Signal("hr", true); !errors.Is(err, processor.ErrUnknownSignal) {
+		t.Errorf("expect ErrUnknownSignal, got %v", err)
+	}
+	// a signal arrived before the process is kept for it
+	_ = p.Signal("legal", "approved")
+
+	ctx := newMemoryContext()
+	done := make(chan error)
+	go func() {
+		done <- p.Clone().Process(ctx)
+	}()
+	if missing := p.Missing(); !reflect.DeepEqual(missing, []string{"finance"}) {
+		t.Errorf("expect finance missing, got %v", missing)
+	}
+	_ = p.Signal("finance", 42)
+	if err := <-done; err != nil {
+		t.Fatalf("process error: %s", err)
+	}
+	if ctx.GetMemory("legal") != "approved" || ctx.GetMemory("finance") != 42 {
+		t.Errorf("expect the payloads in the memory, got %v", ctx.memory)
+	}
+	// the signals are consumed by the process
+	if missing := p.Missing(); len(missing) != 2 {
+		t.Errorf("expect the signals consumed, missing %v", missing)
+	}
+}
+
+func TestBarrierProcessorCancelled(t *testing.T) {
+	p := processor.NewBarrierProcessor([]string{"legal", "finance"})
+	_ = p.Signal("legal", "approved")
+
+	ctx := newMemoryContext()
+	cctx, cancel := context.WithCancel(context.Background())
+	ctx.ctx = cctx
+	cancel()
+	if err := p.Process(ctx); !errors.Is(err, processor.ErrBarrierCancelled) {
+		t.Fatalf("expect ErrBarrierCancelled, got %v", err)
+	}
+	if ctx.ExistMemory("legal") {
+		t.Errorf("expect no memory set by a cancelled barrier")
+	}
+}
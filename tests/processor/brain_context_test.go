@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// memoryContext is a map-backed processor.BrainContext for running processors without a brain
+type memoryContext struct {
+	mu          sync.Mutex
+	memory      map[interface{}]interface{}
+	internal    map[string]interface{}
+	neuronID    string
+	triggeredBy string
+	rand        *rand.Rand
+	ctx         context.Context
+	payloads    map[string]interface{}
+	local       processor.LocalStore
+}
+
+func newMemoryContext(keysAndValues ...interface{}) *memoryContext {
+	c := &memoryContext{
+		memory:   make(map[interface{}]interface{}),
+		internal: make(map[string]interface{}),
+		neuronID: "test-neuron",
+		rand:     rand.New(rand.NewSource(1)),
+		ctx:      context.Background(),
+	}
+	_ = c.SetMemory(keysAndValues...)
+	return c
+}
+
+func (c *memoryContext) SetMemory(keysAndValues ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		c.memory[keysAndValues[i]] = keysAndValues[i+1]
+	}
+	return nil
+}
+
+func (c *memoryContext) GetMemory(key interface{}) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.memory[key]
+}
+
+func (c *memoryContext) ExistMemory(key interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.memory[key]
+	return ok
+}
+
+func (c *memoryContext) DeleteMemory(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.memory, key)
+}
+
+func (c *memoryContext) ClearMemory() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory = make(map[interface{}]interface{})
+	c.internal = make(map[string]interface{})
+}
+
+func (c *memoryContext) GetInternalMemory(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.internal[key]
+	return v, ok
+}
+
+func (c *memoryContext) SetInternalMemory(key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.internal[key] = value
+	return nil
+}
+
+func (c *memoryContext) SetStream(key interface{}, r io.ReadCloser) error {
+	return c.SetMemory(key, processor.NewStream(r))
+}
+
+func (c *memoryContext) GetStream(key interface{}) (io.ReadCloser, error) {
+	stream, ok := c.GetMemory(key).(*processor.Stream)
+	if !ok {
+		return nil, processor.ErrNotStream
+	}
+	return stream.Take()
+}
+
+func (c *memoryContext) GetCurrentNeuronID() string {
+	return c.neuronID
+}
+
+func (c *memoryContext) GetTriggeredBy() string {
+	return c.triggeredBy
+}
+
+func (c *memoryContext) GetCurrentNeuronLabels() map[string]string {
+	return map[string]string{}
+}
+
+func (c *memoryContext) GetBrainID() string {
+	return "test-brain"
+}
+
+func (c *memoryContext) GetBrainLabels() map[string]string {
+	return map[string]string{}
+}
+
+func (c *memoryContext) ContinueCast() {}
+
+func (c *memoryContext) GetAbsentInputs() []string {
+	return []string{}
+}
+
+func (c *memoryContext) RegisterCompensation(fn func(ctx context.Context) error) {}
+
+func (c *memoryContext) Defer(fn func()) {}
+
+func (c *memoryContext) Yield() {}
+
+func (c *memoryContext) Local() *processor.LocalStore {
+	return &c.local
+}
+
+func (c *memoryContext) Rand() *rand.Rand {
+	return c.rand
+}
+
+func (c *memoryContext) IdempotencyKey() string {
+	return c.neuronID + "/1"
+}
+
+func (c *memoryContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *memoryContext) SetCastPayload(group string, payload interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.payloads == nil {
+		c.payloads = make(map[string]interface{})
+	}
+	c.payloads[group] = payload
+	return nil
+}
+
+func (c *memoryContext) GetPayloads() map[string]interface{} {
+	return map[string]interface{}{}
+}
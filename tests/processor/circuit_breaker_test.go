@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	calls := 0
+	fail := true
+	dep := processor.NewFuncProcessor(func(ctx processor.BrainContext) error {
+		calls++
+		if fail {
+			return errors.New("dependency unavailable")
+		}
+		return nil
+	})
+	p := processor.WithCircuitBreaker(dep, processor.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		Now:              func() time.Time { return now },
+	})
+	ctx := newMemoryContext()
+	expectState := func(step string, state processor.CircuitState) {
+		t.Helper()
+		if got := p.State(); got != state {
+			t.Fatalf("%s: expect state %s, got %s", step, state, got)
+		}
+	}
+
+	// consecutive failures open the circuit, a clone shares it
+	_ = p.Process(ctx)
+	expectState("first failure", processor.CircuitClosed)
+	_ = p.Clone().Process(ctx)
+	expectState("second failure", processor.CircuitOpen)
+	if err := p.Process(ctx); !errors.Is(err, processor.ErrCircuitOpen) {
+		t.Fatalf("expect ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expect the open circuit short-circuits, got %d calls", calls)
+	}
+
+	// a failed probe opens the circuit again
+	now = now.Add(time.Minute)
+	expectState("cooldown over", processor.CircuitHalfOpen)
+	_ = p.Process(ctx)
+	expectState("failed probe", processor.CircuitOpen)
+	if calls != 3 {
+		t.Fatalf("expect one probe, got %d calls", calls)
+	}
+
+	// a successful probe closes the circuit and resets the failures
+	now = now.Add(time.Minute)
+	fail = false
+	if err := p.Process(ctx); err != nil {
+		t.Fatalf("expect the probe succeeded, got %v", err)
+	}
+	expectState("successful probe", processor.CircuitClosed)
+	fail = true
+	_ = p.Process(ctx)
+	expectState("failure after close", processor.CircuitClosed)
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	fail := true
+	dep := processor.NewFuncProcessor(func(ctx processor.BrainContext) error {
+		if fail {
+			return errors.New("dependency unavailable")
+		}
+		close(probing)
+		<-release
+		return nil
+	})
+	p := processor.WithCircuitBreaker(dep, processor.CircuitBreakerConfig{
+		Cooldown: time.Second,
+		Now:      func() time.Time { return now },
+	})
+	ctx := newMemoryContext()
+	_ = p.Process(ctx)
+	now = now.Add(time.Second)
+	fail = false
+
+	done := make(chan error)
+	go func() {
+		done <- p.Process(ctx)
+	}()
+	<-probing
+	// only one probe runs in the half-open state
+	if err := p.Clone().Process(ctx); !errors.Is(err, processor.ErrCircuitOpen) {
+		t.Errorf("expect ErrCircuitOpen during the probe, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expect the probe succeeded, got %v", err)
+	}
+	if p.State() != processor.CircuitClosed {
+		t.Errorf("expect the circuit closed, got %s", p.State())
+	}
+}
+
+func TestCircuitBreakerPanickingProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	calls := 0
+	panics := true
+	dep := processor.NewFuncProcessor(func(ctx processor.BrainContext) error {
+		calls++
+		if panics {
+			panic("dependency client panics")
+		}
+		return nil
+	})
+	p := processor.WithCircuitBreaker(dep, processor.CircuitBreakerConfig{
+		Cooldown: time.Second,
+		Now:      func() time.Time { return now },
+	})
+	ctx := newMemoryContext()
+	process := func() (panicked bool, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+			}
+		}()
+		return false, p.Process(ctx)
+	}
+
+	// the panic is passed on, and counted as a failure
+	if panicked, _ := process(); !panicked {
+		t.Fatalf("expect the panic passed on")
+	}
+	if p.State() != processor.CircuitOpen {
+		t.Fatalf("expect the circuit open, got %s", p.State())
+	}
+
+	// a panicking probe opens the circuit again, and ends the probe
+	now = now.Add(time.Second)
+	if panicked, _ := process(); !panicked {
+		t.Fatalf("expect the probe panics")
+	}
+	if p.State() != processor.CircuitOpen {
+		t.Fatalf("expect the circuit open after the panicking probe, got %s", p.State())
+	}
+	now = now.Add(time.Second)
+	panics = false
+	if _, err := process(); err != nil {
+		t.Fatalf("expect the next probe runs, got %v", err)
+	}
+	if calls != 3 || p.State() != processor.CircuitClosed {
+		t.Errorf("expect the circuit closed by the third call, got %d calls, state %s", calls, p.State())
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestConfigProcessor(t *testing.T) {
+	resolved := 0
+	p := processor.NewConfigProcessor(func() (map[string]interface{}, error) {
+		resolved++
+		return map[string]interface{}{"region": "eu", "retries": 3}, nil
+	})
+	if resolved != 0 {
+		t.Fatalf("expect the config resolved lazily, got %d", resolved)
+	}
+	for i := 0; i < 3; i++ {
+		ctx := newMemoryContext()
+		if err := p.Clone().Process(ctx); err != nil {
+			t.Fatalf("process error: %v", err)
+		}
+		if ctx.GetMemory("region") != "eu" || ctx.GetMemory("retries") != 3 {
+			t.Errorf("expect the config in memory, got region %v retries %v", ctx.GetMemory("region"), ctx.GetMemory("retries"))
+		}
+	}
+	if resolved != 1 {
+		t.Errorf("expect the config resolved once, got %d", resolved)
+	}
+
+	missing := errors.New("CONFIG_PATH not set")
+	failing := processor.NewConfigProcessor(func() (map[string]interface{}, error) {
+		return nil, missing
+	})
+	if err := failing.ResolveAtBuild(); !errors.Is(err, missing) {
+		t.Errorf("expect error %v, got %v", missing, err)
+	}
+	if err := failing.Process(newMemoryContext()); !errors.Is(err, missing) {
+		t.Errorf("expect process error %v, got %v", missing, err)
+	}
+}
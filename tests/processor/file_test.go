@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+// memoryFS is an in-memory processor.WriteFileFS
+type memoryFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memoryFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func TestFileReadProcessor(t *testing.T) {
+	fsys := fstest.MapFS{"in/a.txt": {Data: []byte("hello")}}
+	p := processor.NewFileReadProcessorWithFS(fsys, "path", "out")
+
+	ctx := newMemoryContext("path", "in/a.txt")
+	if err := p.Clone().Process(ctx); err != nil {
+		t.Fatalf("process error: %v", err)
+	}
+	if out, _ := ctx.GetMemory("out").([]byte); string(out) != "hello" {
+		t.Errorf("expect out %q, got %v", "hello", ctx.GetMemory("out"))
+	}
+
+	ctx = newMemoryContext("path", "in/missing.txt")
+	if err := p.Process(ctx); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expect fs.ErrNotExist, got %v", err)
+	}
+	if ctx.ExistMemory("out") {
+		t.Errorf("expect no out memory after a failed read")
+	}
+
+	if err := p.Process(newMemoryContext()); err == nil {
+		t.Errorf("expect error without path memory")
+	}
+}
+
+func TestFileWriteProcessor(t *testing.T) {
+	fsys := &memoryFS{}
+	p := processor.NewFileWriteProcessorWithFS(fsys, "path", "data")
+
+	if err := p.Clone().Process(newMemoryContext("path", "out/a.txt", "data", []byte("bytes"))); err != nil {
+		t.Fatalf("process error: %v", err)
+	}
+	if err := p.Process(newMemoryContext("path", "out/b.txt", "data", "string")); err != nil {
+		t.Fatalf("process error: %v", err)
+	}
+	if got := string(fsys.files["out/a.txt"]); got != "bytes" {
+		t.Errorf("expect out/a.txt %q, got %q", "bytes", got)
+	}
+	if got := string(fsys.files["out/b.txt"]); got != "string" {
+		t.Errorf("expect out/b.txt %q, got %q", "string", got)
+	}
+
+	if err := p.Process(newMemoryContext("path", "out/c.txt", "data", 1)); err == nil {
+		t.Errorf("expect error for data of unsupported type")
+	}
+	if err := p.Process(newMemoryContext("path", "out/c.txt")); err == nil {
+		t.Errorf("expect error without data memory")
+	}
+	if _, ok := fsys.files["out/c.txt"]; ok {
+		t.Errorf("expect out/c.txt not written")
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+type echoRequest struct {
+	Message string
+}
+
+type echoReply struct {
+	Message string
+}
+
+// fakeInvoker is a processor.GRPCInvoker replying the message of an echoRequest
+type fakeInvoker struct {
+	method string
+	err    error
+	ctx    context.Context
+}
+
+func (f *fakeInvoker) Invoke(ctx context.Context, method string, req, resp interface{}) error {
+	f.method = method
+	f.ctx = ctx
+	if f.err != nil {
+		return f.err
+	}
+	reply := echoReply{Message: req.(echoRequest).Message}
+	switch r := resp.(type) {
+	case *echoReply:
+		*r = reply
+	case *interface{}:
+		*r = reply
+	default:
+		return errors.New("unsupported response type")
+	}
+	return nil
+}
+
+func TestGRPCProcessor(t *testing.T) {
+	invoker := &fakeInvoker{}
+	p := processor.NewGRPCProcessor(invoker, "method", "req", "resp")
+
+	ctx := newMemoryContext("method", "/echo.Echo/Say", "req", echoRequest{Message: "hi"})
+	if err := p.Clone().Process(ctx); err != nil {
+		t.Fatalf("process error: %v", err)
+	}
+	if invoker.method != "/echo.Echo/Say" {
+		t.Errorf("expect method %q, got %q", "/echo.Echo/Say", invoker.method)
+	}
+	if invoker.ctx != ctx.Context() {
+		t.Errorf("expect the call with the context of the brain context")
+	}
+	if resp, _ := ctx.GetMemory("resp").(echoReply); resp.Message != "hi" {
+		t.Errorf("expect reply %q, got %v", "hi", ctx.GetMemory("resp"))
+	}
+
+	if err := p.Process(newMemoryContext("req", echoRequest{})); err == nil {
+		t.Errorf("expect error without method memory")
+	}
+	if err := p.Process(newMemoryContext("method", "/echo.Echo/Say")); err == nil {
+		t.Errorf("expect error without request memory")
+	}
+}
+
+func TestGRPCProcessorWithResponse(t *testing.T) {
+	p := processor.NewGRPCProcessorWithResponse(&fakeInvoker{}, "method", "req", "resp", func() interface{} {
+		return &echoReply{}
+	})
+
+	ctx := newMemoryContext("method", "/echo.Echo/Say", "req", echoRequest{Message: "hi"})
+	if err := p.Process(ctx); err != nil {
+		t.Fatalf("process error: %v", err)
+	}
+	if resp, _ := ctx.GetMemory("resp").(*echoReply); resp == nil || resp.Message != "hi" {
+		t.Errorf("expect reply %q, got %v", "hi", ctx.GetMemory("resp"))
+	}
+
+	unavailable := errors.New("unavailable")
+	p = processor.NewGRPCProcessor(&fakeInvoker{err: unavailable}, "method", "req", "resp")
+	ctx = newMemoryContext("method", "/echo.Echo/Say", "req", echoRequest{})
+	if err := p.Process(ctx); !errors.Is(err, unavailable) {
+		t.Errorf("expect error %v, got %v", unavailable, err)
+	}
+	if ctx.ExistMemory("resp") {
+		t.Errorf("expect no resp memory after a failed call")
+	}
+}
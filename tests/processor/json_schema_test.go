@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+var userSchema = []byte(`{
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"enum": ["admin", "user"]}}
+	}
+}`)
+
+func TestJSONSchemaValidator(t *testing.T) {
+	p, err := processor.NewJSONSchemaValidator(userSchema, "name", "age", "tags")
+	if err != nil {
+		t.Fatalf("new json schema validator error: %s", err)
+	}
+
+	cases := []struct {
+		name    string
+		memory  []interface{}
+		wantErr bool
+	}{
+		{"valid", []interface{}{"name", "Clay", "age", 18, "tags", []string{"admin"}}, false},
+		{"missing required", []interface{}{"name", "Clay"}, true},
+		{"wrong type", []interface{}{"name", "Clay", "age", "18"}, true},
+		{"not integer", []interface{}{"name", "Clay", "age", 1.5}, true},
+		{"below minimum", []interface{}{"name", "Clay", "age", -1}, true},
+		{"empty string", []interface{}{"name", "", "age", 1}, true},
+		{"enum violation", []interface{}{"name", "Clay", "age", 1, "tags", []string{"root"}}, true},
+	}
+	for _, c := range cases {
+		err := p.Process(newMemoryContext(c.memory...))
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: want error %v, got %v", c.name, c.wantErr, err)
+		}
+	}
+}
+
+func TestJSONSchemaValidatorRejectsSchema(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema string
+	}{
+		{"malformed", `{"type": "object"`},
+		{"unsupported keyword", `{"type": "string", "pattern": "^a"}`},
+		{"unsupported nested keyword", `{"properties": {"tags": {"items": {"anyOf": [{"type": "string"}]}}}}`},
+		{"ref", `{"$ref": "#/definitions/user"}`},
+		{"additionalProperties schema", `{"additionalProperties": {"type": "string"}}`},
+		{"invalid keyword value", `{"minimum": "0"}`},
+	}
+	for _, c := range cases {
+		if _, err := processor.NewJSONSchemaValidator([]byte(c.schema), "name"); err == nil {
+			t.Errorf("%s: expect compile error", c.name)
+		}
+	}
+
+	// annotations do not affect validation
+	if _, err := processor.NewJSONSchemaValidator([]byte(`{"title": "user", "description": "a user", "type": "object"}`), "name"); err != nil {
+		t.Errorf("expect annotations supported, got %s", err)
+	}
+}
+
+type fakeSchemaCompiler struct {
+	schema   string
+	document string
+}
+
+func (c *fakeSchemaCompiler) Compile(schema []byte) (processor.SchemaValidator, error) {
+	c.schema = string(schema)
+	return c, nil
+}
+
+func (c *fakeSchemaCompiler) Validate(document []byte) error {
+	c.document = string(document)
+	return errors.New("rejected")
+}
+
+func TestJSONSchemaValidatorWithCompiler(t *testing.T) {
+	c := &fakeSchemaCompiler{}
+	// the compiler decides which keywords are supported
+	p, err := processor.NewJSONSchemaValidatorWithCompiler(c, []byte(`{"$ref": "#/user"}`), "name")
+	if err != nil {
+		t.Fatalf("new json schema validator error: %s", err)
+	}
+	if c.schema != `{"$ref": "#/user"}` {
+		t.Errorf("unexpected compiled schema: %s", c.schema)
+	}
+
+	if err := p.Process(newMemoryContext("name", "Clay")); err == nil {
+		t.Fatalf("expect error from the compiled validator")
+	}
+	if c.document != `{"name":"Clay"}` {
+		t.Errorf("unexpected validated document: %s", c.document)
+	}
+}
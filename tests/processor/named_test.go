@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+type customProcessor struct{}
+
+type customNamedProcessor struct{}
+
+func (p *customNamedProcessor) Kind() string {
+	return "custom"
+}
+
+func TestKindOf(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		kind string
+	}{
+		{&processor.DefaultSelector{}, "default"},
+		{processor.NewFuncSelector(nil), "func"},
+		{processor.NewTriggerGroupSelector(nil), "trigger_group"},
+		{processor.NewQuotaSelector(nil), "quota"},
+		{processor.NewFuncProcessor(nil), "func"},
+		{&processor.EmptyProcessor{}, "empty"},
+		{processor.NewTemplateProcessor("", "out"), "template"},
+		{&processor.JSONSchemaValidatorProcessor{}, "json_schema_validator"},
+		{processor.NewFileReadProcessor("path", "out"), "file_read"},
+		{processor.NewFileWriteProcessor("path", "data"), "file_write"},
+		{&processor.SwitchProcessor{}, "switch"},
+		{processor.NewAssertProcessor(nil), "assert"},
+		{processor.NewGRPCProcessor(nil, "method", "req", "resp"), "grpc"},
+		{processor.Once(nil, nil), "once"},
+		{&processor.ConfigProcessor{}, "config"},
+		{processor.WithCircuitBreaker(nil, processor.CircuitBreakerConfig{}), "circuit_breaker"},
+		{&customNamedProcessor{}, "custom"},
+		{&customProcessor{}, "customProcessor"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if kind := processor.KindOf(c.v); kind != c.kind {
+			t.Errorf("expect kind %q, got %q", c.kind, kind)
+		}
+	}
+}
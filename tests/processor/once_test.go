@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestOnce(t *testing.T) {
+	calls := 0
+	fail := true
+	charge := processor.NewFuncProcessor(func(ctx processor.BrainContext) error {
+		calls++
+		if fail {
+			return errors.New("gateway timeout")
+		}
+		return nil
+	})
+	p := processor.Once(charge, nil)
+
+	ctx := newMemoryContext()
+	// a failed process is not recorded, the retry runs it again
+	if err := p.Process(ctx); err == nil {
+		t.Fatalf("expect the process error")
+	}
+	fail = false
+	for i := 0; i < 3; i++ {
+		if err := p.Clone().Process(ctx); err != nil {
+			t.Fatalf("process error: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expect 2 calls, got %d", calls)
+	}
+	if _, ok := ctx.GetInternalMemory(processor.OnceMemoryKeyPrefix + ctx.IdempotencyKey()); !ok {
+		t.Errorf("expect the idempotency key recorded in the internal memory")
+	}
+	if ctx.ExistMemory(processor.OnceMemoryKeyPrefix + ctx.IdempotencyKey()) {
+		t.Errorf("expect the idempotency key not in the user memory")
+	}
+	if !core.IsReservedMemoryKey(processor.OnceMemoryKeyPrefix) {
+		t.Errorf("expect the prefix %s reserved", processor.OnceMemoryKeyPrefix)
+	}
+
+	// the key is derived from memory by keyFn
+	calls = 0
+	p = processor.Once(charge, func(ctx processor.BrainContextReader) string {
+		return ctx.GetMemory("orderID").(string)
+	})
+	ctx = newMemoryContext("orderID", "o-1")
+	_ = p.Process(ctx)
+	_ = p.Process(ctx)
+	_ = ctx.SetMemory("orderID", "o-2")
+	_ = p.Process(ctx)
+	if calls != 2 {
+		t.Errorf("expect 1 call for each order, got %d calls", calls)
+	}
+}
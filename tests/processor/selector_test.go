@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTriggerGroupSelector(t *testing.T) {
+	s := processor.NewTriggerGroupSelector(map[string]string{"fromA": "toX"})
+
+	ctx := newMemoryContext()
+	ctx.triggeredBy = "fromA"
+	if group := s.Select(ctx); group != "toX" {
+		t.Errorf("expect group toX, got %s", group)
+	}
+	ctx.triggeredBy = "unmapped"
+	if group := s.Clone().Select(ctx); group != processor.DefaultCastGroupName {
+		t.Errorf("unmapped trigger group should select the default group, got %s", group)
+	}
+}
+
+func TestTriggerGroupSelectorPossibleGroups(t *testing.T) {
+	s := processor.NewTriggerGroupSelector(map[string]string{
+		"fromC": "toY",
+		"fromA": "toX",
+		"fromB": "toY",
+		"fromD": processor.DefaultCastGroupName,
+	})
+	expect := []string{processor.DefaultCastGroupName, "toX", "toY"}
+	for i := 0; i < 10; i++ {
+		if groups := s.PossibleGroups(); !reflect.DeepEqual(groups, expect) {
+			t.Fatalf("expect possible groups %v, got %v", expect, groups)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestSwitchProcessor(t *testing.T) {
+	setOut := func(v string) processor.Processor {
+		return processor.NewFuncProcessor(func(ctx processor.BrainContext) error {
+			return ctx.SetMemory("out", v)
+		})
+	}
+	chooser := func(bcr processor.BrainContextReader) string {
+		kind, _ := bcr.GetMemory("kind").(string)
+		return kind
+	}
+	cases := map[string]processor.Processor{
+		"a": setOut("case a"),
+		"b": setOut("case b"),
+	}
+
+	p, err := processor.NewSwitch(chooser, cases, setOut("default"))
+	if err != nil {
+		t.Fatalf("new switch error: %v", err)
+	}
+	for kind, expect := range map[string]string{"a": "case a", "b": "case b", "c": "default"} {
+		ctx := newMemoryContext("kind", kind)
+		if err := p.Clone().Process(ctx); err != nil {
+			t.Fatalf("kind %s: process error: %v", kind, err)
+		}
+		if out := ctx.GetMemory("out"); out != expect {
+			t.Errorf("kind %s: expect out %q, got %v", kind, expect, out)
+		}
+	}
+
+	// an unmatched key is an error without the default case
+	p, err = processor.NewSwitch(chooser, cases, nil)
+	if err != nil {
+		t.Fatalf("new switch without default case error: %v", err)
+	}
+	ctx := newMemoryContext("kind", "c")
+	if err := p.Process(ctx); !errors.Is(err, processor.ErrSwitchCaseNotFound) {
+		t.Errorf("expect switch case not found for an unmatched key without the default case, got %v", err)
+	}
+	if ctx.ExistMemory("out") {
+		t.Errorf("expect no case processed for an unmatched key")
+	}
+}
+
+func TestSwitchProcessorNilCase(t *testing.T) {
+	chooser := func(bcr processor.BrainContextReader) string { return "a" }
+	if _, err := processor.NewSwitch(chooser, map[string]processor.Processor{"a": nil}, nil); !errors.Is(err, processor.ErrNilSwitchCase) {
+		t.Errorf("expect nil switch case for a nil case, got %v", err)
+	}
+	if _, err := processor.NewSwitch(nil, nil, &processor.EmptyProcessor{}); !errors.Is(err, processor.ErrNilSwitchCase) {
+		t.Errorf("expect nil switch case for a nil chooser, got %v", err)
+	}
+}
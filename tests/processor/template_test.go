@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestTemplateProcessor(t *testing.T) {
+	user := map[string]interface{}{
+		"name": "Clay",
+		"address": map[string]interface{}{
+			"city": "Shanghai",
+		},
+	}
+
+	cases := []struct {
+		name   string
+		tmpl   string
+		memory []interface{}
+		expect string
+	}{
+		{"flat", "hello {{.name}}", []interface{}{"name", "Clay"}, "hello Clay"},
+		{"nested", "{{.user.name}} lives in {{.user.address.city}}", []interface{}{"user", user}, "Clay lives in Shanghai"},
+		{"root variable in range", "{{range .tags}}{{.}}@{{$.user.name}} {{end}}", []interface{}{"tags", []string{"a", "b"}, "user", user}, "a@Clay b@Clay "},
+		{"no escaping by default", "{{.q}}", []interface{}{"q", `<a&b "c">`}, `<a&b "c">`},
+		{"urlquery escaping", "https://example.com/search?q={{urlquery .q}}", []interface{}{"q", "a b&c"}, "https://example.com/search?q=a+b%26c"},
+		{"html escaping", "{{html .q}}", []interface{}{"q", `<a&b>`}, "&lt;a&amp;b&gt;"},
+	}
+	for _, c := range cases {
+		ctx := newMemoryContext(c.memory...)
+		p := processor.NewTemplateProcessor(c.tmpl, "out")
+		if err := p.Process(ctx); err != nil {
+			t.Errorf("%s: process error: %s", c.name, err)
+			continue
+		}
+		if got := ctx.GetMemory("out"); got != c.expect {
+			t.Errorf("%s: expect %q, got %q", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestTemplateProcessorMissingKey(t *testing.T) {
+	user := map[string]interface{}{"name": "Clay"}
+
+	if err := processor.NewTemplateProcessor("hello {{.name}}", "out").Process(newMemoryContext()); err == nil {
+		t.Errorf("missing memory should error")
+	}
+	if err := processor.NewTemplateProcessor("hello {{.user.age}}", "out").Process(newMemoryContext("user", user)); err == nil {
+		t.Errorf("missing nested key should error")
+	}
+
+	ctx := newMemoryContext("user", user)
+	p := processor.NewTemplateProcessor("{{.user.name}}{{if .title}} {{.title}}{{end}}", "out", processor.MissingKeyZero)
+	if err := p.Process(ctx); err != nil {
+		t.Fatalf("process with MissingKeyZero error: %s", err)
+	}
+	if got := ctx.GetMemory("out"); got != "Clay" {
+		t.Errorf("expect %q, got %q", "Clay", got)
+	}
+
+	// missing values render as empty, not "<no value>"
+	ctx = newMemoryContext("user", user, "zero", 0)
+	p = processor.NewTemplateProcessor("[{{.missing}}][{{.user.age}}][{{.zero}}][{{$n := .user.name}}{{$n}}]", "out", processor.MissingKeyZero)
+	if err := p.Process(ctx); err != nil {
+		t.Fatalf("process with MissingKeyZero error: %s", err)
+	}
+	if got := ctx.GetMemory("out"); got != "[][][0][Clay]" {
+		t.Errorf("expect %q, got %q", "[][][0][Clay]", got)
+	}
+
+	if err := processor.NewTemplateProcessor("{{.name", "out").Process(newMemoryContext()); err == nil {
+		t.Errorf("invalid template should error")
+	}
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func TestThresholdTriggerEvaluator(t *testing.T) {
+	arrived := map[string]processor.SignalInfo{
+		"a": {LinkID: "a", Seq: 1, Payload: 3},
+		"b": {LinkID: "b", Seq: 1, Payload: -1},
+		"c": {LinkID: "c", Seq: 1, Payload: 5},
+	}
+	positive := func(linkID string, value interface{}) bool { return value.(int) > 0 }
+	cases := []struct {
+		name      string
+		threshold int
+		countIf   processor.CountIf
+		count     int
+		satisfied bool
+	}{
+		{"every signal counts", 3, nil, 3, true},
+		{"qualifying signals reach the threshold", 2, positive, 2, true},
+		{"non-qualifying signals do not count", 3, positive, 2, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := processor.NewThresholdTriggerEvaluator(c.threshold, c.countIf)
+			if count := e.Count(arrived); count != c.count {
+				t.Errorf("expect count %d, got %d", c.count, count)
+			}
+			if satisfied := e.Clone().IsSatisfied(arrived); satisfied != c.satisfied {
+				t.Errorf("expect satisfied %v, got %v", c.satisfied, satisfied)
+			}
+		})
+	}
+}
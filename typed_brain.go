@@ -0,0 +1,136 @@
+package rModel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Rovanta/rmodel/core"
+)
+
+// MemoryTag is the struct tag mapping a field of the input or output of a TypedBrain to a memory key, e.g. `rmodel:"query"`.
+// A field tagged `rmodel:"-"` is ignored.
+const MemoryTag = "rmodel"
+
+// NewTypedBrain new typed facade of the brain, In and Out should be structs, every exported field mapped to a memory key by MemoryTag.
+// Returns error if a type is not a struct, or an exported field is not mapped.
+func NewTypedBrain[In, Out any](brain core.Brain) (*TypedBrain[In, Out], error) {
+	inFields, err := memoryFieldsOf(reflect.TypeOf((*In)(nil)).Elem())
+	if err != nil {
+		return nil, fmt.Errorf("input type: %w", err)
+	}
+	outFields, err := memoryFieldsOf(reflect.TypeOf((*Out)(nil)).Elem())
+	if err != nil {
+		return nil, fmt.Errorf("output type: %w", err)
+	}
+
+	return &TypedBrain[In, Out]{
+		brain:     brain,
+		inFields:  inFields,
+		outFields: outFields,
+	}, nil
+}
+
+// TypedBrain runs a brain with a typed input and output, instead of memories of keys.
+// Runs are serialized, use RunBatch of the brain for parallel runs.
+type TypedBrain[In, Out any] struct {
+	brain     core.Brain
+	inFields  []memoryField
+	outFields []memoryField
+	mu        sync.Mutex
+}
+
+// memoryField is a struct field mapped to a memory key
+type memoryField struct {
+	index []int
+	name  string
+	key   string
+}
+
+// Run resets the brain, sets the fields of in to memory, runs the brain from the entry links,
+// and reads the memories into the fields of the output when the brain sleeps.
+// Returns the run error, the error of ctx if ctx is done before the run ends,
+// or an error naming the key if an output memory is missing or of a mismatched type.
+func (t *TypedBrain[In, Out]) Run(ctx context.Context, in In) (Out, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out Out
+	if err := t.brain.Reset(); err != nil {
+		return out, err
+	}
+	inValue := reflect.ValueOf(in)
+	keysAndValues := make([]any, 0, 2*len(t.inFields))
+	for _, f := range t.inFields {
+		keysAndValues = append(keysAndValues, f.key, inValue.FieldByIndex(f.index).Interface())
+	}
+	if err := t.brain.EntryWithMemory(keysAndValues...); err != nil {
+		return out, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t.brain.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return out, ctx.Err()
+	}
+	if err := t.brain.GetRunError(); err != nil {
+		return out, err
+	}
+
+	outValue := reflect.ValueOf(&out).Elem()
+	for _, f := range t.outFields {
+		if !t.brain.ExistMemory(f.key) {
+			return out, fmt.Errorf("memory %s of output field %s not found", f.key, f.name)
+		}
+		field := outValue.FieldByIndex(f.index)
+		v := t.brain.GetMemory(f.key)
+		if v == nil {
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.Type().AssignableTo(field.Type()) {
+			return out, fmt.Errorf("memory %s of type %s can not be assigned to output field %s of type %s",
+				f.key, rv.Type(), f.name, field.Type())
+		}
+		field.Set(rv)
+	}
+
+	return out, nil
+}
+
+func memoryFieldsOf(t reflect.Type) ([]memoryField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+	fields := make([]memoryField, 0, t.NumField())
+	keys := make(map[string]string)
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous {
+			continue
+		}
+		key, ok := sf.Tag.Lookup(MemoryTag)
+		if key == "-" {
+			continue
+		}
+		if !ok || key == "" {
+			return nil, fmt.Errorf("field %s of %s is not mapped to a memory key by tag %s", sf.Name, t, MemoryTag)
+		}
+		if other, ok := keys[key]; ok {
+			return nil, fmt.Errorf("fields %s and %s of %s are mapped to the same memory key %s", other, sf.Name, t, key)
+		}
+		keys[key] = sf.Name
+		fields = append(fields, memoryField{
+			index: sf.Index,
+			name:  sf.Name,
+			key:   key,
+		})
+	}
+
+	return fields, nil
+}
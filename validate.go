@@ -0,0 +1,361 @@
+package rModel
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Rovanta/rmodel/core"
+	"github.com/Rovanta/rmodel/internal/errors"
+	"github.com/Rovanta/rmodel/internal/topology"
+	"github.com/Rovanta/rmodel/processor"
+)
+
+func (b *brainprint) Validate() error {
+	return b.ValidateReport().Err()
+}
+
+func (b *brainprint) ValidateReport() core.ValidationReport {
+	report := core.ValidationReport{}
+	if err := b.validateNotEmpty(); err != nil {
+		report.Errors = append(report.Errors, err)
+		return report
+	}
+	report.Errors = append(report.Errors, b.validateEndReachable()...)
+	report.Errors = append(report.Errors, b.validateTriggerGroups()...)
+	report.Errors = append(report.Errors, b.validateSelfLinks()...)
+	report.Errors = append(report.Errors, b.validateProcessors()...)
+	report.Errors = append(report.Errors, b.validateNeuronConfigs()...)
+
+	report.Warnings = append(report.Warnings, b.warnUnreachableNeurons()...)
+	report.Warnings = append(report.Warnings, b.warnUnselectedCastGroups()...)
+	report.Warnings = append(report.Warnings, b.warnEmptyCastGroups()...)
+	sort.SliceStable(report.Warnings, func(i, j int) bool {
+		return report.Warnings[i].NeuronID < report.Warnings[j].NeuronID
+	})
+
+	return report
+}
+
+// validateNotEmpty the blueprint should have a neuron besides End neurons
+func (b *brainprint) validateNotEmpty() error {
+	if len(b.neurons) == len(b.listEndNeuronIDs()) {
+		return core.ErrNoNeurons
+	}
+
+	return nil
+}
+
+// validateEndReachable if there are End neurons, at least one of them should be reachable from each entry neuron.
+// Blueprint without entry links is only triggered by TrigLinks, reachability is not checked.
+func (b *brainprint) validateEndReachable() []error {
+	ends := b.listEndNeuronIDs()
+	if len(ends) == 0 || !b.HasEntryLink() {
+		return nil
+	}
+
+	var errs []error
+	links := b.topologyLinks()
+	for _, entry := range b.listEntryNeuronIDs() {
+		reachable := topology.ReachableFrom(links, entry)
+		found := core.IsEndNeuronID(entry)
+		for _, id := range ends {
+			found = found || reachable[id]
+		}
+		if !found {
+			errs = append(errs, errors.Wrapf(core.ErrNoReachableEnd, "entry neuron %s, end neurons: %v", entry, ends))
+		}
+	}
+
+	return errs
+}
+
+// listEntryNeuronIDs returns the sorted IDs of the neurons linked by the entry links
+func (b *brainprint) listEntryNeuronIDs() []string {
+	found := make(map[string]bool)
+	for _, l := range b.links {
+		if l.IsEntryLink() {
+			found[l.dest] = true
+		}
+	}
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// validateTriggerGroups every link in a trigger group should be possible to cast by its source neuron,
+// otherwise the trigger group can never be satisfied.
+// Only selectors implementing processor.PossibleGroupsSelector, and the neurons casting their only cast group
+// without calling the selector, are checked, other selectors may select any group.
+func (b *brainprint) validateTriggerGroups() []error {
+	var errs []error
+	castable := b.castableLinks()
+	for _, n := range b.sortedNeurons() {
+		keys := make([]string, 0, len(n.triggerGroups))
+		for key := range n.triggerGroups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, linkID := range n.triggerGroups[key] {
+				if !castable[linkID] {
+					errs = append(errs, errors.Wrapf(core.ErrUnsatisfiableTriggerGroup,
+						"trigger group %s of neuron %s waits for link %s which can never be cast", key, n.id, linkID))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// warnEmptyCastGroups every named cast group should have links, unless it is allowed to be empty by AllowEmptyCastGroup.
+// The default cast group is not checked, it is empty when all out-links are in named groups.
+func (b *brainprint) warnEmptyCastGroups() []core.Warning {
+	var warnings []core.Warning
+	for _, n := range b.sortedNeurons() {
+		names := make([]string, 0, len(n.castGroups))
+		for name := range n.castGroups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == processor.DefaultCastGroupName || len(n.castGroups[name]) > 0 {
+				continue
+			}
+			if _, allowed := n.emptyCastGroups[name]; !allowed {
+				warnings = append(warnings, core.Warning{
+					Kind:     core.WarningEmptyCastGroup,
+					NeuronID: n.id,
+					Message:  fmt.Sprintf("cast group %s of neuron %s has no link", name, n.id),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// validateSelfLinks a link from a neuron to itself is only allowed with max revisits, see core.WithMaxRevisits
+func (b *brainprint) validateSelfLinks() []error {
+	var errs []error
+	ids := make([]string, 0, len(b.links))
+	for id := range b.links {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		l := b.links[id]
+		if l.src != l.dest {
+			continue
+		}
+		if n, ok := b.neurons[l.src]; ok && n.maxRevisits <= 0 {
+			errs = append(errs, errors.Wrapf(errors.ErrSelfLink(n.id), "link %s", id))
+		}
+	}
+
+	return errs
+}
+
+// validateProcessors every neuron should have a processor, a nil one fails its runs with core.ErrNilProcessor
+func (b *brainprint) validateProcessors() []error {
+	var errs []error
+	for _, n := range b.sortedNeurons() {
+		if n.processor == nil {
+			errs = append(errs, errors.ErrNilProcessor(n.id))
+		}
+	}
+
+	return errs
+}
+
+// validateNeuronConfigs the settings of every neuron should not contradict each other:
+// a run once neuron is never activated again in a run, so it should not have max revisits, nor be in a loop.
+func (b *brainprint) validateNeuronConfigs() []error {
+	var errs []error
+	links := b.topologyLinks()
+	for _, n := range b.sortedNeurons() {
+		if !n.runOnce {
+			continue
+		}
+		if n.maxRevisits > 0 {
+			errs = append(errs, errors.ErrConflictingNeuronConfig(n.id, "run once",
+				fmt.Sprintf("max revisits %d", n.maxRevisits)))
+		}
+		if topology.ReachableFrom(links, n.id)[n.id] {
+			errs = append(errs, errors.ErrConflictingNeuronConfig(n.id, "run once", "a loop back to the neuron"))
+		}
+		if n.refireGuard != nil {
+			errs = append(errs, errors.ErrConflictingNeuronConfig(n.id, "run once", "a refire guard"))
+		}
+	}
+
+	return errs
+}
+
+// warnUnreachableNeurons every neuron should be reachable from an entry neuron, ignoring cast group selection.
+// Blueprint without entry links is only triggered by TrigLinks, reachability is not checked.
+func (b *brainprint) warnUnreachableNeurons() []core.Warning {
+	if !b.HasEntryLink() {
+		return nil
+	}
+
+	var warnings []core.Warning
+	reachable := topology.Reachable(b.topologyLinks())
+	for _, n := range b.sortedNeurons() {
+		if reachable[n.id] || core.IsEndNeuronID(n.id) {
+			continue
+		}
+		warnings = append(warnings, core.Warning{
+			Kind:     core.WarningUnreachableNeuron,
+			NeuronID: n.id,
+			Message:  fmt.Sprintf("neuron %s is not reachable from any entry neuron", n.id),
+		})
+	}
+
+	return warnings
+}
+
+// warnUnselectedCastGroups every named cast group should be possible to select,
+// only selectors implementing processor.PossibleGroupsSelector, and the neurons casting their only cast group
+// without calling the selector, are checked.
+func (b *brainprint) warnUnselectedCastGroups() []core.Warning {
+	var warnings []core.Warning
+	for _, n := range b.sortedNeurons() {
+		groups, ok := n.possibleGroups()
+		if !ok {
+			continue
+		}
+		possible := make(map[string]bool)
+		for _, name := range groups {
+			if _, exist := n.castGroups[name]; !exist {
+				if group, isAlias := n.groupAliases[name]; isAlias {
+					name = group
+				}
+			}
+			possible[name] = true
+		}
+		names := make([]string, 0, len(n.castGroups))
+		for name := range n.castGroups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == processor.DefaultCastGroupName || possible[name] {
+				continue
+			}
+			warnings = append(warnings, core.Warning{
+				Kind:     core.WarningUnselectedCastGroup,
+				NeuronID: n.id,
+				Message:  fmt.Sprintf("cast group %s of neuron %s is never selected by its selector", name, n.id),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// possibleGroups returns the cast groups the neuron may cast, see topology.PossibleGroups
+func (n *neuron) possibleGroups() ([]string, bool) {
+	sizes := make(map[string]int, len(n.castGroups))
+	for name, links := range n.castGroups {
+		sizes[name] = len(links)
+	}
+
+	return topology.PossibleGroups(n.selector, n.selectorBound, sizes)
+}
+
+// castableLinks returns the links which may be cast: entry links, and out-links in the possible cast groups of the source neuron.
+func (b *brainprint) castableLinks() map[string]bool {
+	castable := make(map[string]bool)
+	for id, l := range b.links {
+		if l.IsEntryLink() {
+			castable[id] = true
+		}
+	}
+	for _, n := range b.neurons {
+		groups, ok := n.possibleGroups()
+		if !ok {
+			for _, group := range n.castGroups {
+				for linkID := range group {
+					castable[linkID] = true
+				}
+			}
+			continue
+		}
+		for _, name := range groups {
+			if _, exist := n.castGroups[name]; !exist {
+				if group, isAlias := n.groupAliases[name]; isAlias {
+					name = group
+				}
+			}
+			for linkID := range n.castGroups[name] {
+				castable[linkID] = true
+			}
+		}
+	}
+
+	return castable
+}
+
+func (b *brainprint) TopologicalOrder() ([]core.Neuron, error) {
+	ids := make([]string, 0, len(b.neurons))
+	for id := range b.neurons {
+		ids = append(ids, id)
+	}
+	order, err := topology.Order(ids, b.topologyLinks(), func(loop []string) bool {
+		for _, id := range loop {
+			if b.neurons[id].maxRevisits > 0 {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	neurons := make([]core.Neuron, 0, len(order))
+	for _, id := range order {
+		neurons = append(neurons, b.neurons[id])
+	}
+
+	return neurons, nil
+}
+
+func (b *brainprint) sortedNeurons() []*neuron {
+	ids := make([]string, 0, len(b.neurons))
+	for id := range b.neurons {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	neurons := make([]*neuron, 0, len(ids))
+	for _, id := range ids {
+		neurons = append(neurons, b.neurons[id])
+	}
+
+	return neurons
+}
+
+func (b *brainprint) topologyLinks() map[string]topology.Link {
+	links := make(map[string]topology.Link, len(b.links))
+	for id, l := range b.links {
+		links[id] = topology.Link{From: l.src, To: l.dest}
+	}
+
+	return links
+}
+
+func (b *brainprint) listEndNeuronIDs() []string {
+	ids := make([]string, 0)
+	for id := range b.neurons {
+		if core.IsEndNeuronID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}